@@ -16,6 +16,7 @@ import (
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
 )
 
 //go:embed scripts/*
@@ -35,13 +36,42 @@ func NewApp() *App {
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	// Started by the logon task after a queue restarted the PC; the task runs elevated,
+	// so an ordinary launch never picks up the queue
+	if len(os.Args) > 1 && os.Args[1] == "--resume" && isAdmin() {
+		if exe, err := os.Executable(); err == nil {
+			os.Chdir(filepath.Dir(exe))
+		}
+		go a.resumeAfterReboot()
+	}
 }
 
 // --- Data Structures ---
 
 type Config struct {
-	NasBasePath  string     `json:"nas_base_path"`
-	SoftwareList []Software `json:"software_list"`
+	NasBasePath        string                  `json:"nas_base_path"`
+	NasRoots           []string                `json:"nas_roots"`
+	NasProbeTimeoutSec int                     `json:"nas_probe_timeout_seconds"`
+	NasCacheTTLSec     int                     `json:"nas_cache_ttl_seconds"`
+	MirrorPath         string                  `json:"mirror_path"`
+	PeerServeDir       string                  `json:"peer_serve_dir"`
+	PeerListen         string                  `json:"peer_listen"`
+	PeerSources        []string                `json:"peer_sources"`
+	SecurityBaseline   *SecurityBaseline       `json:"security_baseline"`
+	BrowserPolicy      *BrowserPolicy          `json:"browser_policy"`
+	DeviceControl      *DeviceControlPolicy    `json:"device_control"`
+	VNC                *VNCConfig              `json:"vnc"`
+	FirewallRules      []FirewallRule          `json:"firewall_rules"`
+	IPPlanPath         string                  `json:"ip_plan_path"`
+	Naming             *NamingPolicy           `json:"naming"`
+	DomainJoin         *DomainJoinConfig       `json:"domain_join"`
+	Regional           *RegionalSettings       `json:"regional"`
+	Power              *PowerPolicy            `json:"power"`
+	Personalization    *PersonalizationProfile `json:"personalization"`
+	Profiles           []SetupProfile          `json:"profiles"`
+	RebootCountdownSec int                     `json:"reboot_countdown_seconds"`
+	EnforceIntervalMin int                     `json:"enforce_interval_minutes"`
+	SoftwareList       []Software              `json:"software_list"`
 }
 
 type Software struct {
@@ -188,65 +218,81 @@ func (a *App) GetSystemStatus() string {
 	if err != nil {
 		return "⚠️ Config Error: " + err.Error()
 	}
+	ensureNasSession(config)
+
+	timeout, ttl := config.nasProbeOptions()
+	roots := probeNasRoots(config.nasRoots(), timeout, ttl)
+
+	reachable := 0
+	for _, r := range roots {
+		if r.Reachable {
+			reachable++
+		}
+	}
 
-	if checkNasAvailability(config.NasBasePath) {
-		return fmt.Sprintf("✅ NAS Connected (%s)", config.NasBasePath)
+	for _, r := range roots {
+		if r.Reachable {
+			return fmt.Sprintf("✅ NAS Connected (%s, %d ms, %d/%d roots reachable)", r.Path, r.LatencyMs, reachable, len(roots))
+		}
 	}
-	return "🌍 NAS Offline (Internet Mode)"
+	return fmt.Sprintf("🌍 NAS Offline (Internet Mode, 0/%d roots reachable)", len(roots))
 }
 
-// ConnectNAS attempts to map the NAS drive with credentials
-func (a *App) ConnectNAS(user, pass string) string {
+// ConnectNAS connects to the NAS share and saves the credentials in Windows Credential Manager
+func (a *App) ConnectNAS(user, pass string) (result string) {
+	defer beginAudit("ConnectNAS", map[string]string{"user": user, "password": pass}, nil).finish(&result)
+
 	config, err := loadConfig("config.json")
 	if err != nil {
 		return "Error: " + err.Error()
 	}
 
-	// PowerShell: net use <path> <pass> /user:<user> /persistent:no
-	ps := fmt.Sprintf(`net use "%s" "%s" /user:"%s" /persistent:no`, config.NasBasePath, pass, user)
-	cmd := exec.Command("powershell", "-Command", ps)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
+	share := nasShareRoot(config.NasBasePath)
+	if share == "" {
+		return "Error: Invalid NAS path in config: " + config.NasBasePath
+	}
 
+	err = connectNasShare(share, user, pass)
+	nasHealth.invalidate()
 	if err != nil {
-		return "Connection Failed: " + string(output)
+		return "Connection Failed: " + err.Error()
+	}
+
+	// Remember the credentials so later sessions reconnect automatically
+	if err := nasCredentials.Write(nasCredentialTarget(nasServer(config.NasBasePath)), user, pass); err != nil {
+		return "✅ Success: NAS Connected (credentials not saved: " + err.Error() + ")."
 	}
 	return "✅ Success: NAS Connected."
 }
 
-// DisconnectNAS removes the NAS mapping and credentials
-func (a *App) DisconnectNAS() string {
+// DisconnectNAS removes every session and saved credential for our NAS server, leaving other mapped drives alone
+func (a *App) DisconnectNAS() (result string) {
+	defer beginAudit("DisconnectNAS", nil, nil).finish(&result)
+
 	config, err := loadConfig("config.json")
 	if err != nil {
 		return "Error: " + err.Error()
 	}
 
-	// Extract server IP/Name from UNC path (e.g. \\174.156.4.3\...)
-	parts := strings.Split(config.NasBasePath, "\\")
-	var server string
-	if len(parts) >= 3 {
-		server = parts[2] // This gets '174.156.4.3' without backslashes for cmdkey
+	server := nasServer(config.NasBasePath)
+	if server == "" {
+		return "Error: Invalid NAS path in config: " + config.NasBasePath
 	}
 
-	// PowerShell script to aggressively clear sessions
-	// 1. cmdkey /delete:<server> (Removes saved Windows Credentials)
-	// 2. net use * /delete /y (Forces close of all network connections)
-	psCleanup := fmt.Sprintf(`
-		cmdkey /delete:%s 2>$null
-		net use * /delete /y 2>$null
-	`, server)
+	sessions, creds, failures := disconnectNasServer(server)
+	nasHealth.invalidate()
 
-	cmd := exec.Command("powershell", "-Command", psCleanup)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	// We capture output but don't fail immediately on it, as some commands might error if nothing to delete
-	output, _ := cmd.CombinedOutput()
+	report := fmt.Sprintf("Sessions removed: %s. Credentials removed: %s.", listOrNone(sessions), listOrNone(creds))
+	if len(failures) > 0 {
+		report += " Failed: " + strings.Join(failures, "; ") + "."
+	}
 
 	// Double check availability
 	if !checkNasAvailability(config.NasBasePath) {
-		return "✅ Success: Credentials wiped and disconnected."
+		return "✅ Success: Disconnected from " + server + ". " + report
 	}
 
-	return "Warning: Session persists. Output: " + string(output)
+	return "Warning: Session persists. " + report
 }
 
 // GetSoftwareList reads the config.json and returns the list
@@ -309,51 +355,15 @@ func (a *App) GetHardwareInfo() HardwareInfo {
 	return info
 }
 
-// ApplyTightVNCConfig applies security settings to TightVNC natively
-func (a *App) ApplyTightVNCConfig() string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required to configure VNC."
-	}
-
-	ipAccess := "192.168.1.1-192.168.1.254:0,174.156.5.1-174.156.5.254:0"
-
-	// Configure Registry to NOT ask for password (No Authentication)
-	commands := [][]string{
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "UseVncAuthentication", "/t", "REG_DWORD", "/d", "0", "/f"},
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "UseControlAuthentication", "/t", "REG_DWORD", "/d", "0", "/f"},
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "AccessControlConfig", "/t", "REG_STRING", "/d", ipAccess, "/f"},
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "RfbPort", "/t", "REG_DWORD", "/d", "5900", "/f"},
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "AcceptPointerEvents", "/t", "REG_DWORD", "/d", "1", "/f"},
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "AcceptKeyboardEvents", "/t", "REG_DWORD", "/d", "1", "/f"},
-		{"reg", "add", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "AllowLoopback", "/t", "REG_DWORD", "/d", "1", "/f"},
-		// Delete existing password keys to ensure no conflicts
-		{"reg", "delete", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "Password", "/f"},
-		{"reg", "delete", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "ControlPassword", "/f"},
-		{"reg", "delete", "HKLM\\SOFTWARE\\TightVNC\\Server", "/v", "PasswordViewOnly", "/f"},
-	}
-
-	for _, cmdArgs := range commands {
-		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
-		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-		cmd.Run()
-	}
-
-	// Restart service more robustly
-	exec.Command("net", "stop", "tvnserver").Run()
-	// Wait a bit for it to stop
-	exec.Command("powershell", "-Command", "Start-Sleep -Seconds 2").Run()
-	err := exec.Command("net", "start", "tvnserver").Run()
-
-	if err != nil {
-		return "✅ Config Applied (VNC Service restart skipped)."
-	}
-
-	return "✅ Success: TightVNC Configured (Authentication Disabled)."
+// InstallSQLyog handles SQLyog specifically (Q2C requirement)
+func (a *App) InstallSQLyog() (result string) {
+	defer beginAudit("InstallSoftware", map[string]string{"name": "SQLyog"}, installAuditProbe("SQLyog")).finish(&result)
+	return a.installSQLyog(liveExecutor())
 }
 
-// InstallSQLyog handles SQLyog specifically (Q2C requirement)
-func (a *App) InstallSQLyog() string {
+func (a *App) installSQLyog(x *executor) string {
 	config, _ := loadConfig("config.json")
+	x.nasSession(config)
 	target := "SQLyog-v13.1.1.x64.exe"
 
 	// Quick Search Priority
@@ -368,16 +378,18 @@ func (a *App) InstallSQLyog() string {
 	// 1. Try NAS
 	for _, p := range nasPaths {
 		if fileExists(p) {
-			copyFile(a, p, tempPath)
-			runInstaller(tempPath, []string{"/S"}, false)
+			x.source("NAS " + p)
+			x.copyFile(a, p, tempPath)
+			x.install(tempPath, []string{"/S"}, false)
 			return "✅ Success: SQLyog Installed from NAS."
 		}
 	}
 
 	// 2. Try Download
 	url := "https://github.com/webyog/sqlyog-community/releases/download/v13.2.1/SQLyog-13.2.1-0.x64Community.exe"
-	if err := downloadFile(a, url, tempPath); err == nil {
-		runInstaller(tempPath, []string{"/S"}, false)
+	x.source("Internet " + url)
+	if err := x.download(a, url, tempPath); err == nil {
+		x.install(tempPath, []string{"/S"}, false)
 		return "✅ Success: SQLyog Installed from Web Download."
 	}
 
@@ -385,8 +397,14 @@ func (a *App) InstallSQLyog() string {
 }
 
 // InstallDocker handles Docker Desktop specifically (Q2C requirement)
-func (a *App) InstallDocker() string {
+func (a *App) InstallDocker() (result string) {
+	defer beginAudit("InstallSoftware", map[string]string{"name": "Docker Desktop"}, installAuditProbe("Docker Desktop")).finish(&result)
+	return a.installDocker(liveExecutor())
+}
+
+func (a *App) installDocker(x *executor) string {
 	config, _ := loadConfig("config.json")
+	x.nasSession(config)
 	target := "Docker Desktop Installer.exe"
 
 	// Quick Search Priority
@@ -401,19 +419,19 @@ func (a *App) InstallDocker() string {
 	// 1. Try NAS
 	for _, p := range nasPaths {
 		if fileExists(p) {
-			copyFile(a, p, tempPath)
+			x.source("NAS " + p)
+			x.copyFile(a, p, tempPath)
 			// Run directly (interactive), bypass PowerShell wrapper
-			cmd := exec.Command(tempPath, "install")
-			cmd.Start()
+			x.start(exec.Command(tempPath, "install"))
 			return "✅ Success: Docker Installer Started from NAS (Direct)."
 		}
 	}
 
 	// 2. Try Download
 	url := "https://desktop.docker.com/win/main/amd64/Docker%20Desktop%20Installer.exe"
-	if err := downloadFile(a, url, tempPath); err == nil {
-		cmd := exec.Command(tempPath, "install")
-		cmd.Start()
+	x.source("Internet " + url)
+	if err := x.download(a, url, tempPath); err == nil {
+		x.start(exec.Command(tempPath, "install"))
 		return "✅ Success: Docker Installer Started from Web (Direct)."
 	}
 
@@ -421,7 +439,7 @@ func (a *App) InstallDocker() string {
 }
 
 // InstallSoftware handles the logic for a specific software
-func (a *App) InstallSoftware(name string) string {
+func (a *App) InstallSoftware(name string) (result string) {
 	if name == "TightVNC Config" {
 		return a.ApplyTightVNCConfig()
 	}
@@ -431,7 +449,11 @@ func (a *App) InstallSoftware(name string) string {
 	if name == "Docker Desktop" {
 		return a.InstallDocker()
 	}
+	defer beginAudit("InstallSoftware", map[string]string{"name": name}, installAuditProbe(name)).finish(&result)
+	return a.installSoftware(liveExecutor(), name)
+}
 
+func (a *App) installSoftware(x *executor, name string) string {
 	config, err := loadConfig("config.json")
 	if err != nil {
 		return "Error loading config"
@@ -451,7 +473,7 @@ func (a *App) InstallSoftware(name string) string {
 		return "Software not found in config"
 	}
 
-	os.MkdirAll(TempDir, 0755)
+	x.mkdirAll(TempDir)
 
 	// Detect file extension from NAS path
 	fileExt := filepath.Ext(targetSw.NasPath)
@@ -465,35 +487,28 @@ func (a *App) InstallSoftware(name string) string {
 
 	if targetSw.IsEmbedded {
 		// Extract from binary to temp folder
-		installerPath = extractEmbeddedScript(targetSw.NasPath)
+		installerPath = x.extract(targetSw.NasPath)
+		if installerPath != "" {
+			x.source("Embedded " + targetSw.NasPath)
+		}
 	}
 
 	if installerPath == "" {
-		// Try multiple NAS search roots for better reliability
-		nasRoots := []string{
-			config.NasBasePath,
-			"\\\\174.156.4.3\\fjt\\Automations-Priyanshu",
-			"\\\\174.156.4.3\\fjt\\Automations-Priyanshu\\Basic sw",
-			"\\\\174.156.4.3\\fjt\\Automations-Priyanshu\\Q2C",
-			"\\\\174.156.4.3\\fjt\\Automations-Priyanshu\\rabbitmq,elastic",
-			"\\\\174.156.4.3\\fjt\\Required softwares\\Automation Software\\Automations-Priyanshu",
-			"\\\\174.156.4.3\\fjt\\Required softwares\\Automation Software\\Automations-Priyanshu\\Basic sw",
-			"\\\\174.156.4.3\\fjt\\Required softwares\\Automation Software\\Automations-Priyanshu\\Q2C",
-			"\\\\174.156.4.3\\fjt\\Required softwares\\Update - Dev System",
-		}
+		x.nasSession(config)
 
-		for _, root := range nasRoots {
-			if root == "" {
+		// Probe all NAS search roots concurrently, then try the reachable ones in order
+		timeout, ttl := config.nasProbeOptions()
+		for _, root := range probeNasRoots(config.nasRoots(), timeout, ttl) {
+			if !root.Reachable {
 				continue
 			}
-			if checkNasAvailability(root) {
-				fullNasPath := filepath.Join(root, targetSw.NasPath)
-				if fileExists(fullNasPath) {
-					err := copyFile(a, fullNasPath, destPath)
-					if err == nil {
-						installerPath = destPath
-						break
-					}
+			fullNasPath := filepath.Join(root.Path, targetSw.NasPath)
+			if fileExists(fullNasPath) {
+				x.source("NAS " + fullNasPath)
+				err := x.copyFile(a, fullNasPath, destPath)
+				if err == nil {
+					installerPath = destPath
+					break
 				}
 			}
 		}
@@ -502,13 +517,19 @@ func (a *App) InstallSoftware(name string) string {
 	if installerPath == "" {
 		// Local Fallback: Check if file exists in the current directory or a relative path
 		if fileExists(targetSw.NasPath) {
+			x.source("Local " + targetSw.NasPath)
 			installerPath = targetSw.NasPath
+		} else if peer, err := x.fetchFromPeers(a, config, targetSw, destPath); err == nil {
+			// Peer PC serving its mirror on the LAN
+			x.source("Peer " + peer)
+			installerPath = destPath
 		} else {
 			// Fallback to Internet
 			if targetSw.DownloadUrl == "" {
 				return "❌ Error: Not found on NAS and no Download URL provided for " + targetSw.Name
 			}
-			err := downloadFile(a, targetSw.DownloadUrl, destPath)
+			x.source("Internet " + targetSw.DownloadUrl)
+			err := x.download(a, targetSw.DownloadUrl, destPath)
 			if err != nil {
 				return "Download Failed: " + err.Error()
 			}
@@ -517,7 +538,7 @@ func (a *App) InstallSoftware(name string) string {
 	}
 
 	// Install
-	err = runInstaller(installerPath, targetSw.InstallArgs, targetSw.Interactive)
+	err = x.install(installerPath, targetSw.InstallArgs, targetSw.Interactive)
 	if err != nil {
 		return "Installation Error: " + err.Error()
 	}
@@ -546,7 +567,12 @@ func (a *App) BulkUninstall(names []string) []string {
 }
 
 // UninstallSoftware handles the removal logic for a specific software
-func (a *App) UninstallSoftware(name string) string {
+func (a *App) UninstallSoftware(name string) (result string) {
+	defer beginAudit("UninstallSoftware", map[string]string{"name": name}, installAuditProbe(name)).finish(&result)
+	return a.uninstallSoftware(liveExecutor(), name)
+}
+
+func (a *App) uninstallSoftware(x *executor, name string) string {
 	config, err := loadConfig("config.json")
 	if err != nil {
 		return "Error loading config"
@@ -577,6 +603,7 @@ func (a *App) UninstallSoftware(name string) string {
 		var installerPath string
 
 		// Check if NAS is available and file exists
+		x.nasSession(config)
 		useNas := checkNasAvailability(config.NasBasePath)
 		if useNas {
 			fullNasPath := filepath.Join(config.NasBasePath, targetSw.NasPath)
@@ -602,7 +629,7 @@ func (a *App) UninstallSoftware(name string) string {
 
 			cmd := exec.Command("msiexec", msiArgs...)
 			cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: !targetSw.Interactive}
-			output, err := cmd.CombinedOutput()
+			output, err := x.output(cmd)
 
 			if err != nil {
 				return "Uninstallation Error: " + string(output) + " " + err.Error()
@@ -619,7 +646,7 @@ func (a *App) UninstallSoftware(name string) string {
 	if len(targetSw.UninstallArgs) > 0 {
 		if targetSw.IsEmbedded {
 			// Embedded scripts are always handled via PowerShell
-			extractedPath := extractEmbeddedScript(targetSw.NasPath)
+			extractedPath := x.extract(targetSw.NasPath)
 			if extractedPath == "" {
 				return "❌ Error: Failed to extract embedded script for uninstallation"
 			}
@@ -628,8 +655,7 @@ func (a *App) UninstallSoftware(name string) string {
 			psArgs := []string{"-NoExit", "-ExecutionPolicy", "Bypass", "-File", extractedPath}
 			psArgs = append(psArgs, targetSw.UninstallArgs...)
 			psCmd := fmt.Sprintf("Start-Process powershell.exe -ArgumentList '%s' -Wait", strings.Join(psArgs, "','"))
-			cmd := exec.Command("powershell", "-NoProfile", "-Command", psCmd)
-			err := cmd.Run()
+			err := x.run(exec.Command("powershell", "-NoProfile", "-Command", psCmd))
 			if err != nil {
 				return "Uninstallation Error: " + err.Error()
 			}
@@ -678,8 +704,7 @@ func (a *App) UninstallSoftware(name string) string {
 						psCmd = fmt.Sprintf("Start-Process '%s' -Wait", exePath)
 					}
 				}
-				cmd := exec.Command("powershell", "-NoProfile", "-NoExit", "-Command", psCmd)
-				err := cmd.Run()
+				err := x.run(exec.Command("powershell", "-NoProfile", "-NoExit", "-Command", psCmd))
 				if err != nil {
 					return "Uninstallation Error: " + err.Error()
 				}
@@ -693,7 +718,7 @@ func (a *App) UninstallSoftware(name string) string {
 
 			// We don't use CombinedOutput here because we want the GUI to show up
 			// and potentially keep running.
-			err := cmd.Start()
+			err := x.start(cmd)
 			if err != nil {
 				return "Uninstallation Launch Error: " + err.Error()
 			}
@@ -768,22 +793,18 @@ func (a *App) TestSoftware(name string) string {
 }
 
 // RenamePC renames the computer and requires a restart
-func (a *App) RenamePC(newName string) string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required to rename PC."
-	}
-	if newName == "" {
-		return "Error: Name cannot be empty."
-	}
-	if len(newName) > 15 {
-		return "Error: PC Name too long (Max 15 characters)."
+func (a *App) RenamePC(newName string) (result string) {
+	defer beginAudit("RenamePC", map[string]string{"new_name": newName}, renameAuditProbe).snapshot(renameChangeScope).finish(&result)
+	return a.renamePC(liveExecutor(), newName)
+}
+
+func (a *App) renamePC(x *executor, newName string) string {
+
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to rename PC."); !ok {
+		return msg
 	}
-	// Check for illegal characters (RFC 1123 / Windows restrictions)
-	invalidChars := []string{" ", ".", ",", "@", "#", "$", "%", "^", "&", "*", "(", ")", "+", "=", "[", "]", "{", "}", "|", "\\", ":", ";", "\"", "'", "<", ">", "?", "/"}
-	for _, char := range invalidChars {
-		if strings.Contains(newName, char) {
-			return "Error: PC Name contains illegal character: " + char
-		}
+	if problems := validateNetBIOSName(newName); len(problems) > 0 {
+		return "Error: " + strings.Join(problems, "; ") + "."
 	}
 
 	hostname, _ := os.Hostname()
@@ -791,148 +812,105 @@ func (a *App) RenamePC(newName string) string {
 		return "ℹ️ PC is already named " + newName
 	}
 
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if msg := renameProblem(config, newName); msg != "" {
+		return msg
+	}
+
 	// Use ErrorAction Stop to ensure errors are caught by Go
 	ps := fmt.Sprintf("Rename-Computer -NewName '%s' -Force -ErrorAction Stop", newName)
 	cmd := exec.Command("powershell", "-Command", ps)
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 
-	output, err := cmd.CombinedOutput()
+	output, err := x.output(cmd)
 	if err != nil {
 		return "PowerShell Error: " + string(output) + " " + err.Error()
 	}
-	return "✅ Success: PC Renamed to " + newName + ". Restart required."
+	return "✅ Success: PC Renamed to " + newName + ". Restart required." + recordRename(x, config, newName, hostname)
 }
 
-// SetStaticIP configures the network adapter
-func (a *App) SetStaticIP(ip, subnet, gateway, dns string) string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required for network changes."
-	}
-	ps := fmt.Sprintf(`
-		$adapter = Get-NetAdapter | Where-Object { $_.Status -eq 'Up' } | Select-Object -First 1
-		if ($adapter) {
-			New-NetIPAddress -InterfaceAlias $adapter.Name -IPAddress '%s' -PrefixLength %s -DefaultGateway '%s' -Force -ErrorAction Stop
-			Set-DnsClientServerAddress -InterfaceAlias $adapter.Name -ServerAddresses ('%s') -ErrorAction Stop
-		} else {
-			throw 'No active network adapter found'
-		}
-	`, ip, subnet, gateway, dns)
-
-	cmd := exec.Command("powershell", "-Command", ps)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "Network Error: " + string(output) + " " + err.Error()
-	}
-	return "✅ Success: Static IP and DNS applied."
+// SetWallpaper downloads an image and sets it as the desktop wallpaper ("" style = keep the current one)
+func (a *App) SetWallpaper(url, style string) (result string) {
+	defer beginAudit("SetWallpaper", map[string]string{"url": url, "style": style}, wallpaperAuditProbe).snapshot(wallpaperChangeScope).finish(&result)
+	return a.setWallpaper(liveExecutor(), url, style)
 }
 
-// SetWallpaper sets the desktop wallpaper via PowerShell
-func (a *App) SetWallpaper(url string) string {
-	dest := filepath.Join(TempDir, "wallpaper.jpg")
-	os.MkdirAll(TempDir, 0755)
-
-	err := downloadFile(a, url, dest)
+func (a *App) setWallpaper(x *executor, url, style string) string {
+	if problems := (PersonalizationProfile{WallpaperStyle: style}).validate(); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	path, err := fetchImage(x, url, "wallpaper")
 	if err != nil {
 		return "Download Error: " + err.Error()
 	}
-
-	ps := fmt.Sprintf(`
-		$code = @'
-		using System.Runtime.InteropServices;
-		public class Wallpaper {
-			[DllImport("user32.dll", CharSet = CharSet.Auto)]
-			public static extern int SystemParametersInfo(int uAction, int uParam, string lpvParam, int fuWinIni);
-		}
-'@
-		Add-Type $code
-		[Wallpaper]::SystemParametersInfo(20, 0, '%s', 3)
-	`, dest)
-
-	cmd := exec.Command("powershell", "-Command", ps)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "Wallpaper Error: " + string(output) + " " + err.Error()
+	if err := applyWallpaper(x, path, style); err != nil {
+		return "Wallpaper Error: " + err.Error()
 	}
 	return "✅ Success: Wallpaper updated."
 }
 
-// SetBrandedWallpaper sets the local tgs.png as wallpaper
-func (a *App) SetBrandedWallpaper() string {
-	// Find the file in the executable directory
-	exe, _ := os.Executable()
-	exeDir := filepath.Dir(exe)
-	localPath := filepath.Join(exeDir, "tgs.png")
-
-	// Fallback to current working directory if exe path is weird
-	if _, err := os.Stat(localPath); os.IsNotExist(err) {
-		localPath = "tgs.png"
-	}
+// SetBrandedWallpaper sets the local tgs.png as wallpaper, in the configured style
+func (a *App) SetBrandedWallpaper() (result string) {
+	defer beginAudit("SetBrandedWallpaper", nil, wallpaperAuditProbe).snapshot(wallpaperChangeScope).finish(&result)
+	return a.setBrandedWallpaper(liveExecutor())
+}
 
-	if _, err := os.Stat(localPath); os.IsNotExist(err) {
+func (a *App) setBrandedWallpaper(x *executor) string {
+	localPath := resolveImagePath("tgs.png")
+	if !fileExists(localPath) {
 		return "Error: Branding file 'tgs.png' not found in application folder."
 	}
-
-	ps := fmt.Sprintf(`
-		$code = @'
-		using System.Runtime.InteropServices;
-		public class Wallpaper {
-			[DllImport("user32.dll", CharSet = CharSet.Auto)]
-			public static extern int SystemParametersInfo(int uAction, int uParam, string lpvParam, int fuWinIni);
-		}
-'@
-		Add-Type $code
-		[Wallpaper]::SystemParametersInfo(20, 0, '%s', 3)
-	`, localPath)
-
-	cmd := exec.Command("powershell", "-Command", ps)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
+	style := ""
+	if config, err := loadConfig("config.json"); err == nil && config.Personalization != nil {
+		style = config.Personalization.WallpaperStyle
+	}
+	if problems := (PersonalizationProfile{WallpaperStyle: style}).validate(); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	path, err := fetchImage(x, localPath, "wallpaper")
 	if err != nil {
-		return "Branding Error: " + string(output) + " " + err.Error()
+		return "Branding Error: " + err.Error()
+	}
+	if err := applyWallpaper(x, path, style); err != nil {
+		return "Branding Error: " + err.Error()
 	}
 	return "✅ Success: TGS Branding Applied!"
 }
 
-// SyncTime sets timezone to India and syncs with NTP
-func (a *App) SyncTime() string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required to sync time."
-	}
-	ps := `
-		Set-TimeZone -Id "India Standard Time" -ErrorAction Stop
-		net start w32time ; w32tm /resync /force
-		Set-ItemProperty -Path "HKCU:\Control Panel\International" -Name "sShortTime" -Value "HH:mm" -Force
-		Set-ItemProperty -Path "HKCU:\Control Panel\International" -Name "sTimeFormat" -Value "HH:mm:ss" -Force
-	`
-	cmd := exec.Command("powershell", "-Command", ps)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "Time Sync Error: " + string(output) + " " + err.Error()
-	}
-	return "✅ Success: Time synced to India (12HR format set)."
+// SyncTime applies the config-wide regional settings (India time and 12-hour clock by default) and syncs with NTP
+func (a *App) SyncTime() (result string) {
+	defer beginAudit("SyncTime", nil, timeAuditProbe).snapshot(timeChangeScope).finish(&result)
+	return a.syncTime(liveExecutor())
+}
+
+func (a *App) syncTime(x *executor) string {
+	return a.applyRegionalSettings(x, "")
 }
 
 // ShowThisPCIcon adds 'This PC' to desktop via registry
-func (a *App) ShowThisPCIcon() string {
-	ps := `
-		$path = "HKCU:\Software\Microsoft\Windows\CurrentVersion\Explorer\HideDesktopIcons\NewStartPanel"
-		if (!(Test-Path $path)) { New-Item -Path $path -Force -ErrorAction Stop }
-		Set-ItemProperty -Path $path -Name "{20D04FE0-3AEA-1069-A2D8-08002B30309D}" -Value 0 -Force -ErrorAction Stop
-	`
-	cmd := exec.Command("powershell", "-Command", ps)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "Registry Error: " + string(output) + " " + err.Error()
+func (a *App) ShowThisPCIcon() (result string) {
+	defer beginAudit("ShowThisPCIcon", nil, thisPCAuditProbe).snapshot(thisPCChangeScope).finish(&result)
+	return a.showThisPCIcon(liveExecutor())
+}
+
+func (a *App) showThisPCIcon(x *executor) string {
+	if err := setDesktopIcons(x, map[string]bool{"this_pc": true}); err != nil {
+		return "Registry Error: " + err.Error()
 	}
 	return "✅ Success: 'This PC' icon enabled."
 }
 
 // SetSleepMode configures AC sleep timeout (0 = Never)
-func (a *App) SetSleepMode(minutes int) string {
+func (a *App) SetSleepMode(minutes int) (result string) {
+	defer beginAudit("SetSleepMode", map[string]string{"minutes": fmt.Sprint(minutes)}, nil).snapshot(sleepChangeScope).finish(&result)
+	return a.setSleepMode(liveExecutor(), minutes)
+}
+
+func (a *App) setSleepMode(x *executor, minutes int) string {
+
 	var cmdStr string
 	if minutes == 0 {
 		cmdStr = "powercfg /change monitor-timeout-ac 0; powercfg /change standby-timeout-ac 0"
@@ -942,7 +920,7 @@ func (a *App) SetSleepMode(minutes int) string {
 
 	cmd := exec.Command("powershell", "-Command", cmdStr)
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
+	output, err := x.output(cmd)
 	if err != nil {
 		return "Power Error: " + string(output) + " " + err.Error()
 	}
@@ -954,9 +932,15 @@ func (a *App) SetSleepMode(minutes int) string {
 }
 
 // AllowPing enables ICMP Echo Request through Windows Firewall
-func (a *App) AllowPing() string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required to modify firewall."
+func (a *App) AllowPing() (result string) {
+	defer beginAudit("AllowPing", nil, nil).snapshot(pingChangeScope).finish(&result)
+	return a.allowPing(liveExecutor())
+}
+
+func (a *App) allowPing(x *executor) string {
+
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to modify firewall."); !ok {
+		return msg
 	}
 
 	// PowerShell command to enable ICMPv4 Echo Request rule
@@ -974,13 +958,35 @@ func (a *App) AllowPing() string {
 	`
 	cmd := exec.Command("powershell", "-Command", ps)
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	output, err := cmd.CombinedOutput()
+	output, err := x.output(cmd)
 	if err != nil {
 		return "Firewall Error: " + string(output) + " " + err.Error()
 	}
+	if x.dryRun {
+		return "✅ Success: Ping rule enabled (standard rule, or custom rule if missing)."
+	}
 	return string(output)
 }
 
+// BlockPing disables the ICMPv4 Echo Request firewall rules AllowPing enables
+func (a *App) BlockPing() (result string) {
+	defer beginAudit("BlockPing", nil, nil).snapshot(pingChangeScope).finish(&result)
+	return a.blockPing(liveExecutor())
+}
+
+func (a *App) blockPing(x *executor) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to modify firewall."); !ok {
+		return msg
+	}
+	// Either rule may be missing: the custom one only exists where AllowPing created it
+	ps := "Get-NetFirewallRule -DisplayName " + psQuote(pingRuleStandard) + ", " + psQuote(pingRuleCustom) +
+		" -ErrorAction SilentlyContinue | Disable-NetFirewallRule -ErrorAction Stop"
+	if output, err := x.output(psCommand(ps)); err != nil {
+		return "❌ Error: Cannot disable the ping rules: " + strings.TrimSpace(string(output)) + " " + err.Error()
+	}
+	return "✅ Success: Ping rules disabled."
+}
+
 // --- Helpers ---
 
 func loadConfig(path string) (*Config, error) {
@@ -1003,11 +1009,30 @@ func loadConfig(path string) (*Config, error) {
 }
 
 func checkNasAvailability(path string) bool {
-	if runtime.GOOS != "windows" {
+	if path == "" {
 		return false
 	}
-	_, err := os.Stat(path)
-	return err == nil
+	return probeNasRoots([]string{path}, defaultNasProbeTimeout, defaultNasCacheTTL)[0].Reachable
+}
+
+// runPowerShell runs a hidden PowerShell script and returns its combined output
+func runPowerShell(script string) (string, error) {
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// psQuote quotes a value as a PowerShell single-quoted string literal
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
 }
 
 func fileExists(path string) bool {
@@ -1190,11 +1215,17 @@ func isAdmin() bool {
 
 // --- v1.19.0 New Features ---
 
-func (a *App) OptimizeSystem(action string) string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required for optimizations."
+func (a *App) OptimizeSystem(action string) (result string) {
+	defer beginAudit("OptimizeSystem", map[string]string{"action": action}, optimizerAuditProbe).snapshot(optimizerChangeScope).finish(&result)
+	return a.optimizeSystem(liveExecutor(), action)
+}
+
+func (a *App) optimizeSystem(x *executor, action string) string {
+
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required for optimizations."); !ok {
+		return msg
 	}
-	scriptPath := extractEmbeddedScript("optimizer.ps1")
+	scriptPath := x.extract("optimizer.ps1")
 	if scriptPath == "" {
 		return "❌ Error: Failed to extract optimizer script."
 	}
@@ -1205,16 +1236,22 @@ func (a *App) OptimizeSystem(action string) string {
 		interactive = true
 	}
 
-	err := runInstaller(scriptPath, []string{"-Action", action}, interactive)
+	err := x.install(scriptPath, []string{"-Action", action}, interactive)
 	if err != nil {
 		return "❌ Error: Optimization failed - " + err.Error()
 	}
 	return "✅ Success: Optimization task '" + action + "' triggered."
 }
 
-func (a *App) SetUSBBlock(block bool) string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required."
+func (a *App) SetUSBBlock(block bool) (result string) {
+	defer beginAudit("SetUSBBlock", map[string]string{"block": fmt.Sprint(block)}, usbAuditProbe).snapshot(usbChangeScope).finish(&result)
+	return a.setUSBBlock(liveExecutor(), block)
+}
+
+func (a *App) setUSBBlock(x *executor, block bool) string {
+
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required."); !ok {
+		return msg
 	}
 	value := "3" // Default (Enabled)
 	if block {
@@ -1223,7 +1260,7 @@ func (a *App) SetUSBBlock(block bool) string {
 	// reg command is standard. We use hide window.
 	cmd := exec.Command("reg", "add", "HKLM\\SYSTEM\\CurrentControlSet\\Services\\USBSTOR", "/v", "Start", "/t", "REG_DWORD", "/d", value, "/f")
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	if err := cmd.Run(); err != nil {
+	if err := x.run(cmd); err != nil {
 		return "❌ Error: Failed to update USB policy: " + err.Error()
 	}
 
@@ -1234,9 +1271,15 @@ func (a *App) SetUSBBlock(block bool) string {
 	return "✅ Success: USB Storage is now " + status
 }
 
-func (a *App) SetRDPBlock(block bool) string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required."
+func (a *App) SetRDPBlock(block bool) (result string) {
+	defer beginAudit("SetRDPBlock", map[string]string{"block": fmt.Sprint(block)}, rdpAuditProbe).snapshot(rdpChangeScope).finish(&result)
+	return a.setRDPBlock(liveExecutor(), block)
+}
+
+func (a *App) setRDPBlock(x *executor, block bool) string {
+
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required."); !ok {
+		return msg
 	}
 	denyValue := "0" // Allow
 	if block {
@@ -1246,7 +1289,7 @@ func (a *App) SetRDPBlock(block bool) string {
 	// Registry Change
 	regCmd := exec.Command("reg", "add", "HKLM\\System\\CurrentControlSet\\Control\\Terminal Server", "/v", "fDenyTSConnections", "/t", "REG_DWORD", "/d", denyValue, "/f")
 	regCmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	regCmd.Run()
+	x.run(regCmd)
 
 	// Firewall Rules (Using PowerShell)
 	var fwCmd string
@@ -1255,7 +1298,7 @@ func (a *App) SetRDPBlock(block bool) string {
 	} else {
 		fwCmd = "Enable-NetFirewallRule -DisplayGroup 'Remote Desktop*'"
 	}
-	exec.Command("powershell", "-Command", fwCmd).Run()
+	x.run(exec.Command("powershell", "-Command", fwCmd))
 
 	status := "ENABLED"
 	if block {
@@ -1264,35 +1307,44 @@ func (a *App) SetRDPBlock(block bool) string {
 	return "✅ Success: RDP is now " + status
 }
 
-func (a *App) SetDomainWhitelist(domains string) string {
-	if !isAdmin() {
-		return "⚠️ Error: Administrative privileges required."
-	}
+func (a *App) SetDomainWhitelist(domains string) (result string) {
+	defer beginAudit("SetDomainWhitelist", map[string]string{"domains": domains}, domainWhitelistAuditProbe).snapshot(domainWhitelistChangeScope).finish(&result)
+	return a.setDomainWhitelist(liveExecutor(), domains)
+}
 
-	// Handle empty string as disable
-	if domains == "" || domains == "*" {
-		exec.Command("reg", "delete", "HKLM\\SOFTWARE\\Policies\\Google\\Chrome\\URLAllowlist", "/f").Run()
-		exec.Command("reg", "delete", "HKLM\\SOFTWARE\\Policies\\Microsoft\\Edge\\URLAllowlist", "/f").Run()
-		exec.Command("reg", "delete", "HKLM\\SOFTWARE\\Policies\\Google\\Chrome", "/v", "URLBlocklist", "/f").Run()
-		exec.Command("reg", "delete", "HKLM\\SOFTWARE\\Policies\\Microsoft\\Edge", "/v", "URLBlocklist", "/f").Run()
-		return "✅ Success: Domain filtering disabled (All domains allowed)."
+func (a *App) setDomainWhitelist(x *executor, domains string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required."); !ok {
+		return msg
 	}
 
-	domainList := strings.Split(domains, ",")
-
-	// Chrome Policies
-	exec.Command("reg", "add", "HKLM\\SOFTWARE\\Policies\\Google\\Chrome", "/v", "URLBlocklist", "/t", "REG_MULTI_SZ", "/d", "*", "/f").Run()
-	for i, d := range domainList {
-		valName := fmt.Sprintf("%d", i+1)
-		exec.Command("reg", "add", "HKLM\\SOFTWARE\\Policies\\Google\\Chrome\\URLAllowlist", "/v", valName, "/t", "REG_STRING", "/d", strings.TrimSpace(d), "/f").Run()
+	// Empty string or "*" disables filtering
+	var allow, block []string
+	if domains != "" && domains != "*" {
+		normalized, err := normalizeURLFilters(splitURLFilters(domains))
+		if err != nil {
+			return "❌ Error: Invalid domain - " + err.Error()
+		}
+		if len(normalized) > 0 {
+			allow, block = normalized, []string{"*"}
+		}
 	}
 
-	// Edge Policies
-	exec.Command("reg", "add", "HKLM\\SOFTWARE\\Policies\\Microsoft\\Edge", "/v", "URLBlocklist", "/t", "REG_MULTI_SZ", "/d", "*", "/f").Run()
-	for i, d := range domainList {
-		valName := fmt.Sprintf("%d", i+1)
-		exec.Command("reg", "add", "HKLM\\SOFTWARE\\Policies\\Microsoft\\Edge\\URLAllowlist", "/v", valName, "/t", "REG_STRING", "/d", strings.TrimSpace(d), "/f").Run()
+	// Chrome and Edge read list policies from numbered REG_SZ values under a subkey
+	for _, b := range chromiumBrowsers {
+		err := writeChromiumList(x, b, "URLAllowlist", allow)
+		if err == nil {
+			err = writeChromiumList(x, b, "URLBlocklist", block)
+		}
+		if err == nil {
+			err = x.regDeleteValue(registry.LOCAL_MACHINE, b.PolicyPath, "URLBlocklist")
+		}
+		if err != nil {
+			return "❌ Error: Failed to update " + b.Title + " policy: " + err.Error()
+		}
 	}
 
-	return "✅ Success: Domain Whitelist Applied (" + domains + ")"
+	if len(allow) == 0 {
+		return "✅ Success: Domain filtering disabled (All domains allowed)."
+	}
+	return "✅ Success: Domain Whitelist Applied (" + strings.Join(allow, ", ") + ")"
 }
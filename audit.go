@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord is one line of the append-only audit log
+type AuditRecord struct {
+	Time      string            `json:"time"`
+	User      string            `json:"user"`
+	Host      string            `json:"host"`
+	Operation string            `json:"operation"`
+	Params    map[string]string `json:"params"`
+	Result    string            `json:"result"`
+	Success   bool              `json:"success"`
+	Before    map[string]string `json:"before"`
+	After     map[string]string `json:"after"`
+	ChangeID  string            `json:"change_id,omitempty"` // pass to Rollback to undo
+	Seq       int64             `json:"seq"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+	Signature string            `json:"signature,omitempty"`
+}
+
+// AuditFilter narrows GetAuditLog / ExportAuditCSV. Empty fields match everything.
+type AuditFilter struct {
+	Operation    string `json:"operation"`
+	User         string `json:"user"`
+	Since        string `json:"since"` // RFC3339 or 2006-01-02
+	Until        string `json:"until"` // RFC3339 or 2006-01-02 (inclusive)
+	Text         string `json:"text"`
+	FailuresOnly bool   `json:"failures_only"`
+	Limit        int    `json:"limit"`
+}
+
+// Parameter names containing any of these are never written to the log
+var auditSecretKeys = []string{"pass", "secret", "token", "credential", "key"}
+
+var auditMu sync.Mutex
+
+// appDataDir is the machine-wide folder for the toolkit's logs and state
+func appDataDir() string {
+	base := os.Getenv("ProgramData")
+	if base == "" {
+		return filepath.Join(TempDir, "data")
+	}
+	return filepath.Join(base, "Triveni-Control-Center")
+}
+
+func auditLogPath() string {
+	return filepath.Join(appDataDir(), "audit.jsonl")
+}
+
+// auditEntry is an operation in progress; finish writes it to the log
+type auditEntry struct {
+	record AuditRecord
+	probe  func() map[string]string
+	change *changeRecord
+}
+
+// beginAudit captures who is doing what, and the "before" values from probe (may be nil).
+// Use as: defer beginAudit(...).finish(&result)
+func beginAudit(operation string, params map[string]string, probe func() map[string]string) *auditEntry {
+	e := &auditEntry{
+		record: AuditRecord{
+			Time:      time.Now().Format(time.RFC3339),
+			User:      currentUserName(),
+			Host:      hostName(),
+			Operation: operation,
+			Params:    redactParams(params),
+		},
+		probe: probe,
+	}
+	if probe != nil {
+		e.record.Before = probe()
+	}
+	return e
+}
+
+// snapshot captures the settings in scope before the operation runs, for Rollback.
+// Use as: defer beginAudit(...).snapshot(scope).finish(&result)
+func (e *auditEntry) snapshot(scope changeScope) *auditEntry {
+	return e.withChange(beginChange(e.record.Operation, e.record.Params, scope))
+}
+
+// withChange attaches a snapshot taken by beginChange; it is kept unless the operation was refused before changing anything
+func (e *auditEntry) withChange(c *changeRecord) *auditEntry {
+	e.change = c
+	return e
+}
+
+// finish records the result and the "after" values
+func (e *auditEntry) finish(result *string) {
+	e.record.Result = strings.TrimSpace(*result)
+	e.record.Success = resultSucceeded(*result)
+	if e.record.Success {
+		noteRebootReason(e.record.Operation, e.record.Result)
+	}
+	// A failed operation may have stopped partway (Edge after Chrome, the wallpaper before
+	// the lock screen), so its snapshot is kept for Rollback as well
+	if e.change != nil && !refusedBeforeChanging(e.record.Result) {
+		e.change.Failed = !e.record.Success
+		if err := e.change.save(); err != nil {
+			fmt.Printf("Error saving rollback snapshot for %s: %v\n", e.record.Operation, err)
+		} else {
+			e.record.ChangeID = e.change.ID
+		}
+	}
+	if e.probe != nil {
+		e.record.After = e.probe()
+	}
+	if err := appendAuditRecord(e.record); err != nil {
+		fmt.Printf("Error writing audit record for %s: %v\n", e.record.Operation, err)
+	}
+}
+
+// refusedBeforeChanging tells whether the operation stopped at the privilege check
+func refusedBeforeChanging(result string) bool {
+	return strings.HasPrefix(result, "⚠️ Error: Administrative privileges required")
+}
+
+// appendAuditRecord links rec onto the hash chain and appends it (plus a
+// signed checkpoint when due) while holding an exclusive file lock.
+func appendAuditRecord(rec AuditRecord) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if err := os.MkdirAll(appDataDir(), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(auditLogPath(), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	unlock, err := lockAuditFile(f)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tail, err := lastAuditRecord(f)
+	if err != nil {
+		return fmt.Errorf("cannot read chain head: %w", err)
+	}
+
+	// A damaged tail must not stop auditing: note the break and chain on from the last good record
+	var buf []byte
+	last := tail.Last
+	if tail.NeedsBreak {
+		buf = append(buf, '\n')
+	}
+	if tail.Damaged > 0 {
+		last = chainBreakRecord(tail)
+		line, err := json.Marshal(last)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	records := chainAuditRecords(last, rec)
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	if _, err := f.Write(buf); err != nil {
+		return err
+	}
+	return writeAuditHead(records[len(records)-1])
+}
+
+// resultSucceeded interprets the status strings returned by App methods
+func resultSucceeded(result string) bool {
+	return strings.Contains(result, "✅") || strings.HasPrefix(strings.TrimSpace(result), "ℹ️")
+}
+
+func redactParams(params map[string]string) map[string]string {
+	if params == nil {
+		return nil
+	}
+	out := make(map[string]string, len(params))
+	for k, v := range params {
+		lk := strings.ToLower(k)
+		out[k] = v
+		for _, s := range auditSecretKeys {
+			if strings.Contains(lk, s) {
+				out[k] = "***REDACTED***"
+				break
+			}
+		}
+	}
+	return out
+}
+
+var (
+	auditUserOnce sync.Once
+	auditUser     string
+)
+
+// currentUserName returns DOMAIN\user of the account running the toolkit
+func currentUserName() string {
+	auditUserOnce.Do(func() {
+		if u, err := user.Current(); err == nil {
+			auditUser = u.Username
+		} else {
+			auditUser = os.Getenv("USERDOMAIN") + "\\" + os.Getenv("USERNAME")
+		}
+	})
+	return auditUser
+}
+
+func hostName() string {
+	h, _ := os.Hostname()
+	return h
+}
+
+// --- Viewer ---
+
+// readAuditLog loads every record from the log, oldest first
+func readAuditLog() ([]AuditRecord, error) {
+	f, err := os.Open(auditLogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
+
+func parseAuditTime(s string, endOfDay bool) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func (f AuditFilter) matches(rec AuditRecord) bool {
+	if f.Operation != "" && !strings.EqualFold(rec.Operation, f.Operation) {
+		return false
+	}
+	// Checkpoints are chain bookkeeping; only show them when asked for
+	if f.Operation == "" && rec.Operation == auditCheckpointOp {
+		return false
+	}
+	if f.User != "" && !strings.Contains(strings.ToLower(rec.User), strings.ToLower(f.User)) {
+		return false
+	}
+	if f.FailuresOnly && rec.Success {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, rec.Time)
+	if since, ok := parseAuditTime(f.Since, false); ok && (err != nil || t.Before(since)) {
+		return false
+	}
+	if until, ok := parseAuditTime(f.Until, true); ok && (err != nil || t.After(until)) {
+		return false
+	}
+	if f.Text != "" {
+		line, _ := json.Marshal(rec)
+		if !strings.Contains(strings.ToLower(string(line)), strings.ToLower(f.Text)) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterAudit returns matching records, newest first, capped at filter.Limit
+func filterAudit(records []AuditRecord, filter AuditFilter) []AuditRecord {
+	result := []AuditRecord{}
+	for i := len(records) - 1; i >= 0; i-- {
+		if filter.matches(records[i]) {
+			result = append(result, records[i])
+			if filter.Limit > 0 && len(result) >= filter.Limit {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// GetAuditLog returns audit records matching the filter, newest first
+func (a *App) GetAuditLog(filter AuditFilter) []AuditRecord {
+	records, err := readAuditLog()
+	if err != nil {
+		return []AuditRecord{}
+	}
+	return filterAudit(records, filter)
+}
+
+// ExportAuditCSV writes the matching audit records to a CSV file (default: Documents folder)
+func (a *App) ExportAuditCSV(filter AuditFilter, path string) string {
+	records, err := readAuditLog()
+	if err != nil {
+		return "❌ Error: Could not read audit log - " + err.Error()
+	}
+	if path == "" {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, "Documents", "triveni-audit-"+time.Now().Format("20060102-150405")+".csv")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	defer f.Close()
+
+	matched := filterAudit(records, filter)
+	w := csv.NewWriter(f)
+	w.Write([]string{"time", "user", "host", "operation", "params", "result", "success", "before", "after", "change_id"})
+	for _, rec := range matched {
+		w.Write([]string{
+			rec.Time, rec.User, rec.Host, rec.Operation, formatAuditMap(rec.Params),
+			rec.Result, fmt.Sprint(rec.Success), formatAuditMap(rec.Before), formatAuditMap(rec.After), rec.ChangeID,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return fmt.Sprintf("✅ Success: %d audit records exported to %s", len(matched), path)
+}
+
+// formatAuditMap renders a map as "k=v; k=v" in key order
+func formatAuditMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + m[k]
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// A signed checkpoint record is appended after every auditCheckpointInterval records
+const auditCheckpointInterval = 50
+
+const auditCheckpointOp = "audit.checkpoint"
+
+// auditChainBreakOp marks where appending resumed after a damaged tail (e.g. a line cut off by a
+// crash). It links to the last readable record, so the records after it still verify.
+const auditChainBreakOp = "audit.chain_break"
+
+// AuditVerifyReport is the outcome of VerifyAudit
+type AuditVerifyReport struct {
+	Valid             bool     `json:"valid"`
+	Records           int      `json:"records"`
+	LegacyRecords     int      `json:"legacy_records"`
+	Checkpoints       int      `json:"checkpoints"`
+	LastSeq           int64    `json:"last_seq"`
+	LastCheckpointSeq int64    `json:"last_checkpoint_seq"`
+	Problems          []string `json:"problems"`
+}
+
+// auditHead is the signed pointer to the newest record; it reveals truncation of the log's tail
+type auditHead struct {
+	Seq       int64  `json:"seq"`
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+}
+
+func auditKeyPath() string  { return filepath.Join(appDataDir(), "audit.key") }
+func auditPubPath() string  { return filepath.Join(appDataDir(), "audit.pub") }
+func auditHeadPath() string { return filepath.Join(appDataDir(), "audit.head") }
+
+// auditRecordHash hashes a record (which already carries prev_hash) with its own hash and signature blanked
+func auditRecordHash(rec AuditRecord) string {
+	rec.Hash = ""
+	rec.Signature = ""
+	data, _ := json.Marshal(rec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// chainAuditRecords links records onto the chain after last and adds a signed
+// checkpoint when the interval is reached. It returns the lines to append.
+func chainAuditRecords(last AuditRecord, rec AuditRecord) []AuditRecord {
+	rec.Seq = last.Seq + 1
+	rec.PrevHash = last.Hash
+	rec.Hash = auditRecordHash(rec)
+	out := []AuditRecord{rec}
+
+	if rec.Seq%auditCheckpointInterval == 0 {
+		cp := AuditRecord{
+			Time:      time.Now().Format(time.RFC3339),
+			User:      rec.User,
+			Host:      rec.Host,
+			Operation: auditCheckpointOp,
+			Params:    map[string]string{"covers_seq": strconv.FormatInt(rec.Seq, 10), "chain_hash": rec.Hash},
+			Result:    "Checkpoint",
+			Success:   true,
+			Seq:       rec.Seq + 1,
+			PrevHash:  rec.Hash,
+		}
+		cp.Hash = auditRecordHash(cp)
+		if sig, err := signAudit([]byte(cp.Hash)); err == nil {
+			cp.Signature = sig
+		} else {
+			fmt.Printf("Error signing audit checkpoint: %v\n", err)
+		}
+		out = append(out, cp)
+	}
+	return out
+}
+
+// auditTail is the end of the (locked) log file as appendAuditRecord needs it
+type auditTail struct {
+	Last       AuditRecord // last readable record
+	Damaged    int         // unreadable lines after it
+	NeedsBreak bool        // the file does not end with a newline
+}
+
+// lastAuditRecord reads the log backwards to the last readable record, counting the
+// unreadable lines after it instead of failing on them
+func lastAuditRecord(f *os.File) (auditTail, error) {
+	var tail auditTail
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return tail, err
+	}
+
+	for window := int64(64 * 1024); ; window *= 4 {
+		if window > info.Size() {
+			window = info.Size()
+		}
+		buf := make([]byte, window)
+		if _, err := f.ReadAt(buf, info.Size()-window); err != nil {
+			return tail, err
+		}
+		tail.NeedsBreak = buf[len(buf)-1] != '\n'
+		lines := bytes.Split(bytes.TrimRight(buf, "\r\n"), []byte("\n"))
+		// The first line of a partial window may be cut off, unless we read the whole file
+		first := 1
+		if window == info.Size() {
+			first = 0
+		}
+		tail.Damaged = 0
+		for i := len(lines) - 1; i >= first; i-- {
+			line := bytes.TrimSpace(lines[i])
+			if len(line) == 0 {
+				continue
+			}
+			var rec AuditRecord
+			if json.Unmarshal(line, &rec) == nil {
+				tail.Last = rec
+				return tail, nil
+			}
+			tail.Damaged++
+		}
+		if window == info.Size() {
+			// Nothing readable at all: the chain starts over after the break marker
+			return tail, nil
+		}
+	}
+}
+
+// chainBreakRecord is the signed marker appended after a damaged tail
+func chainBreakRecord(tail auditTail) AuditRecord {
+	rec := AuditRecord{
+		Time:      time.Now().Format(time.RFC3339),
+		User:      currentUserName(),
+		Host:      hostName(),
+		Operation: auditChainBreakOp,
+		Params: map[string]string{
+			"damaged_lines":  strconv.Itoa(tail.Damaged),
+			"last_good_seq":  strconv.FormatInt(tail.Last.Seq, 10),
+			"last_good_hash": tail.Last.Hash,
+		},
+		Result:   fmt.Sprintf("Chain break: %d unreadable line(s) after seq %d", tail.Damaged, tail.Last.Seq),
+		Success:  true,
+		Seq:      tail.Last.Seq + 1,
+		PrevHash: tail.Last.Hash,
+	}
+	rec.Hash = auditRecordHash(rec)
+	if sig, err := signAudit([]byte(rec.Hash)); err == nil {
+		rec.Signature = sig
+	} else {
+		fmt.Printf("Error signing audit chain break: %v\n", err)
+	}
+	return rec
+}
+
+// lockAuditFile takes an exclusive lock so the GUI and the enforcement service never fork the chain
+func lockAuditFile(f *os.File) (func(), error) {
+	h := windows.Handle(f.Fd())
+	ol := new(windows.Overlapped)
+	if err := windows.LockFileEx(h, windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, ol); err != nil {
+		return nil, err
+	}
+	return func() { windows.UnlockFileEx(h, 0, 1, 0, ol) }, nil
+}
+
+func writeAuditHead(rec AuditRecord) error {
+	head := auditHead{Seq: rec.Seq, Hash: rec.Hash}
+	sig, err := signAudit([]byte(auditHeadPayload(head)))
+	if err != nil {
+		return err
+	}
+	head.Signature = sig
+
+	data, _ := json.Marshal(head)
+	tmp := auditHeadPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, auditHeadPath())
+}
+
+func auditHeadPayload(h auditHead) string {
+	return strconv.FormatInt(h.Seq, 10) + "|" + h.Hash
+}
+
+// --- Signing key ---
+
+var (
+	auditKeyMu sync.Mutex
+	auditKey   ed25519.PrivateKey
+)
+
+// auditSigningKey loads (or on first use creates) the ed25519 key. The seed is
+// kept DPAPI-protected in audit.key and the public half in audit.pub, both readable by
+// SYSTEM and Administrators only.
+func auditSigningKey() (ed25519.PrivateKey, error) {
+	auditKeyMu.Lock()
+	defer auditKeyMu.Unlock()
+	if auditKey != nil {
+		return auditKey, nil
+	}
+
+	blob, err := os.ReadFile(auditKeyPath())
+	if err == nil {
+		if !adminOwnedFile(auditKeyPath()) {
+			return nil, errors.New("audit key was not written by an administrator")
+		}
+		seed, err := dpapiUnprotect(blob)
+		if err != nil {
+			return nil, fmt.Errorf("audit key unreadable: %w", err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, errors.New("audit key corrupt")
+		}
+		auditKey = ed25519.NewKeyFromSeed(seed)
+		return auditKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	// The seed is machine-scope DPAPI, so whoever creates the file could read it back
+	if !isAdmin() {
+		return nil, errors.New("the audit key is created by the first elevated run")
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	protected, err := dpapiProtect(priv.Seed())
+	if err != nil {
+		return nil, err
+	}
+	if err := writeAdminOnlyFile(auditKeyPath(), protected); err != nil {
+		return nil, err
+	}
+	if err := writeAdminOnlyFile(auditPubPath(), []byte(hex.EncodeToString(pub))); err != nil {
+		return nil, err
+	}
+	auditKey = priv
+	return auditKey, nil
+}
+
+func signAudit(payload []byte) (string, error) {
+	key, err := auditSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(ed25519.Sign(key, payload)), nil
+}
+
+func auditPublicKey() (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(auditPubPath())
+	if err != nil {
+		return nil, err
+	}
+	if !adminOwnedFile(auditPubPath()) {
+		return nil, errors.New("audit.pub was not written by an administrator")
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("audit.pub is corrupt")
+	}
+	return key, nil
+}
+
+func verifyAuditSignature(pub ed25519.PublicKey, payload, sig string) bool {
+	raw, err := hex.DecodeString(sig)
+	return err == nil && pub != nil && ed25519.Verify(pub, []byte(payload), raw)
+}
+
+// dpapiProtect encrypts data so only this machine can decrypt it
+func dpapiProtect(data []byte) ([]byte, error) {
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_LOCAL_MACHINE|windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+func dpapiUnprotect(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty blob")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+// --- Verification ---
+
+// verifyAuditLog walks the whole log and reports any break in the chain:
+// modified records (hash mismatch), deleted or inserted records (sequence or
+// prev_hash mismatch), bad checkpoint signatures and a truncated tail.
+func verifyAuditLog() AuditVerifyReport {
+	report := AuditVerifyReport{Problems: []string{}}
+	problem := func(format string, args ...any) {
+		report.Problems = append(report.Problems, fmt.Sprintf(format, args...))
+	}
+
+	pub, pubErr := auditPublicKey()
+
+	f, err := os.Open(auditLogPath())
+	if os.IsNotExist(err) {
+		if fileExists(auditHeadPath()) {
+			problem("audit log is missing but audit.head exists")
+		}
+		report.Valid = len(report.Problems) == 0
+		return report
+	}
+	if err != nil {
+		problem("cannot open audit log: %v", err)
+		return report
+	}
+	defer f.Close()
+
+	var prev AuditRecord
+	chained := false
+	lineNo := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			problem("line %d: unreadable record", lineNo)
+			continue
+		}
+		report.Records++
+
+		// Records written before hash chaining was introduced
+		if rec.Hash == "" && rec.Seq == 0 {
+			if chained {
+				problem("line %d: unchained record inside the chain (inserted?)", lineNo)
+			} else {
+				report.LegacyRecords++
+			}
+			continue
+		}
+
+		if rec.Seq != prev.Seq+1 {
+			if rec.Seq > prev.Seq+1 {
+				problem("line %d: seq %d follows %d (records %d-%d deleted?)", lineNo, rec.Seq, prev.Seq, prev.Seq+1, rec.Seq-1)
+			} else {
+				problem("line %d: seq %d follows %d (record inserted or reordered?)", lineNo, rec.Seq, prev.Seq)
+			}
+		}
+		if rec.PrevHash != prev.Hash {
+			problem("line %d (seq %d): prev_hash does not match the preceding record", lineNo, rec.Seq)
+		}
+		if auditRecordHash(rec) != rec.Hash {
+			problem("line %d (seq %d): content was modified (hash mismatch)", lineNo, rec.Seq)
+		}
+		if rec.Operation == auditCheckpointOp {
+			report.Checkpoints++
+			report.LastCheckpointSeq = rec.Seq
+			if pubErr != nil {
+				problem("line %d (seq %d): cannot check checkpoint signature: %v", lineNo, rec.Seq, pubErr)
+			} else if !verifyAuditSignature(pub, rec.Hash, rec.Signature) {
+				problem("line %d (seq %d): checkpoint signature is invalid", lineNo, rec.Seq)
+			}
+		}
+		if rec.Operation == auditChainBreakOp {
+			// The unreadable lines it skips are reported above; the marker itself must be genuine
+			if pubErr != nil {
+				problem("line %d (seq %d): cannot check chain break signature: %v", lineNo, rec.Seq, pubErr)
+			} else if !verifyAuditSignature(pub, rec.Hash, rec.Signature) {
+				problem("line %d (seq %d): chain break signature is invalid", lineNo, rec.Seq)
+			}
+		}
+
+		chained = true
+		prev = rec
+	}
+	if err := scanner.Err(); err != nil {
+		problem("read error: %v", err)
+	}
+	report.LastSeq = prev.Seq
+
+	// The signed head must point at the last record, otherwise the tail was cut off
+	data, err := os.ReadFile(auditHeadPath())
+	switch {
+	case err != nil && chained:
+		problem("audit.head is missing")
+	case err == nil:
+		var head auditHead
+		if json.Unmarshal(data, &head) != nil {
+			problem("audit.head is unreadable")
+		} else {
+			if pubErr == nil && !verifyAuditSignature(pub, auditHeadPayload(head), head.Signature) {
+				problem("audit.head signature is invalid")
+			}
+			if head.Seq != prev.Seq || head.Hash != prev.Hash {
+				problem("log ends at seq %d but audit.head records seq %d (tail deleted or appended outside the toolkit?)", prev.Seq, head.Seq)
+			}
+		}
+	}
+
+	report.Valid = len(report.Problems) == 0
+	return report
+}
+
+// VerifyAudit checks the audit log for deletion, insertion or modification
+func (a *App) VerifyAudit() AuditVerifyReport {
+	return verifyAuditLog()
+}
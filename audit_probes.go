@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// Registry values read before and after each audited change
+
+var usbAuditProbe = regProbe(
+	regRef{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\USBSTOR`, "Start"},
+)
+
+var rdpAuditProbe = regProbe(
+	regRef{registry.LOCAL_MACHINE, `System\CurrentControlSet\Control\Terminal Server`, "fDenyTSConnections"},
+)
+
+var domainWhitelistAuditProbe = regProbe(
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Google\Chrome`, "URLBlocklist"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Google\Chrome\URLBlocklist`, "*"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Google\Chrome\URLAllowlist`, "*"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Edge`, "URLBlocklist"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Edge\URLBlocklist`, "*"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Edge\URLAllowlist`, "*"},
+)
+
+// Password values are deliberately not probed
+var vncAuditProbe = regProbe(
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\TightVNC\Server`, "UseVncAuthentication"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\TightVNC\Server`, "UseControlAuthentication"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\TightVNC\Server`, "IpAccessControl"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\TightVNC\Server`, "RfbPort"},
+)
+
+var timeAuditProbe = regProbe(
+	regRef{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\TimeZoneInformation`, "TimeZoneKeyName"},
+	regRef{registry.CURRENT_USER, `Control Panel\International`, "sShortTime"},
+	regRef{registry.CURRENT_USER, `Control Panel\International`, "sTimeFormat"},
+	regRef{registry.CURRENT_USER, `Control Panel\International`, "sShortDate"},
+	regRef{registry.CURRENT_USER, `Control Panel\International`, "LocaleName"},
+	regRef{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\W32Time\Parameters`, "NtpServer"},
+)
+
+var thisPCAuditProbe = regProbe(
+	regRef{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\HideDesktopIcons\NewStartPanel`, "{20D04FE0-3AEA-1069-A2D8-08002B30309D}"},
+)
+
+var wallpaperAuditProbe = regProbe(
+	regRef{registry.CURRENT_USER, `Control Panel\Desktop`, "Wallpaper"},
+)
+
+var personalizationAuditProbe = regProbe(
+	regRef{registry.CURRENT_USER, `Control Panel\Desktop`, "Wallpaper"},
+	regRef{registry.CURRENT_USER, `Control Panel\Desktop`, "WallpaperStyle"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Windows\Personalization`, "LockScreenImage"},
+	regRef{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\PersonalizationCSP`, "LockScreenImagePath"},
+	regRef{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\HideDesktopIcons\NewStartPanel`, "*"},
+)
+
+var optimizerAuditProbe = regProbe(
+	regRef{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\SysMain`, "Start"},
+	regRef{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, "EnableTransparency"},
+	regRef{registry.CURRENT_USER, `Control Panel\Desktop\WindowMetrics`, "MinAnimate"},
+	regRef{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\VisualEffects`, "VisualFXSetting"},
+	regRef{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\Advanced`, "TaskbarAnimations"},
+)
+
+// renameAuditProbe records the running hostname and the name pending after reboot
+func renameAuditProbe() map[string]string {
+	values := regProbe(regRef{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\ComputerName\ComputerName`, "ComputerName"})()
+	values["hostname"] = hostName()
+	return values
+}
+
+// installAuditProbe records whether the software is detected as installed
+func installAuditProbe(name string) func() map[string]string {
+	return func() map[string]string {
+		return map[string]string{"installed": fmt.Sprint(isSoftwareInstalled(name))}
+	}
+}
+
+// networkAuditProbe records the IPv4 configuration of connected adapters
+func networkAuditProbe() map[string]string {
+	ps := `Get-NetIPConfiguration | Where-Object { $_.NetAdapter.Status -eq 'Up' } | ForEach-Object {
+		[PSCustomObject]@{
+			Adapter = $_.InterfaceAlias
+			IPv4    = ($_.IPv4Address | ForEach-Object { "$($_.IPAddress)/$($_.PrefixLength)" }) -join ','
+			Gateway = ($_.IPv4DefaultGateway | ForEach-Object { $_.NextHop }) -join ','
+			DNS     = ($_.DNSServer | Where-Object { $_.AddressFamily -eq 2 } | ForEach-Object { $_.ServerAddresses }) -join ','
+			DHCP    = (Get-NetIPInterface -InterfaceIndex $_.InterfaceIndex -AddressFamily IPv4).Dhcp
+		}
+	} | ConvertTo-Json -Compress`
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", ps)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+
+	var adapters []map[string]any
+	text := strings.TrimSpace(string(out))
+	if strings.HasPrefix(text, "{") {
+		text = "[" + text + "]"
+	}
+	json.Unmarshal([]byte(text), &adapters)
+
+	values := map[string]string{}
+	for _, ad := range adapters {
+		name := fmt.Sprint(ad["Adapter"])
+		for _, field := range []string{"IPv4", "Gateway", "DNS", "DHCP"} {
+			values[name+"."+field] = fmt.Sprint(ad[field])
+		}
+	}
+	return values
+}
+
+// powerAuditProbe records the active power plan
+func powerAuditProbe() map[string]string {
+	out, err := runPowercfg("/getactivescheme")
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	_, plan, _ := strings.Cut(strings.TrimSpace(out), "GUID:")
+	return map[string]string{"active_scheme": strings.TrimSpace(plan)}
+}
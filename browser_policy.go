@@ -0,0 +1,567 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// BrowserPolicy is the desired managed browser configuration (browser_policy in config.json).
+// Empty fields are unmanaged: applying the policy removes any value we previously set.
+type BrowserPolicy struct {
+	Browsers  []string `json:"browsers"`  // chrome, edge, firefox; empty = all
+	Allowlist []string `json:"allowlist"` // URL filter patterns
+	Blocklist []string `json:"blocklist"` // defaults to "*" when an allowlist is given
+	// Chrome/Edge extension IDs, optionally "id;update_url"
+	ForceInstallExtensions []string           `json:"force_install_extensions"`
+	BlockedExtensions      []string           `json:"blocked_extensions"` // "*" = everything not force-installed
+	FirefoxExtensions      []FirefoxExtension `json:"firefox_extensions"`
+	FirefoxBlocked         []string           `json:"firefox_blocked_extensions"` // "*" = everything not force-installed
+	Homepage               string             `json:"homepage"`
+	DNSOverHTTPS           string             `json:"dns_over_https"` // off, automatic, secure
+	DoHTemplate            string             `json:"doh_template"`   // required for secure
+	DisableIncognito       bool               `json:"disable_incognito"`
+}
+
+// FirefoxExtension is an add-on force-installed through policies.json
+type FirefoxExtension struct {
+	ID         string `json:"id"`
+	InstallURL string `json:"install_url"`
+}
+
+// chromiumBrowser describes where a Chromium browser reads machine policy
+type chromiumBrowser struct {
+	Name          string
+	Title         string
+	PolicyPath    string
+	UpdateURL     string // default extension update URL
+	IncognitoName string
+}
+
+var chromiumBrowsers = []chromiumBrowser{
+	{"chrome", "Chrome", `SOFTWARE\Policies\Google\Chrome`, "https://clients2.google.com/service/update2/crx", "IncognitoModeAvailability"},
+	{"edge", "Edge", `SOFTWARE\Policies\Microsoft\Edge`, "https://edge.microsoft.com/extensionwebstorebase/v1/crx", "InPrivateModeAvailability"},
+}
+
+// List policies are subkeys with values "1", "2", ...
+var chromiumListPolicies = []string{"URLAllowlist", "URLBlocklist", "ExtensionInstallForcelist", "ExtensionInstallBlocklist"}
+
+// Scalar policies we manage on the browser's policy key
+var chromiumScalarPolicies = []string{"HomepageLocation", "HomepageIsNewTabPage", "ShowHomeButton", "DnsOverHttpsMode", "DnsOverHttpsTemplates"}
+
+// Top-level policies.json keys we manage; everything else in the file is preserved
+var firefoxManagedPolicies = []string{"WebsiteFilter", "ExtensionSettings", "Homepage", "DNSOverHTTPS", "DisablePrivateBrowsing"}
+
+var extensionIDPattern = regexp.MustCompile(`^[a-p]{32}$`)
+
+// --- Validation ---
+
+// validateURLPattern checks a Chrome URL filter entry (see parseURLFilter)
+func validateURLPattern(p string) error {
+	if _, err := parseURLFilter(p); err != nil {
+		return fmt.Errorf("%q: %v", p, err)
+	}
+	return nil
+}
+
+func validateChromiumExtension(entry string) error {
+	id, update, hasUpdate := strings.Cut(entry, ";")
+	if id != "*" && !extensionIDPattern.MatchString(id) {
+		return fmt.Errorf("%q is not an extension ID (32 letters a-p)", id)
+	}
+	if hasUpdate {
+		if u, err := url.Parse(update); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("update URL %q for %s must be https", update, id)
+		}
+	}
+	return nil
+}
+
+// validate reports every problem with the policy, so the admin can fix them in one go
+func (p BrowserPolicy) validate() []string {
+	var problems []string
+	for _, b := range p.Browsers {
+		if b != "chrome" && b != "edge" && b != "firefox" {
+			problems = append(problems, fmt.Sprintf("unknown browser %q (use chrome, edge, firefox)", b))
+		}
+	}
+	for _, list := range [][]string{p.Allowlist, p.Blocklist} {
+		for _, pattern := range list {
+			if err := validateURLPattern(pattern); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	for _, list := range [][]string{p.ForceInstallExtensions, p.BlockedExtensions} {
+		for _, e := range list {
+			if err := validateChromiumExtension(e); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	for _, e := range p.ForceInstallExtensions {
+		if strings.HasPrefix(e, "*") {
+			problems = append(problems, "\"*\" cannot be force-installed")
+		}
+	}
+	for _, e := range p.FirefoxExtensions {
+		if e.ID == "" || e.ID == "*" {
+			problems = append(problems, "Firefox extension without an ID")
+		}
+		if u, err := url.Parse(e.InstallURL); err != nil || (u.Scheme != "https" && u.Scheme != "file") {
+			problems = append(problems, fmt.Sprintf("Firefox extension %s needs an https or file install_url", e.ID))
+		}
+	}
+	if p.Homepage != "" {
+		if u, err := url.Parse(p.Homepage); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("homepage %q must be an http(s) URL", p.Homepage))
+		}
+	}
+	switch p.DNSOverHTTPS {
+	case "", "off", "automatic":
+	case "secure":
+		if u, err := url.Parse(strings.Split(p.DoHTemplate, "{")[0]); err != nil || u.Scheme != "https" || u.Host == "" {
+			problems = append(problems, "dns_over_https \"secure\" needs an https doh_template")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("dns_over_https %q must be off, automatic or secure", p.DNSOverHTTPS))
+	}
+	return problems
+}
+
+func (p BrowserPolicy) targets(browser string) bool {
+	if len(p.Browsers) == 0 {
+		return true
+	}
+	for _, b := range p.Browsers {
+		if b == browser {
+			return true
+		}
+	}
+	return false
+}
+
+// urlAllowlist is the allowlist as written to the browsers. Apply and verify both read it
+// from here, so an entry that normalizes differently is not reported as drift.
+func (p BrowserPolicy) urlAllowlist() []string {
+	allow, _ := normalizeURLFilters(p.Allowlist)
+	return allow
+}
+
+// effectiveBlocklist is the normalized blocklist, blocking everything else when only an allowlist is given
+func (p BrowserPolicy) effectiveBlocklist() []string {
+	block, _ := normalizeURLFilters(p.Blocklist)
+	if len(block) == 0 && len(p.urlAllowlist()) > 0 {
+		return []string{"*"}
+	}
+	return block
+}
+
+// --- Chromium (Chrome, Edge) ---
+
+// chromiumLists renders the list policies for a browser
+func (p BrowserPolicy) chromiumLists(b chromiumBrowser) map[string][]string {
+	force := make([]string, len(p.ForceInstallExtensions))
+	for i, e := range p.ForceInstallExtensions {
+		if !strings.Contains(e, ";") {
+			e += ";" + b.UpdateURL
+		}
+		force[i] = e
+	}
+	return map[string][]string{
+		"URLAllowlist":              p.urlAllowlist(),
+		"URLBlocklist":              p.effectiveBlocklist(),
+		"ExtensionInstallForcelist": force,
+		"ExtensionInstallBlocklist": p.BlockedExtensions,
+	}
+}
+
+// chromiumScalars renders the single-value policies (nil = remove)
+func (p BrowserPolicy) chromiumScalars(b chromiumBrowser) map[string]any {
+	values := map[string]any{}
+	for _, name := range chromiumScalarPolicies {
+		values[name] = nil
+	}
+	values[b.IncognitoName] = nil
+
+	if p.Homepage != "" {
+		values["HomepageLocation"] = p.Homepage
+		values["HomepageIsNewTabPage"] = uint32(0)
+		values["ShowHomeButton"] = uint32(1)
+	}
+	if p.DNSOverHTTPS != "" {
+		values["DnsOverHttpsMode"] = p.DNSOverHTTPS
+		if p.DNSOverHTTPS == "secure" {
+			values["DnsOverHttpsTemplates"] = p.DoHTemplate
+		}
+	}
+	if p.DisableIncognito {
+		values[b.IncognitoName] = uint32(1)
+	}
+	return values
+}
+
+// writeChromiumList replaces a list policy with values "1".."n" (REG_SZ); empty removes it
+func writeChromiumList(x *executor, b chromiumBrowser, name string, values []string) error {
+	return writeRegList(x, registry.LOCAL_MACHINE, b.PolicyPath+`\`+name, values)
+}
+
+// readChromiumList returns a list policy in value order
+func readChromiumList(b chromiumBrowser, name string) []string {
+	return readRegList(registry.LOCAL_MACHINE, b.PolicyPath+`\`+name)
+}
+
+func applyChromiumPolicy(x *executor, b chromiumBrowser, p BrowserPolicy) error {
+	lists := p.chromiumLists(b)
+	for _, name := range chromiumListPolicies {
+		if err := writeChromiumList(x, b, name, lists[name]); err != nil {
+			return err
+		}
+	}
+	// Older versions of SetDomainWhitelist wrote URLBlocklist as a value instead of a list key
+	if err := x.regDeleteValue(registry.LOCAL_MACHINE, b.PolicyPath, "URLBlocklist"); err != nil {
+		return err
+	}
+
+	scalars := p.chromiumScalars(b)
+	names := make([]string, 0, len(scalars))
+	for n := range scalars {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var err error
+		if scalars[name] == nil {
+			err = x.regDeleteValue(registry.LOCAL_MACHINE, b.PolicyPath, name)
+		} else {
+			err = x.regSet(registry.LOCAL_MACHINE, b.PolicyPath, name, scalars[name])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chromiumDrift lists differences between the desired policy and the registry
+func chromiumDrift(b chromiumBrowser, p BrowserPolicy) []string {
+	var drift []string
+	lists := p.chromiumLists(b)
+	for _, name := range chromiumListPolicies {
+		want, got := lists[name], readChromiumList(b, name)
+		if strings.Join(want, "\n") != strings.Join(got, "\n") {
+			drift = append(drift, fmt.Sprintf("%s: want [%s], found [%s]", name, strings.Join(want, ", "), strings.Join(got, ", ")))
+		}
+	}
+	if v, ok := readRegValue(registry.LOCAL_MACHINE, b.PolicyPath, "URLBlocklist"); ok {
+		drift = append(drift, "legacy URLBlocklist value present: "+v)
+	}
+	for name, want := range p.chromiumScalars(b) {
+		got, ok := readRegValue(registry.LOCAL_MACHINE, b.PolicyPath, name)
+		switch {
+		case want == nil && ok:
+			drift = append(drift, fmt.Sprintf("%s: want unset, found %s", name, got))
+		case want != nil && (!ok || got != fmt.Sprint(want)):
+			drift = append(drift, fmt.Sprintf("%s: want %v, found %s", name, want, onOff(ok, got, "(not set)")))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// --- Firefox ---
+
+// firefoxPoliciesPath is distribution\policies.json of the installed Firefox ("" if not installed)
+func firefoxPoliciesPath() string {
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)"} {
+		base := os.Getenv(env)
+		if base == "" {
+			continue
+		}
+		dir := filepath.Join(base, "Mozilla Firefox")
+		if fileExists(filepath.Join(dir, "firefox.exe")) {
+			return filepath.Join(dir, "distribution", "policies.json")
+		}
+	}
+	return ""
+}
+
+// firefoxMatchPatterns converts Chrome URL filters to WebExtension match patterns
+func firefoxMatchPatterns(patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		if p == "*" {
+			out = append(out, "<all_urls>")
+			continue
+		}
+		scheme, rest := "*", p
+		if i := strings.Index(p, "://"); i >= 0 {
+			scheme, rest = p[:i], p[i+3:]
+		}
+		host, path := rest, "/*"
+		if i := strings.Index(rest, "/"); i >= 0 {
+			host, path = rest[:i], rest[i:]
+			if !strings.HasSuffix(path, "*") {
+				path += "*"
+			}
+		}
+		if i := strings.Index(host, ":"); i >= 0 {
+			host = host[:i] // match patterns cannot express ports
+		}
+		if strings.HasPrefix(host, ".") {
+			out = append(out, scheme+"://"+host[1:]+path)
+			continue
+		}
+		out = append(out, scheme+"://"+host+path)
+		if host != "*" {
+			out = append(out, scheme+"://*."+host+path)
+		}
+	}
+	return out
+}
+
+// firefoxPolicies renders the managed top-level policies (nil = remove)
+func (p BrowserPolicy) firefoxPolicies() map[string]any {
+	values := map[string]any{}
+	for _, name := range firefoxManagedPolicies {
+		values[name] = nil
+	}
+
+	if block, allow := p.effectiveBlocklist(), p.urlAllowlist(); len(block) > 0 || len(allow) > 0 {
+		filter := map[string]any{"Block": firefoxMatchPatterns(block)}
+		if len(allow) > 0 {
+			filter["Exceptions"] = firefoxMatchPatterns(allow)
+		}
+		values["WebsiteFilter"] = filter
+	}
+
+	if len(p.FirefoxExtensions) > 0 || len(p.FirefoxBlocked) > 0 {
+		settings := map[string]any{}
+		for _, id := range p.FirefoxBlocked {
+			settings[id] = map[string]any{"installation_mode": "blocked"}
+		}
+		for _, e := range p.FirefoxExtensions {
+			settings[e.ID] = map[string]any{"installation_mode": "force_installed", "install_url": e.InstallURL}
+		}
+		values["ExtensionSettings"] = settings
+	}
+
+	if p.Homepage != "" {
+		values["Homepage"] = map[string]any{"URL": p.Homepage, "Locked": true, "StartPage": "homepage"}
+	}
+
+	switch p.DNSOverHTTPS {
+	case "off":
+		values["DNSOverHTTPS"] = map[string]any{"Enabled": false, "Locked": true}
+	case "automatic":
+		values["DNSOverHTTPS"] = map[string]any{"Enabled": true, "Fallback": true, "Locked": true}
+	case "secure":
+		values["DNSOverHTTPS"] = map[string]any{"Enabled": true, "ProviderURL": strings.Split(p.DoHTemplate, "{")[0], "Fallback": false, "Locked": true}
+	}
+
+	if p.DisableIncognito {
+		values["DisablePrivateBrowsing"] = true
+	}
+	return values
+}
+
+// readFirefoxPolicies returns the "policies" object of policies.json (empty if missing)
+func readFirefoxPolicies(path string) (map[string]any, error) {
+	doc := map[string]any{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return doc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	policies, _ := doc["policies"].(map[string]any)
+	if policies == nil {
+		policies = map[string]any{}
+	}
+	return policies, nil
+}
+
+func applyFirefoxPolicy(x *executor, path string, p BrowserPolicy) error {
+	policies, err := readFirefoxPolicies(path)
+	if err != nil {
+		return err
+	}
+	for name, v := range p.firefoxPolicies() {
+		if v == nil {
+			delete(policies, name)
+		} else {
+			policies[name] = v
+		}
+	}
+	data, err := json.MarshalIndent(map[string]any{"policies": policies}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return x.writeFile(path, data)
+}
+
+// jsonEqual compares values after a JSON round trip, so typed and decoded maps compare equal
+func jsonEqual(a, b any) bool {
+	var x, y any
+	da, _ := json.Marshal(a)
+	db, _ := json.Marshal(b)
+	json.Unmarshal(da, &x)
+	json.Unmarshal(db, &y)
+	return reflect.DeepEqual(x, y)
+}
+
+func firefoxDrift(path string, p BrowserPolicy) []string {
+	policies, err := readFirefoxPolicies(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var drift []string
+	for name, want := range p.firefoxPolicies() {
+		got, ok := policies[name]
+		switch {
+		case want == nil && ok:
+			drift = append(drift, name+": want unset, found a value")
+		case want != nil && (!ok || !jsonEqual(want, got)):
+			data, _ := json.Marshal(got)
+			drift = append(drift, fmt.Sprintf("%s: differs (found %s)", name, onOff(ok, string(data), "nothing")))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// --- Operations ---
+
+func (a *App) applyBrowserPolicy(x *executor, p BrowserPolicy) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to set browser policies."); !ok {
+		return msg
+	}
+	if problems := p.validate(); len(problems) > 0 {
+		return "❌ Error: Invalid browser policy - " + strings.Join(problems, "; ")
+	}
+
+	var applied []string
+	for _, b := range chromiumBrowsers {
+		if !p.targets(b.Name) {
+			continue
+		}
+		if err := applyChromiumPolicy(x, b, p); err != nil {
+			return fmt.Sprintf("❌ Error: %s policy failed - %v", b.Name, err)
+		}
+		applied = append(applied, b.Name)
+	}
+	if p.targets("firefox") {
+		if path := firefoxPoliciesPath(); path != "" {
+			if err := applyFirefoxPolicy(x, path, p); err != nil {
+				return "❌ Error: firefox policy failed - " + err.Error()
+			}
+			applied = append(applied, "firefox")
+		} else {
+			x.record("note", "Firefox is not installed; skipped")
+		}
+	}
+	return "✅ Success: Browser policy applied (" + listOrNone(applied) + "). Restart the browsers to pick it up."
+}
+
+// browserChangeScope lists everything applyBrowserPolicy can touch, for Rollback
+func browserChangeScope() changeScope {
+	var scope changeScope
+	for _, b := range chromiumBrowsers {
+		for _, name := range chromiumListPolicies {
+			scope.Registry = append(scope.Registry, regRef{registry.LOCAL_MACHINE, b.PolicyPath + `\` + name, "*"})
+		}
+		for _, name := range append(chromiumScalarPolicies, b.IncognitoName, "URLBlocklist") {
+			scope.Registry = append(scope.Registry, regRef{registry.LOCAL_MACHINE, b.PolicyPath, name})
+		}
+	}
+	if path := firefoxPoliciesPath(); path != "" {
+		scope.Files = []string{path}
+	}
+	return scope
+}
+
+// verifyBrowserPolicy reads every targeted browser's policy back and reports drift
+func verifyBrowserPolicy(p BrowserPolicy) []ComplianceControl {
+	var controls []ComplianceControl
+	add := func(browser string, drift []string) {
+		c := ComplianceControl{
+			Control:     browser + " policy",
+			Expected:    "matches browser_policy in config.json",
+			Actual:      "matches",
+			Pass:        len(drift) == 0,
+			Remediation: "Re-apply the browser policy",
+			FixOp:       "ApplyBrowserPolicy",
+		}
+		if !c.Pass {
+			c.Actual = strings.Join(drift, "; ")
+		}
+		controls = append(controls, c)
+	}
+	for _, b := range chromiumBrowsers {
+		if p.targets(b.Name) {
+			add(b.Title, chromiumDrift(b, p))
+		}
+	}
+	if p.targets("firefox") {
+		if path := firefoxPoliciesPath(); path != "" {
+			add("Firefox", firefoxDrift(path, p))
+		}
+	}
+	return controls
+}
+
+func browserPolicyParams(p BrowserPolicy) map[string]string {
+	return map[string]string{
+		"browsers":   strings.Join(p.Browsers, ","),
+		"allowlist":  strings.Join(p.Allowlist, ","),
+		"blocklist":  strings.Join(p.Blocklist, ","),
+		"extensions": strings.Join(p.ForceInstallExtensions, ","),
+		"homepage":   p.Homepage,
+		"doh":        p.DNSOverHTTPS,
+		"incognito":  onOff(p.DisableIncognito, "disabled", "allowed"),
+	}
+}
+
+// ApplyBrowserPolicy writes the policy for Chrome/Edge (registry) and Firefox (policies.json)
+func (a *App) ApplyBrowserPolicy(policy BrowserPolicy) (result string) {
+	defer beginAudit("ApplyBrowserPolicy", browserPolicyParams(policy), nil).snapshot(browserChangeScope()).finish(&result)
+	return a.applyBrowserPolicy(liveExecutor(), policy)
+}
+
+// ApplyConfiguredBrowserPolicy applies browser_policy from config.json
+func (a *App) ApplyConfiguredBrowserPolicy() string {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if config.BrowserPolicy == nil {
+		return "❌ Error: No browser_policy declared in config.json"
+	}
+	return a.ApplyBrowserPolicy(*config.BrowserPolicy)
+}
+
+// GetBrowserPolicy returns browser_policy from config.json (empty if not declared)
+func (a *App) GetBrowserPolicy() BrowserPolicy {
+	config, err := loadConfig("config.json")
+	if err != nil || config.BrowserPolicy == nil {
+		return BrowserPolicy{}
+	}
+	return *config.BrowserPolicy
+}
+
+// VerifyBrowserPolicy reads the browsers' policies back and compares them with the given policy
+func (a *App) VerifyBrowserPolicy(policy BrowserPolicy) []ComplianceControl {
+	return verifyBrowserPolicy(policy)
+}
@@ -0,0 +1,564 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"Triveni-Control-Center/powercfg"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// --- Services ---
+
+// ServiceSnapshot records whether a service existed and was running (its start type lives in the registry)
+type ServiceSnapshot struct {
+	Name    string `json:"name"`
+	Existed bool   `json:"existed"`
+	Running bool   `json:"running"`
+}
+
+func snapshotService(name string) (ServiceSnapshot, error) {
+	snap := ServiceSnapshot{Name: name}
+	m, err := mgr.Connect()
+	if err != nil {
+		return snap, err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return snap, nil
+	}
+	defer s.Close()
+	snap.Existed = true
+
+	status, err := s.Query()
+	if err != nil {
+		return snap, err
+	}
+	snap.Running = status.State == svc.Running || status.State == svc.StartPending
+	return snap, nil
+}
+
+func (s ServiceSnapshot) restore() error {
+	if !s.Existed {
+		return nil
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	service, err := m.OpenService(s.Name)
+	if err != nil {
+		return err
+	}
+	defer service.Close()
+
+	status, err := service.Query()
+	if err != nil {
+		return err
+	}
+	running := status.State == svc.Running || status.State == svc.StartPending
+	switch {
+	case s.Running && !running:
+		return service.Start()
+	case !s.Running && running:
+		_, err := service.Control(svc.Stop)
+		return err
+	}
+	return nil
+}
+
+// --- Firewall ---
+
+// FirewallRuleSnapshot is the enabled state of one rule; rules this tool manages keep their full definition
+type FirewallRuleSnapshot struct {
+	Name        string        `json:"name"`
+	DisplayName string        `json:"display_name"`
+	Enabled     bool          `json:"enabled"`
+	Rule        *FirewallRule `json:"rule,omitempty"`
+}
+
+// FirewallSnapshot captures the rules matched by a display group and/or display names
+type FirewallSnapshot struct {
+	Group        string                 `json:"group"`
+	DisplayNames []string               `json:"display_names"`
+	Rules        []FirewallRuleSnapshot `json:"rules"`
+}
+
+// selector is a PowerShell expression returning the rules this snapshot covers
+func (f FirewallSnapshot) selector() string {
+	var parts []string
+	if f.Group != "" {
+		parts = append(parts, "Get-NetFirewallRule -DisplayGroup "+psQuote(f.Group)+" -ErrorAction SilentlyContinue")
+	}
+	for _, n := range f.DisplayNames {
+		parts = append(parts, "Get-NetFirewallRule -DisplayName "+psQuote(n)+" -ErrorAction SilentlyContinue")
+	}
+	return "@(" + strings.Join(parts, "; ") + ")"
+}
+
+func snapshotFirewall(group string, displayNames []string) (*FirewallSnapshot, error) {
+	snap := &FirewallSnapshot{Group: group, DisplayNames: displayNames, Rules: []FirewallRuleSnapshot{}}
+	ps := snap.selector() + ` | ForEach-Object { [PSCustomObject]@{ name = $_.Name; display_name = $_.DisplayName; enabled = ($_.Enabled -eq 'True') } } | ConvertTo-Json -Compress`
+	out, err := runPowerShell(ps)
+	if err != nil {
+		return snap, fmt.Errorf("%v: %s", err, out)
+	}
+	if out == "" {
+		return snap, nil
+	}
+	if strings.HasPrefix(out, "{") {
+		out = "[" + out + "]"
+	}
+	if err := json.Unmarshal([]byte(out), &snap.Rules); err != nil || group != firewallManagedGroup {
+		return snap, err
+	}
+
+	defs, err := readFirewallRules(snap.selector())
+	if err != nil {
+		return snap, err
+	}
+	for i, r := range snap.Rules {
+		if def, ok := defs[r.Name]; ok {
+			snap.Rules[i].Rule = &def
+		}
+	}
+	return snap, nil
+}
+
+// restore re-applies each rule's enabled state and removes matching rules created since the snapshot
+func (f FirewallSnapshot) restore() error {
+	data, _ := json.Marshal(f.Rules)
+	ps := fmt.Sprintf(`
+		$snap = @(%s | ConvertFrom-Json)
+		foreach ($r in %s) {
+			$s = $snap | Where-Object { $_.name -eq $r.Name } | Select-Object -First 1
+			if (-not $s) {
+				Remove-NetFirewallRule -Name $r.Name -ErrorAction Stop
+			} elseif ($s.enabled) {
+				Set-NetFirewallRule -Name $r.Name -Enabled True -ErrorAction Stop
+			} else {
+				Set-NetFirewallRule -Name $r.Name -Enabled False -ErrorAction Stop
+			}
+		}
+	`, psQuote(string(data)), f.selector())
+	if out, err := runPowerShell(ps); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+
+	// Recreate managed rules that were removed or edited since the snapshot
+	var defs []FirewallRule
+	for _, r := range f.Rules {
+		if r.Rule != nil {
+			defs = append(defs, *r.Rule)
+		}
+	}
+	if len(defs) == 0 {
+		return nil
+	}
+	existing, err := readFirewallRules(f.selector())
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if _, err := upsertFirewallRule(liveExecutor(), def, existing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Power ---
+
+// powerSettingRef names a powercfg setting by subgroup and setting alias
+type powerSettingRef struct {
+	Subgroup string
+	Setting  string
+}
+
+// PowerSettingValue is the AC (and, where the PC has one, DC) value index of a setting on the captured scheme
+type PowerSettingValue struct {
+	Scheme   string  `json:"scheme,omitempty"` // "" = the active scheme
+	Subgroup string  `json:"subgroup"`
+	Setting  string  `json:"setting"`
+	AC       uint32  `json:"ac"`
+	DC       *uint32 `json:"dc,omitempty"` // nil in snapshots taken before DC values were managed
+}
+
+// PowerSnapshot is the active power scheme and selected values on it
+type PowerSnapshot struct {
+	ActiveScheme string              `json:"active_scheme"`
+	Settings     []PowerSettingValue `json:"settings"`
+}
+
+func runPowercfg(args ...string) (string, error) {
+	cmd := exec.Command("powercfg", args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("powercfg %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// snapshotPower captures the active scheme with settings on it, plus settings of other schemes
+// (by GUID) that an operation changes without them being active yet. On error the values
+// captured so far are still returned.
+func snapshotPower(settings []powerSettingRef, schemeSettings map[string][]powerSettingRef) (*PowerSnapshot, error) {
+	out, err := runPowercfg("/getactivescheme")
+	if err != nil {
+		return nil, err
+	}
+	guid := powercfg.ParseGUID(out)
+	if guid == "" {
+		return nil, fmt.Errorf("cannot parse active power scheme")
+	}
+
+	snap := &PowerSnapshot{ActiveScheme: guid}
+	capture := func(scheme, query string, s powerSettingRef) error {
+		// /qh also lists hidden settings, such as the processor parking values
+		out, err := runPowercfg("/qh", query, s.Subgroup, s.Setting)
+		if err != nil {
+			return err
+		}
+		v, ok := powercfg.Find(powercfg.ParseQuery(out), s.Subgroup, s.Setting)
+		if !ok {
+			return fmt.Errorf("cannot parse %s %s", s.Subgroup, s.Setting)
+		}
+		value := PowerSettingValue{Scheme: scheme, Subgroup: s.Subgroup, Setting: s.Setting, AC: v.AC}
+		if v.HasDC {
+			dc := v.DC
+			value.DC = &dc
+		}
+		snap.Settings = append(snap.Settings, value)
+		return nil
+	}
+	for _, s := range settings {
+		if err := capture("", guid, s); err != nil {
+			return snap, err
+		}
+	}
+	var errs []string
+	for _, scheme := range sortedKeys(schemeSettings) {
+		for _, s := range schemeSettings[scheme] {
+			if err := capture(scheme, scheme, s); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return snap, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return snap, nil
+}
+
+func (p PowerSnapshot) restore() error {
+	for _, s := range p.Settings {
+		scheme := s.Scheme
+		if scheme == "" {
+			scheme = p.ActiveScheme
+		}
+		if _, err := runPowercfg("/setacvalueindex", scheme, s.Subgroup, s.Setting, strconv.FormatUint(uint64(s.AC), 10)); err != nil {
+			return err
+		}
+		if s.DC != nil {
+			if _, err := runPowercfg("/setdcvalueindex", scheme, s.Subgroup, s.Setting, strconv.FormatUint(uint64(*s.DC), 10)); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := runPowercfg("/setactive", p.ActiveScheme)
+	return err
+}
+
+// --- Scheduled tasks ---
+
+// TaskSnapshot is a scheduled task's definition, or its absence
+type TaskSnapshot struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Existed bool   `json:"existed"`
+	XML     string `json:"xml,omitempty"`
+}
+
+func snapshotTask(name string) (TaskSnapshot, error) {
+	snap := TaskSnapshot{Name: name}
+	ps := `
+		$t = Get-ScheduledTask -TaskName ` + psQuote(name) + ` -ErrorAction SilentlyContinue | Select-Object -First 1
+		if ($t) {
+			[PSCustomObject]@{ path = $t.TaskPath; xml = (Export-ScheduledTask -TaskName $t.TaskName -TaskPath $t.TaskPath) } | ConvertTo-Json -Compress
+		}
+	`
+	out, err := runPowerShell(ps)
+	if err != nil {
+		return snap, fmt.Errorf("%v: %s", err, out)
+	}
+	if out == "" {
+		return snap, nil
+	}
+	var t struct {
+		Path string `json:"path"`
+		XML  string `json:"xml"`
+	}
+	if err := json.Unmarshal([]byte(out), &t); err != nil {
+		return snap, err
+	}
+	snap.Existed, snap.Path, snap.XML = true, t.Path, t.XML
+	return snap, nil
+}
+
+// restore stops and removes the task as it is now, then re-registers the captured definition
+func (t TaskSnapshot) restore() error {
+	ps := `
+		$t = Get-ScheduledTask -TaskName ` + psQuote(t.Name) + ` -ErrorAction SilentlyContinue
+		if ($t) {
+			$t | Stop-ScheduledTask -ErrorAction SilentlyContinue
+			$t | Unregister-ScheduledTask -Confirm:$false -ErrorAction Stop
+		}
+	`
+	if t.Existed {
+		ps += "Register-ScheduledTask -TaskName " + psQuote(t.Name) + " -TaskPath " + psQuote(t.Path) + " -Xml " + psQuote(t.XML) + " -Force -ErrorAction Stop | Out-Null\n"
+	}
+	if out, err := runPowerShell(ps); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+// --- Network ---
+
+// NetworkSnapshot is the IPv4 (and manual IPv6) configuration of one adapter
+type NetworkSnapshot struct {
+	Adapter     string   `json:"adapter"`
+	DHCP        bool     `json:"dhcp"`
+	Addresses   []string `json:"addresses"` // ip/prefix
+	Gateway     string   `json:"gateway"`
+	DNS         []string `json:"dns"`
+	DNSFromDHCP bool     `json:"dns_from_dhcp"`
+	// IPv6 is nil in snapshots taken before IPv6 was managed; restore leaves IPv6 alone then
+	IPv6 *NetworkSnapshotV6 `json:"ipv6,omitempty"`
+}
+
+// NetworkSnapshotV6 is the manually configured IPv6 state of an adapter
+type NetworkSnapshotV6 struct {
+	Addresses []string `json:"addresses"` // manual ip/prefix only
+	Gateway   string   `json:"gateway"`   // manual default route
+	DNS       []string `json:"dns"`
+}
+
+// networkAdapterSelector is a PowerShell expression for the adapter: by name, or the first one that is Up
+func networkAdapterSelector(adapter string) string {
+	if adapter != "" {
+		return "Get-NetAdapter -Name " + psQuote(adapter) + " -ErrorAction Stop"
+	}
+	return "Get-NetAdapter | Where-Object { $_.Status -eq 'Up' } | Select-Object -First 1"
+}
+
+// snapshotNetwork captures the adapter a network change targets ("" = first adapter that is Up)
+func snapshotNetwork(adapter string) (*NetworkSnapshot, error) {
+	ps := `
+		$a = ` + networkAdapterSelector(adapter) + `
+		if (-not $a) { throw 'No active network adapter found' }
+		$if = Get-NetIPInterface -InterfaceAlias $a.Name -AddressFamily IPv4
+		$ips = @(Get-NetIPAddress -InterfaceAlias $a.Name -AddressFamily IPv4 -ErrorAction SilentlyContinue | Where-Object { $_.PrefixOrigin -ne 'WellKnown' })
+		$gw = (Get-NetRoute -InterfaceAlias $a.Name -DestinationPrefix '0.0.0.0/0' -ErrorAction SilentlyContinue | Select-Object -First 1).NextHop
+		$dns = @((Get-DnsClientServerAddress -InterfaceAlias $a.Name -AddressFamily IPv4).ServerAddresses)
+		$ns = (Get-ItemProperty "HKLM:\SYSTEM\CurrentControlSet\Services\Tcpip\Parameters\Interfaces\$($a.InterfaceGuid)" -ErrorAction SilentlyContinue).NameServer
+		$ip6 = @(Get-NetIPAddress -InterfaceAlias $a.Name -AddressFamily IPv6 -PrefixOrigin Manual -ErrorAction SilentlyContinue)
+		$gw6 = (Get-NetRoute -InterfaceAlias $a.Name -DestinationPrefix '::/0' -Protocol NetMgmt -ErrorAction SilentlyContinue | Select-Object -First 1).NextHop
+		$ns6 = (Get-ItemProperty "HKLM:\SYSTEM\CurrentControlSet\Services\Tcpip6\Parameters\Interfaces\$($a.InterfaceGuid)" -ErrorAction SilentlyContinue).NameServer
+		[PSCustomObject]@{
+			adapter       = $a.Name
+			dhcp          = ($if.Dhcp -eq 'Enabled')
+			addresses     = @($ips | ForEach-Object { "$($_.IPAddress)/$($_.PrefixLength)" })
+			gateway       = [string]$gw
+			dns           = $dns
+			dns_from_dhcp = [string]::IsNullOrEmpty($ns)
+			ipv6          = [PSCustomObject]@{
+				addresses = @($ip6 | ForEach-Object { "$($_.IPAddress)/$($_.PrefixLength)" })
+				gateway   = [string]$gw6
+				dns       = @(([string]$ns6) -split '[ ,]' | Where-Object { $_ })
+			}
+		} | ConvertTo-Json -Compress -Depth 3
+	`
+	out, err := runPowerShell(ps)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+	var snap NetworkSnapshot
+	if err := json.Unmarshal([]byte(out), &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func (n NetworkSnapshot) restore() error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$alias = %s\n", psQuote(n.Adapter))
+	b.WriteString("Remove-NetRoute -InterfaceAlias $alias -DestinationPrefix '0.0.0.0/0' -Confirm:$false -ErrorAction SilentlyContinue\n")
+	b.WriteString("Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv4 -ErrorAction SilentlyContinue | Remove-NetIPAddress -Confirm:$false -ErrorAction SilentlyContinue\n")
+
+	if n.DHCP {
+		b.WriteString("Set-NetIPInterface -InterfaceAlias $alias -AddressFamily IPv4 -Dhcp Enabled -ErrorAction Stop\n")
+	} else {
+		b.WriteString("Set-NetIPInterface -InterfaceAlias $alias -AddressFamily IPv4 -Dhcp Disabled -ErrorAction Stop\n")
+		for i, addr := range n.Addresses {
+			ip, prefix, _ := strings.Cut(addr, "/")
+			fmt.Fprintf(&b, "New-NetIPAddress -InterfaceAlias $alias -IPAddress %s -PrefixLength %s", psQuote(ip), prefix)
+			if i == 0 && n.Gateway != "" {
+				fmt.Fprintf(&b, " -DefaultGateway %s", psQuote(n.Gateway))
+			}
+			b.WriteString(" -ErrorAction Stop | Out-Null\n")
+		}
+	}
+
+	dns := n.DNS
+	if n.IPv6 != nil {
+		b.WriteString("Remove-NetRoute -InterfaceAlias $alias -DestinationPrefix '::/0' -Protocol NetMgmt -Confirm:$false -ErrorAction SilentlyContinue\n")
+		b.WriteString("Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv6 -PrefixOrigin Manual -ErrorAction SilentlyContinue | Remove-NetIPAddress -Confirm:$false -ErrorAction SilentlyContinue\n")
+		for i, addr := range n.IPv6.Addresses {
+			ip, prefix, _ := strings.Cut(addr, "/")
+			fmt.Fprintf(&b, "New-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv6 -IPAddress %s -PrefixLength %s", psQuote(ip), prefix)
+			if i == 0 && n.IPv6.Gateway != "" {
+				fmt.Fprintf(&b, " -DefaultGateway %s", psQuote(n.IPv6.Gateway))
+			}
+			b.WriteString(" -ErrorAction Stop | Out-Null\n")
+		}
+		if !n.DNSFromDHCP {
+			dns = append(append([]string{}, dns...), n.IPv6.DNS...)
+		}
+	}
+
+	if n.DNSFromDHCP || len(dns) == 0 {
+		b.WriteString("Set-DnsClientServerAddress -InterfaceAlias $alias -ResetServerAddresses -ErrorAction Stop\n")
+	} else {
+		quoted := make([]string, len(dns))
+		for i, d := range dns {
+			quoted[i] = psQuote(d)
+		}
+		fmt.Fprintf(&b, "Set-DnsClientServerAddress -InterfaceAlias $alias -ServerAddresses (%s) -ErrorAction Stop\n", strings.Join(quoted, ","))
+	}
+
+	if out, err := runPowerShell(b.String()); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+// --- Files ---
+
+// FileSnapshot is the prior content of a small configuration file
+type FileSnapshot struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+	Data    []byte `json:"data"`
+}
+
+func snapshotFile(path string) (FileSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return FileSnapshot{Path: path}, nil
+	}
+	if err != nil {
+		return FileSnapshot{Path: path}, err
+	}
+	return FileSnapshot{Path: path, Existed: true, Data: data}, nil
+}
+
+func (f FileSnapshot) restore() error {
+	if !f.Existed {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(f.Path, f.Data, 0644)
+}
+
+// --- Time zone and computer name ---
+
+func snapshotTimeZone() (string, error) {
+	out, err := runPowerShell("(Get-TimeZone).Id")
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, out)
+	}
+	return out, nil
+}
+
+func restoreTimeZone(id string) error {
+	if out, err := runPowerShell("Set-TimeZone -Id " + psQuote(id) + " -ErrorAction Stop"); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+// pendingComputerName is the name Windows will use after the next reboot
+func pendingComputerName() string {
+	name, _ := readRegValue(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\ComputerName\ComputerName`, "ComputerName")
+	return name
+}
+
+func restoreComputerName(name string) error {
+	if name == "" || strings.EqualFold(pendingComputerName(), name) {
+		return nil
+	}
+	if out, err := runPowerShell("Rename-Computer -NewName " + psQuote(name) + " -Force -ErrorAction Stop"); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+// --- Wallpaper ---
+
+var (
+	moduser32                 = windows.NewLazySystemDLL("user32.dll")
+	procSystemParametersInfoW = moduser32.NewProc("SystemParametersInfoW")
+)
+
+const (
+	spiSetDeskWallpaper = 0x0014
+	spifUpdateIniFile   = 0x01
+	spifSendChange      = 0x02
+)
+
+// applyDesktopWallpaper tells Explorer to (re)load the wallpaper image at path
+func applyDesktopWallpaper(path string) error {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	r, _, err := procSystemParametersInfoW.Call(spiSetDeskWallpaper, 0, uintptr(unsafe.Pointer(p)), spifUpdateIniFile|spifSendChange)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// refreshWallpaperFromRegistry re-applies whatever the registry now says the wallpaper is
+func refreshWallpaperFromRegistry() error {
+	path, _ := readRegValue(registry.CURRENT_USER, `Control Panel\Desktop`, "Wallpaper")
+	return applyDesktopWallpaper(path)
+}
+
+// newChangeID returns a sortable, unique change identifier
+func newChangeID() string {
+	var b [3]byte
+	now := time.Now()
+	rand.Read(b[:])
+	return fmt.Sprintf("%s-%x", now.Format("20060102-150405"), b)
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"Triveni-Control-Center/powercfg"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// changeScope lists what an operation may touch, so it can be snapshotted before it runs
+type changeScope struct {
+	Registry       []regRef
+	Services       []string
+	FirewallGroup  string
+	FirewallRules  []string // display names
+	Power          []powerSettingRef
+	PowerScheme    bool                         // capture the active scheme even with no settings
+	SchemePower    map[string][]powerSettingRef // settings of a specific scheme (GUID), active or not
+	ScheduledTasks []string                     // task names
+	Network        bool
+	NetworkAdapter string // "" = first adapter that is Up
+	TimeZone       bool
+	ComputerName   bool
+	Wallpaper      bool     // re-apply the wallpaper after restoring its registry values
+	Files          []string // small config files (e.g. Firefox policies.json)
+}
+
+// Scopes of the operations that support Rollback. Installs are not covered.
+
+var usbChangeScope = changeScope{
+	Registry: []regRef{{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\USBSTOR`, "Start"}},
+}
+
+var rdpChangeScope = changeScope{
+	Registry:      []regRef{{registry.LOCAL_MACHINE, `System\CurrentControlSet\Control\Terminal Server`, "fDenyTSConnections"}},
+	FirewallGroup: "Remote Desktop*",
+}
+
+var domainWhitelistChangeScope = changeScope{
+	Registry: []regRef{
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Google\Chrome`, "URLBlocklist"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Google\Chrome\URLBlocklist`, "*"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Google\Chrome\URLAllowlist`, "*"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Edge`, "URLBlocklist"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Edge\URLBlocklist`, "*"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\Edge\URLAllowlist`, "*"},
+	},
+}
+
+var vncChangeScope = changeScope{
+	Registry: []regRef{{registry.LOCAL_MACHINE, `SOFTWARE\TightVNC\Server`, "*"}},
+	Services: []string{"tvnserver"},
+}
+
+// Set-Culture rewrites most of Control Panel\International, so all of it is captured
+var timeChangeScope = changeScope{
+	Registry: []regRef{
+		{registry.CURRENT_USER, `Control Panel\International`, "*"},
+		{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\W32Time\Parameters`, "NtpServer"},
+		{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\W32Time\Parameters`, "Type"},
+	},
+	TimeZone: true,
+}
+
+var thisPCChangeScope = changeScope{
+	Registry: []regRef{{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\HideDesktopIcons\NewStartPanel`, "{20D04FE0-3AEA-1069-A2D8-08002B30309D}"}},
+}
+
+var wallpaperChangeScope = changeScope{
+	Registry: []regRef{
+		{registry.CURRENT_USER, `Control Panel\Desktop`, "Wallpaper"},
+		{registry.CURRENT_USER, `Control Panel\Desktop`, "WallpaperStyle"},
+		{registry.CURRENT_USER, `Control Panel\Desktop`, "TileWallpaper"},
+	},
+	Wallpaper: true,
+}
+
+var sleepChangeScope = changeScope{
+	Power: []powerSettingRef{{"SUB_VIDEO", "VIDEOIDLE"}, {"SUB_SLEEP", "STANDBYIDLE"}},
+}
+
+// A rollback re-activates the previous plan and restores its values; a plan created in between is kept
+var powerPlanChangeScope = changeScope{
+	Power: []powerSettingRef{
+		{"SUB_VIDEO", "VIDEOIDLE"},
+		{"SUB_SLEEP", "STANDBYIDLE"},
+		{"SUB_SLEEP", "HIBERNATEIDLE"},
+		{"SUB_DISK", "DISKIDLE"},
+		{"SUB_BUTTONS", "LIDACTION"},
+	},
+	PowerScheme: true,
+}
+
+var pingChangeScope = changeScope{
+	FirewallRules: []string{"File and Printer Sharing (Echo Request - ICMPv4-In)", "Allow ICMPv4 Ping"},
+}
+
+var optimizerChangeScope = changeScope{
+	Registry: []regRef{
+		{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\SysMain`, "Start"},
+		{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, "EnableTransparency"},
+		{registry.CURRENT_USER, `Control Panel\Desktop\WindowMetrics`, "MinAnimate"},
+		{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\VisualEffects`, "VisualFXSetting"},
+		{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Explorer\Advanced`, "TaskbarAnimations"},
+	},
+	Services:    []string{"SysMain"},
+	PowerScheme: true,
+	// UltimateCPU activates High performance and sets its processor minimum to 100% (AC and DC)
+	SchemePower: map[string][]powerSettingRef{
+		powercfg.SchemeHighPerformance: {{"SUB_PROCESSOR", "0cc5b647-c1df-4637-891a-dec35c318583"}},
+	},
+	// InstallService registers a SYSTEM task that runs this script at logon
+	ScheduledTasks: []string{"TGS_Booster_Service"},
+	Files:          []string{`C:\ProgramData\TGS_System_Booster\TGS_Booster_SVC.ps1`},
+}
+
+var renameChangeScope = changeScope{ComputerName: true}
+
+var networkChangeScope = changeScope{Network: true}
+
+// changeRecord is the prior state captured before one operation, stored as changes/<id>.json
+type changeRecord struct {
+	ID           string            `json:"id"`
+	Time         string            `json:"time"`
+	User         string            `json:"user"`
+	Operation    string            `json:"operation"`
+	Params       map[string]string `json:"params"`
+	Registry     []RegSnapshot     `json:"registry,omitempty"`
+	Services     []ServiceSnapshot `json:"services,omitempty"`
+	Firewall     *FirewallSnapshot `json:"firewall,omitempty"`
+	Power        *PowerSnapshot    `json:"power,omitempty"`
+	Tasks        []TaskSnapshot    `json:"tasks,omitempty"`
+	Network      *NetworkSnapshot  `json:"network,omitempty"`
+	TimeZone     string            `json:"time_zone,omitempty"`
+	ComputerName string            `json:"computer_name,omitempty"`
+	Wallpaper    bool              `json:"wallpaper,omitempty"`
+	Files        []FileSnapshot    `json:"files,omitempty"`
+	Problems     []string          `json:"problems,omitempty"` // parts that could not be captured
+	Failed       bool              `json:"failed,omitempty"`   // the operation failed, possibly partway
+	RolledBack   string            `json:"rolled_back,omitempty"`
+	RolledBackBy string            `json:"rolled_back_by,omitempty"`
+}
+
+// ChangeSummary describes a recorded change for the UI
+type ChangeSummary struct {
+	ID         string            `json:"id"`
+	Time       string            `json:"time"`
+	User       string            `json:"user"`
+	Operation  string            `json:"operation"`
+	Params     map[string]string `json:"params"`
+	Items      []string          `json:"items"`
+	Problems   []string          `json:"problems"`
+	Failed     bool              `json:"failed"`
+	RolledBack string            `json:"rolled_back"`
+}
+
+func changesDir() string {
+	return filepath.Join(appDataDir(), "changes")
+}
+
+func changePath(id string) string {
+	return filepath.Join(changesDir(), id+".json")
+}
+
+// beginChange snapshots everything in scope. Failures are recorded, not fatal:
+// the operation still runs, it just cannot be fully rolled back.
+func beginChange(operation string, params map[string]string, scope changeScope) *changeRecord {
+	c := &changeRecord{
+		ID:        newChangeID(),
+		Time:      time.Now().Format(time.RFC3339),
+		User:      currentUserName(),
+		Operation: operation,
+		Params:    redactParams(params),
+		Wallpaper: scope.Wallpaper,
+	}
+	problem := func(what string, err error) {
+		c.Problems = append(c.Problems, what+": "+err.Error())
+	}
+
+	for _, ref := range scope.Registry {
+		snap, err := snapshotRegistry(ref)
+		if err != nil {
+			problem(ref.String(), err)
+			continue
+		}
+		c.Registry = append(c.Registry, snap)
+	}
+	for _, name := range scope.Services {
+		snap, err := snapshotService(name)
+		if err != nil {
+			problem("service "+name, err)
+			continue
+		}
+		c.Services = append(c.Services, snap)
+	}
+	if scope.FirewallGroup != "" || len(scope.FirewallRules) > 0 {
+		snap, err := snapshotFirewall(scope.FirewallGroup, scope.FirewallRules)
+		if err != nil {
+			problem("firewall", err)
+		} else {
+			c.Firewall = snap
+		}
+	}
+	if scope.PowerScheme || len(scope.Power) > 0 || len(scope.SchemePower) > 0 {
+		snap, err := snapshotPower(scope.Power, scope.SchemePower)
+		if err != nil {
+			problem("power", err)
+		}
+		c.Power = snap
+	}
+	for _, name := range scope.ScheduledTasks {
+		snap, err := snapshotTask(name)
+		if err != nil {
+			problem("scheduled task "+name, err)
+			continue
+		}
+		c.Tasks = append(c.Tasks, snap)
+	}
+	if scope.Network {
+		snap, err := snapshotNetwork(scope.NetworkAdapter)
+		if err != nil {
+			problem("network", err)
+		} else {
+			c.Network = snap
+		}
+	}
+	if scope.TimeZone {
+		tz, err := snapshotTimeZone()
+		if err != nil {
+			problem("time zone", err)
+		} else {
+			c.TimeZone = tz
+		}
+	}
+	if scope.ComputerName {
+		c.ComputerName = pendingComputerName()
+	}
+	for _, path := range scope.Files {
+		snap, err := snapshotFile(path)
+		if err != nil {
+			problem(path, err)
+			continue
+		}
+		c.Files = append(c.Files, snap)
+	}
+	return c
+}
+
+// scope reconstructs what a record covers, so a rollback can itself be snapshotted
+func (c *changeRecord) scope() changeScope {
+	var s changeScope
+	for _, r := range c.Registry {
+		root, err := regRootFromName(r.Root)
+		if err == nil {
+			s.Registry = append(s.Registry, regRef{root, r.Path, r.Name})
+		}
+	}
+	for _, svc := range c.Services {
+		s.Services = append(s.Services, svc.Name)
+	}
+	if c.Firewall != nil {
+		s.FirewallGroup, s.FirewallRules = c.Firewall.Group, c.Firewall.DisplayNames
+	}
+	if c.Power != nil {
+		s.PowerScheme = true
+		for _, p := range c.Power.Settings {
+			if p.Scheme == "" {
+				s.Power = append(s.Power, powerSettingRef{p.Subgroup, p.Setting})
+				continue
+			}
+			if s.SchemePower == nil {
+				s.SchemePower = map[string][]powerSettingRef{}
+			}
+			s.SchemePower[p.Scheme] = append(s.SchemePower[p.Scheme], powerSettingRef{p.Subgroup, p.Setting})
+		}
+	}
+	for _, t := range c.Tasks {
+		s.ScheduledTasks = append(s.ScheduledTasks, t.Name)
+	}
+	if c.Network != nil {
+		s.Network, s.NetworkAdapter = true, c.Network.Adapter
+	}
+	s.TimeZone = c.TimeZone != ""
+	s.ComputerName = c.ComputerName != ""
+	s.Wallpaper = c.Wallpaper
+	for _, f := range c.Files {
+		s.Files = append(s.Files, f.Path)
+	}
+	return s
+}
+
+// items lists the captured settings in plain words
+func (c *changeRecord) items() []string {
+	items := []string{}
+	for _, r := range c.Registry {
+		name := r.Name
+		if name == "*" {
+			name = "(all values)"
+		}
+		items = append(items, "registry "+r.Root+"\\"+r.Path+"\\"+name)
+	}
+	for _, s := range c.Services {
+		items = append(items, "service "+s.Name)
+	}
+	if c.Firewall != nil {
+		items = append(items, fmt.Sprintf("firewall (%d rules)", len(c.Firewall.Rules)))
+	}
+	if c.Power != nil {
+		items = append(items, "power scheme "+c.Power.ActiveScheme)
+	}
+	for _, t := range c.Tasks {
+		items = append(items, "scheduled task "+t.Name)
+	}
+	if c.Network != nil {
+		items = append(items, "network adapter "+c.Network.Adapter)
+	}
+	if c.TimeZone != "" {
+		items = append(items, "time zone "+c.TimeZone)
+	}
+	if c.ComputerName != "" {
+		items = append(items, "computer name "+c.ComputerName)
+	}
+	for _, f := range c.Files {
+		items = append(items, "file "+f.Path)
+	}
+	return items
+}
+
+func (c *changeRecord) save() error {
+	if err := os.MkdirAll(changesDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := changePath(c.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, changePath(c.ID))
+}
+
+func loadChange(id string) (*changeRecord, error) {
+	if id == "" || strings.ContainsAny(id, `\/.:`) {
+		return nil, fmt.Errorf("invalid change ID %q", id)
+	}
+	data, err := os.ReadFile(changePath(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("change %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c changeRecord
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// restore puts back everything captured, continuing past failures.
+// Network goes first so a broken static IP does not block the rest.
+func (c *changeRecord) restore() []string {
+	var failures []string
+	fail := func(what string, err error) {
+		if err != nil {
+			failures = append(failures, what+": "+err.Error())
+		}
+	}
+
+	if c.Network != nil {
+		fail("network", c.Network.restore())
+	}
+	for _, r := range c.Registry {
+		fail("registry "+r.Root+"\\"+r.Path, r.restore())
+	}
+	for _, s := range c.Services {
+		fail("service "+s.Name, s.restore())
+	}
+	if c.Firewall != nil {
+		fail("firewall", c.Firewall.restore())
+	}
+	if c.Power != nil {
+		fail("power", c.Power.restore())
+	}
+	// Tasks before files, so a restored-away task no longer runs the script being removed
+	for _, t := range c.Tasks {
+		fail("scheduled task "+t.Name, t.restore())
+	}
+	if c.TimeZone != "" {
+		fail("time zone", restoreTimeZone(c.TimeZone))
+	}
+	if c.ComputerName != "" {
+		fail("computer name", restoreComputerName(c.ComputerName))
+	}
+	for _, f := range c.Files {
+		fail("file "+f.Path, f.restore())
+	}
+	if c.Wallpaper {
+		fail("wallpaper", refreshWallpaperFromRegistry())
+	}
+	return failures
+}
+
+// Rollback restores the settings captured before the given change.
+// The rollback is itself recorded as a change, so it can be undone too.
+func (a *App) Rollback(changeID string) (result string) {
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to roll back changes."
+	}
+	c, err := loadChange(changeID)
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if c.RolledBack != "" {
+		return fmt.Sprintf("ℹ️ Change %s was already rolled back at %s (by %s).", c.ID, c.RolledBack, c.RolledBackBy)
+	}
+
+	params := map[string]string{"change_id": c.ID, "operation": c.Operation}
+	undo := beginChange("Rollback", params, c.scope())
+	defer beginAudit("Rollback", params, nil).withChange(undo).finish(&result)
+
+	failures := c.restore()
+	if len(failures) > 0 {
+		return fmt.Sprintf("❌ Error: Rollback of %s (%s) incomplete - %s", c.Operation, c.ID, strings.Join(failures, "; "))
+	}
+
+	c.RolledBack = time.Now().Format(time.RFC3339)
+	c.RolledBackBy = undo.ID
+	if err := c.save(); err != nil {
+		return "❌ Error: Settings restored but the change record could not be updated - " + err.Error()
+	}
+
+	note := ""
+	if c.ComputerName != "" {
+		note = " Restart to apply the computer name."
+	}
+	return fmt.Sprintf("✅ Success: Rolled back %s (%s).%s", c.Operation, c.ID, note)
+}
+
+// GetChanges lists recorded changes, newest first
+func (a *App) GetChanges() []ChangeSummary {
+	summaries := []ChangeSummary{}
+	files, err := filepath.Glob(filepath.Join(changesDir(), "*.json"))
+	if err != nil {
+		return summaries
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	for _, f := range files {
+		c, err := loadChange(strings.TrimSuffix(filepath.Base(f), ".json"))
+		if err != nil {
+			continue
+		}
+		summaries = append(summaries, ChangeSummary{
+			ID:         c.ID,
+			Time:       c.Time,
+			User:       c.User,
+			Operation:  c.Operation,
+			Params:     c.Params,
+			Items:      c.items(),
+			Problems:   c.Problems,
+			Failed:     c.Failed,
+			RolledBack: c.RolledBack,
+		})
+	}
+	return summaries
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modkernel32       = windows.NewLazySystemDLL("kernel32.dll")
+	procAttachConsole = modkernel32.NewProc("AttachConsole")
+)
+
+const attachParentProcess = ^uint32(0)
+
+// runCLI handles command line sub-commands (e.g. `Triveni-Control-Center.exe mirror D:\Mirror`).
+// It returns false when no sub-command was given so the GUI starts as usual.
+func runCLI(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "mirror":
+		attachParentConsole()
+		os.Exit(cliMirror(args[1:]))
+	case "serve":
+		attachParentConsole()
+		os.Exit(cliServe(args[1:]))
+	case "verify-audit":
+		attachParentConsole()
+		os.Exit(cliVerifyAudit())
+	case "agent":
+		// "agent service" is how the service control manager starts us; it has no console
+		if len(args) < 2 || args[1] != "service" {
+			attachParentConsole()
+		}
+		os.Exit(cliAgent(args[1:]))
+	case "compliance":
+		attachParentConsole()
+		os.Exit(cliCompliance())
+	case "plan":
+		attachParentConsole()
+		os.Exit(cliPlan(args[1:]))
+	case "diagnose":
+		attachParentConsole()
+		os.Exit(cliDiagnose())
+	case "help", "-h", "--help", "/?":
+		attachParentConsole()
+		printUsage()
+		os.Exit(0)
+	}
+	return false
+}
+
+// attachParentConsole lets the GUI-subsystem binary print to the console it was started from
+func attachParentConsole() {
+	r, _, _ := procAttachConsole.Call(uintptr(attachParentProcess))
+	if r == 0 {
+		return
+	}
+	if f, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+		os.Stdout = f
+		os.Stderr = f
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: Triveni-Control-Center.exe [command]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  mirror [dest]   Sync all catalog installers to dest (default: mirror_path from config.json)")
+	fmt.Println("  serve [dir]     Serve a mirror folder to peer PCs over HTTP (default: peer_serve_dir/mirror_path, port from peer_listen)")
+	fmt.Println("  verify-audit    Check the audit log hash chain and signed checkpoints for tampering")
+	fmt.Println("  compliance      Compare security settings with security_baseline in config.json")
+	fmt.Println("  diagnose        Check adapters, gateway, DNS, NAS (SMB), download hosts, proxy and clock skew")
+	fmt.Println("  agent install|remove|status|once")
+	fmt.Println("                  Manage the background service that re-applies the security baseline")
+	fmt.Println("  plan <operation> [key=value ...]")
+	fmt.Println("                  Dry run: list the steps an operation would take without changing anything")
+	fmt.Println("                  (e.g. plan InstallSoftware \"name=Google Chrome\", plan SetUSBBlock block=true)")
+	fmt.Println()
+	fmt.Println("Without a command the Control Center window is opened.")
+}
+
+func cliMirror(args []string) int {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading config:", err)
+		return 1
+	}
+
+	dest := config.MirrorPath
+	if len(args) > 0 {
+		dest = args[0]
+	}
+	if dest == "" {
+		fmt.Fprintln(os.Stderr, "Error: no destination given and no mirror_path configured")
+		return 2
+	}
+
+	fmt.Printf("Mirroring catalog to %s\n", dest)
+	report, err := mirrorCatalog(config, dest, nil, func(format string, args ...any) {
+		fmt.Printf(format, args...)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	fmt.Printf("\n%d copied, %d up to date, %d failed\n", len(report.Copied), len(report.Skipped), len(report.Failed))
+	if len(report.Failed) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func cliServe(args []string) int {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading config:", err)
+		return 1
+	}
+
+	dir, addr := config.peerServeOptions()
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if dir == "" {
+		fmt.Fprintln(os.Stderr, "Error: no folder given and no peer_serve_dir/mirror_path configured")
+		return 2
+	}
+
+	if err := peer.start(dir, addr); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	fmt.Printf("Serving %s on %s (Ctrl+C to stop)\n", dir, peer.addr)
+	select {}
+}
+
+func cliVerifyAudit() int {
+	report := verifyAuditLog()
+	fmt.Printf("Audit log: %s\n", auditLogPath())
+	fmt.Printf("%d records (%d before hash chaining), %d checkpoints, last seq %d\n",
+		report.Records, report.LegacyRecords, report.Checkpoints, report.LastSeq)
+	for _, p := range report.Problems {
+		fmt.Println("  PROBLEM:", p)
+	}
+	if !report.Valid {
+		fmt.Println("RESULT: audit trail has been tampered with or is damaged")
+		return 1
+	}
+	fmt.Println("RESULT: audit trail intact")
+	return 0
+}
+
+func cliPlan(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: plan <operation> [key=value ...]")
+		fmt.Fprintln(os.Stderr, "Operations:", strings.Join(plannableOperationNames(), ", "))
+		return 2
+	}
+
+	params := map[string]string{}
+	for _, arg := range args[1:] {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: parameter %q is not key=value\n", arg)
+			return 2
+		}
+		params[k] = v
+	}
+
+	plan := NewApp().planOperation(args[0], params)
+	fmt.Printf("Plan for %s %s\n", plan.Operation, formatAuditMap(plan.Params))
+	for i, step := range plan.Steps {
+		lines := strings.Split(step.Detail, "\n")
+		fmt.Printf("%3d. [%s] %s\n", i+1, step.Kind, lines[0])
+		for _, l := range lines[1:] {
+			fmt.Printf("       %s\n", l)
+		}
+	}
+	fmt.Println("Outcome:", plan.Outcome)
+	if !resultSucceeded(plan.Outcome) {
+		return 1
+	}
+	return 0
+}
+
+func cliCompliance() int {
+	report := NewApp().ComplianceScan()
+	if report.Error != "" {
+		fmt.Fprintln(os.Stderr, "Error:", report.Error)
+		return 2
+	}
+	for _, c := range report.Controls {
+		fmt.Printf("[%s] %s\n", onOff(c.Pass, "PASS", "FAIL"), c.Control)
+		fmt.Printf("       expected: %s\n       actual:   %s\n", c.Expected, c.Actual)
+		if !c.Pass {
+			fmt.Printf("       fix:      %s\n", c.Remediation)
+		}
+	}
+	fmt.Printf("%d passed, %d failed\n", report.Passed, report.Failed)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func cliDiagnose() int {
+	report := NewApp().RunNetworkDiagnostics()
+	for _, c := range report.Checks {
+		fmt.Printf("[%-4s] %-10s %s\n", strings.ToUpper(c.Status), c.Category, c.Target)
+		if c.Detail != "" {
+			fmt.Printf("       %s\n", c.Detail)
+		}
+	}
+	fmt.Println(report.Summary)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
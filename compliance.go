@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// SecurityBaseline is the declared security state (security_baseline in config.json).
+// A nil field is not part of the baseline and is not checked.
+type SecurityBaseline struct {
+	USBBlocked      *bool `json:"usb_blocked"`
+	RDPBlocked      *bool `json:"rdp_blocked"`
+	PingAllowed     *bool `json:"ping_allowed"`
+	VNCAuthRequired *bool `json:"vnc_auth_required"`
+	// BrowserAllowlist: omitted = not checked, [] = no URL filtering,
+	// otherwise Chrome and Edge allow exactly these patterns and block everything else.
+	BrowserAllowlist []string `json:"browser_allowlist"`
+}
+
+// ComplianceControl is the result of checking one baseline control
+type ComplianceControl struct {
+	Control     string            `json:"control"`
+	Expected    string            `json:"expected"`
+	Actual      string            `json:"actual"`
+	Pass        bool              `json:"pass"`
+	Remediation string            `json:"remediation"`
+	FixOp       string            `json:"fix_operation"` // App operation that restores the control
+	FixParams   map[string]string `json:"fix_params"`
+}
+
+// ComplianceReport is the outcome of a ComplianceScan
+type ComplianceReport struct {
+	Time     string              `json:"time"`
+	Host     string              `json:"host"`
+	Passed   int                 `json:"passed"`
+	Failed   int                 `json:"failed"`
+	Controls []ComplianceControl `json:"controls"`
+	Error    string              `json:"error"`
+}
+
+const (
+	rdpFirewallGroup = "Remote Desktop*"
+	pingRuleStandard = "File and Printer Sharing (Echo Request - ICMPv4-In)"
+	pingRuleCustom   = "Allow ICMPv4 Ping"
+)
+
+func onOff(v bool, on, off string) string {
+	if v {
+		return on
+	}
+	return off
+}
+
+func regValueOrNotSet(root registry.Key, path, name string) string {
+	if v, ok := readRegValue(root, path, name); ok {
+		return v
+	}
+	return "(not set)"
+}
+
+func checkUSB(blocked bool) ComplianceControl {
+	want := onOff(blocked, "4", "3")
+	actual := regValueOrNotSet(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\USBSTOR`, "Start")
+	return ComplianceControl{
+		Control:     "USB storage",
+		Expected:    onOff(blocked, "blocked (USBSTOR Start=4)", "allowed (USBSTOR Start=3)"),
+		Actual:      "USBSTOR Start=" + actual,
+		Pass:        actual == want,
+		Remediation: onOff(blocked, "Block USB storage", "Allow USB storage"),
+		FixOp:       "SetUSBBlock",
+		FixParams:   map[string]string{"block": fmt.Sprint(blocked)},
+	}
+}
+
+func checkRDP(blocked bool, fw *FirewallSnapshot) ComplianceControl {
+	deny := regValueOrNotSet(registry.LOCAL_MACHINE, `System\CurrentControlSet\Control\Terminal Server`, "fDenyTSConnections")
+	c := ComplianceControl{
+		Control:     "Remote Desktop",
+		Expected:    onOff(blocked, "fDenyTSConnections=1, Remote Desktop firewall rules disabled", "fDenyTSConnections=0, Remote Desktop firewall rules enabled"),
+		Remediation: onOff(blocked, "Disable RDP and its firewall rules", "Enable RDP and its firewall rules"),
+		FixOp:       "SetRDPBlock",
+		FixParams:   map[string]string{"block": fmt.Sprint(blocked)},
+	}
+	if fw == nil {
+		c.Actual = "fDenyTSConnections=" + deny + ", firewall rules unreadable"
+		return c
+	}
+
+	var enabled, total int
+	for _, r := range fw.Rules {
+		if r.DisplayName == pingRuleStandard || r.DisplayName == pingRuleCustom {
+			continue
+		}
+		total++
+		if r.Enabled {
+			enabled++
+		}
+	}
+	c.Actual = fmt.Sprintf("fDenyTSConnections=%s, %d of %d firewall rules enabled", deny, enabled, total)
+	if blocked {
+		c.Pass = deny == "1" && enabled == 0
+	} else {
+		c.Pass = deny == "0" && (total == 0 || enabled == total)
+	}
+	return c
+}
+
+func checkPing(allowed bool, fw *FirewallSnapshot) ComplianceControl {
+	c := ComplianceControl{
+		Control:     "ICMP ping",
+		Expected:    onOff(allowed, "echo request allowed", "echo request not allowed"),
+		Remediation: onOff(allowed, "Enable the ICMPv4 echo request firewall rule", "Disable the ICMPv4 echo request firewall rules"),
+	}
+	if fw == nil {
+		c.Actual = "firewall rules unreadable"
+		return c
+	}
+
+	var on []string
+	for _, r := range fw.Rules {
+		if (r.DisplayName == pingRuleStandard || r.DisplayName == pingRuleCustom) && r.Enabled {
+			on = append(on, r.DisplayName)
+		}
+	}
+	c.Actual = "enabled rules: " + listOrNone(on)
+	c.Pass = (len(on) > 0) == allowed
+	c.FixOp = onOff(allowed, "AllowPing", "BlockPing")
+	return c
+}
+
+func checkVNC(authRequired bool) ComplianceControl {
+	const path = `SOFTWARE\TightVNC\Server`
+	vncAuth := regValueOrNotSet(registry.LOCAL_MACHINE, path, "UseVncAuthentication")
+	ctlAuth := regValueOrNotSet(registry.LOCAL_MACHINE, path, "UseControlAuthentication")
+	_, hasPassword := readRegValue(registry.LOCAL_MACHINE, path, "Password")
+
+	c := ComplianceControl{
+		Control:  "TightVNC authentication",
+		Expected: onOff(authRequired, "password required (UseVncAuthentication=1, UseControlAuthentication=1)", "no password (UseVncAuthentication=0, UseControlAuthentication=0)"),
+		Actual:   fmt.Sprintf("UseVncAuthentication=%s, UseControlAuthentication=%s, password %s", vncAuth, ctlAuth, onOff(hasPassword, "set", "not set")),
+		FixOp:    "ApplyTightVNCConfig",
+	}
+	if authRequired {
+		c.Pass = vncAuth == "1" && ctlAuth == "1" && hasPassword
+		c.Remediation = "Set the VNC passwords and apply the TightVNC configuration"
+	} else {
+		c.Pass = vncAuth == "0" && ctlAuth == "0"
+		c.Remediation = "Apply the TightVNC configuration"
+	}
+	return c
+}
+
+// checkBrowserAllowlist compares Chrome and Edge URL filtering with the declared list
+func checkBrowserAllowlist(b chromiumBrowser, allowlist []string) ComplianceControl {
+	want := normalizePatterns(allowlist)
+	actual := normalizePatterns(readChromiumList(b, "URLAllowlist"))
+	blocklist := readChromiumList(b, "URLBlocklist")
+	legacy, hasLegacy := readRegValue(registry.LOCAL_MACHINE, b.PolicyPath, "URLBlocklist")
+	if hasLegacy {
+		blocklist = append(blocklist, legacy+" (legacy value)")
+	}
+
+	c := ComplianceControl{
+		Control:   b.Title + " URL allowlist",
+		Actual:    fmt.Sprintf("allowlist: %s; blocklist: %s", listOrNone(actual), listOrNone(blocklist)),
+		FixOp:     "SetDomainWhitelist",
+		FixParams: map[string]string{"domains": strings.Join(allowlist, ",")},
+	}
+	if len(want) == 0 {
+		c.Expected = "no URL filtering"
+		c.Pass = len(actual) == 0 && len(blocklist) == 0
+		c.Remediation = "Remove the " + b.Title + " URL filtering policies"
+		return c
+	}
+
+	c.Expected = "allow only: " + strings.Join(want, ", ")
+	c.Pass = !hasLegacy && strings.Join(blocklist, ",") == "*" && strings.Join(want, ",") == strings.Join(actual, ",")
+	c.Remediation = "Re-apply the " + b.Title + " URL allowlist"
+	return c
+}
+
+func normalizePatterns(patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		if f, err := parseURLFilter(p); err == nil {
+			out = append(out, f.Normalized)
+		} else if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// noPolicyDeclared is the error when config.json gives the scan and the agent nothing to check
+const noPolicyDeclared = "No security_baseline, browser_policy, device_control, vnc or firewall_rules declared in config.json"
+
+// declaresPolicy tells whether config.json declares anything the scan checks
+func (c *Config) declaresPolicy() bool {
+	return c.SecurityBaseline != nil || c.BrowserPolicy != nil || c.DeviceControl != nil || c.VNC != nil || c.FirewallRules != nil
+}
+
+// scanCompliance evaluates the baseline and every declared policy module against the current machine state
+func scanCompliance(config *Config) ComplianceReport {
+	report := ComplianceReport{
+		Time:     time.Now().Format(time.RFC3339),
+		Host:     hostName(),
+		Controls: []ComplianceControl{},
+	}
+	if !config.declaresPolicy() {
+		report.Error = noPolicyDeclared
+		return report
+	}
+	b, browser, devices := config.SecurityBaseline, config.BrowserPolicy, config.DeviceControl
+	if b == nil {
+		b = &SecurityBaseline{}
+	}
+
+	var fw *FirewallSnapshot
+	if b.RDPBlocked != nil || b.PingAllowed != nil {
+		snap, err := snapshotFirewall(rdpFirewallGroup, []string{pingRuleStandard, pingRuleCustom})
+		if err == nil {
+			fw = snap
+		}
+	}
+
+	if b.USBBlocked != nil {
+		report.Controls = append(report.Controls, checkUSB(*b.USBBlocked))
+	}
+	if b.RDPBlocked != nil {
+		report.Controls = append(report.Controls, checkRDP(*b.RDPBlocked, fw))
+	}
+	if b.BrowserAllowlist != nil {
+		for _, browser := range chromiumBrowsers {
+			report.Controls = append(report.Controls, checkBrowserAllowlist(browser, b.BrowserAllowlist))
+		}
+	}
+	if b.PingAllowed != nil {
+		report.Controls = append(report.Controls, checkPing(*b.PingAllowed, fw))
+	}
+	if b.VNCAuthRequired != nil {
+		report.Controls = append(report.Controls, checkVNC(*b.VNCAuthRequired))
+	}
+	if config.VNC != nil {
+		report.Controls = append(report.Controls, checkVNCAccess(*config.VNC))
+	}
+	if config.FirewallRules != nil {
+		report.Controls = append(report.Controls, verifyFirewallRules(config.FirewallRules)...)
+	}
+	if browser != nil {
+		report.Controls = append(report.Controls, verifyBrowserPolicy(*browser)...)
+	}
+	if devices != nil {
+		report.Controls = append(report.Controls, verifyDeviceControl(*devices)...)
+	}
+
+	for _, c := range report.Controls {
+		if c.Pass {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// ComplianceScan reads the current security settings and reports drift from the declared baseline
+func (a *App) ComplianceScan() ComplianceReport {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return ComplianceReport{Time: time.Now().Format(time.RFC3339), Host: hostName(), Controls: []ComplianceControl{}, Error: "Error loading config"}
+	}
+	return scanCompliance(config)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// credentialStore persists NAS credentials outside of config.json and process arguments
+type credentialStore interface {
+	Read(target string) (user, pass string, err error)
+	Write(target, user, pass string) error
+	Delete(target string) error
+}
+
+var errCredentialNotFound = errors.New("credential not found")
+
+// nasCredentials is the store used by the NAS session helpers
+var nasCredentials credentialStore = windowsCredentialStore{}
+
+// nasCredentialTarget is the Credential Manager target name for a NAS server
+func nasCredentialTarget(server string) string {
+	return "Triveni-Control-Center/NAS/" + server
+}
+
+// --- Windows Credential Manager ---
+
+var (
+	modadvapi32    = windows.NewLazySystemDLL("advapi32.dll")
+	procCredWriteW = modadvapi32.NewProc("CredWriteW")
+	procCredReadW  = modadvapi32.NewProc("CredReadW")
+	procCredDelete = modadvapi32.NewProc("CredDeleteW")
+	procCredFree   = modadvapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credTypeDomainPassword  = 2
+	credPersistLocalMachine = 2
+)
+
+// winCredential mirrors the Win32 CREDENTIALW structure
+type winCredential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// windowsCredentialStore stores generic credentials in the Windows Credential Manager
+type windowsCredentialStore struct{}
+
+func (windowsCredentialStore) Write(target, user, pass string) error {
+	targetPtr, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	userPtr, err := windows.UTF16PtrFromString(user)
+	if err != nil {
+		return err
+	}
+
+	// The blob is the UTF-16 password without a terminator
+	blob := windows.StringToUTF16(pass)
+	blob = blob[:len(blob)-1]
+
+	cred := winCredential{
+		Type:       credTypeGeneric,
+		TargetName: targetPtr,
+		Persist:    credPersistLocalMachine,
+		UserName:   userPtr,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlobSize = uint32(len(blob) * 2)
+		cred.CredentialBlob = (*byte)(unsafe.Pointer(&blob[0]))
+	}
+
+	r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (windowsCredentialStore) Read(target string) (string, string, error) {
+	targetPtr, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return "", "", err
+	}
+
+	var cred *winCredential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(targetPtr)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return "", "", errCredentialNotFound
+		}
+		return "", "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	user := windows.UTF16PtrToString(cred.UserName)
+	var pass string
+	if cred.CredentialBlobSize > 0 {
+		blob := unsafe.Slice((*uint16)(unsafe.Pointer(cred.CredentialBlob)), cred.CredentialBlobSize/2)
+		pass = windows.UTF16ToString(blob)
+	}
+	return user, pass, nil
+}
+
+func (windowsCredentialStore) Delete(target string) error {
+	return deleteCredential(target, credTypeGeneric)
+}
+
+// deleteCredential removes a Credential Manager entry of the given type
+func deleteCredential(target string, credType uint32) error {
+	targetPtr, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(targetPtr)), uintptr(credType), 0)
+	if r == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return errCredentialNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// --- In-memory fake ---
+
+type memoryCredential struct {
+	user string
+	pass string
+}
+
+// memoryCredentialStore keeps credentials for the lifetime of the process only.
+// Swap it in for nasCredentials to exercise the NAS session logic without
+// touching the real Credential Manager.
+type memoryCredentialStore struct {
+	mu    sync.Mutex
+	creds map[string]memoryCredential
+}
+
+func newMemoryCredentialStore() *memoryCredentialStore {
+	return &memoryCredentialStore{creds: map[string]memoryCredential{}}
+}
+
+func (m *memoryCredentialStore) Write(target, user, pass string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.creds[target] = memoryCredential{user: user, pass: pass}
+	return nil
+}
+
+func (m *memoryCredentialStore) Read(target string) (string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.creds[target]
+	if !ok {
+		return "", "", errCredentialNotFound
+	}
+	return c.user, c.pass, nil
+}
+
+func (m *memoryCredentialStore) Delete(target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.creds[target]; !ok {
+		return errCredentialNotFound
+	}
+	delete(m.creds, target)
+	return nil
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// DeviceControlPolicy is the removable-device policy (device_control in config.json).
+// Each class is "allow", "read_only" or "deny"; empty leaves the class unmanaged.
+type DeviceControlPolicy struct {
+	RemovableDisks string `json:"removable_disks"` // USB sticks, external drives, SD readers
+	CDDVD          string `json:"cd_dvd"`
+	Phones         string `json:"phones"` // MTP/PTP phones, cameras and media players (WPD)
+	Floppy         string `json:"floppy"`
+	Tape           string `json:"tape"`
+	// Approved company drives. Windows' storage-access policy has no per-device
+	// exceptions, so with approvals (removable_disks must be "deny") removable devices
+	// are blocked from installing instead, with the approved IDs layered over that deny.
+	ApprovedHardwareIDs []string `json:"approved_hardware_ids"`
+	ApprovedInstanceIDs []string `json:"approved_instance_ids"`
+}
+
+// ConnectedDevice is a storage-capable device currently attached to the machine
+type ConnectedDevice struct {
+	Class       string   `json:"class"`
+	Name        string   `json:"name"`
+	InstanceID  string   `json:"instance_id"`
+	HardwareIDs []string `json:"hardware_ids"`
+	Status      string   `json:"status"`
+	Removable   bool     `json:"removable"`
+	Approved    bool     `json:"approved"`
+	Access      string   `json:"access"` // effective policy for the device's class
+}
+
+type deviceClass struct {
+	Key      string   // device_control field name
+	Title    string   // shown in results
+	PnPClass []string // Get-PnpDevice classes reported by GetConnectedDevices
+	GUIDs    []string // RemovableStorageDevices subkeys
+	mode     func(p DeviceControlPolicy) string
+}
+
+const (
+	removableStoragePolicyPath = `SOFTWARE\Policies\Microsoft\Windows\RemovableStorageDevices`
+	deviceInstallPolicyPath    = `SOFTWARE\Policies\Microsoft\Windows\DeviceInstall\Restrictions`
+)
+
+var deviceClasses = []deviceClass{
+	{"removable_disks", "Removable disks", []string{"DiskDrive"}, []string{"{53f5630d-b6bf-11d0-94f2-00a0c91efb8b}"},
+		func(p DeviceControlPolicy) string { return p.RemovableDisks }},
+	{"cd_dvd", "CD/DVD", []string{"CDROM"}, []string{"{53f56308-b6bf-11d0-94f2-00a0c91efb8b}"},
+		func(p DeviceControlPolicy) string { return p.CDDVD }},
+	{"phones", "Phones and cameras (WPD)", []string{"WPD"}, []string{"{6AC27878-A6FA-4155-BA85-F98F491D4F33}", "{F33FDC04-D1AC-4E8E-9A30-19BBD4B108AE}"},
+		func(p DeviceControlPolicy) string { return p.Phones }},
+	{"floppy", "Floppy", []string{"FloppyDisk"}, []string{"{53f56311-b6bf-11d0-94f2-00a0c91efb8b}"},
+		func(p DeviceControlPolicy) string { return p.Floppy }},
+	{"tape", "Tape", []string{"TapeDrive"}, []string{"{53f5630b-b6bf-11d0-94f2-00a0c91efb8b}"},
+		func(p DeviceControlPolicy) string { return p.Tape }},
+}
+
+// Values under deviceInstallPolicyPath that the approval list manages
+var deviceInstallValues = []string{"DenyRemovableDevices", "AllowDenyLayered", "AllowDeviceIDs", "AllowInstanceIDs"}
+
+func (p DeviceControlPolicy) hasApprovals() bool {
+	return len(p.ApprovedHardwareIDs) > 0 || len(p.ApprovedInstanceIDs) > 0
+}
+
+// denyValues returns Deny_Read/Deny_Write for a class, or ok=false when the values should be removed
+func (p DeviceControlPolicy) denyValues(c deviceClass) (read, write uint32, ok bool) {
+	switch c.mode(p) {
+	case "deny":
+		// With approvals the class-wide deny would also block approved drives; the
+		// installation policy written by applyDeviceApprovals blocks the others
+		if c.Key == "removable_disks" && p.hasApprovals() {
+			return 0, 0, false
+		}
+		return 1, 1, true
+	case "read_only":
+		return 0, 1, true
+	}
+	return 0, 0, false
+}
+
+func validateDeviceID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("empty device ID")
+	}
+	for _, r := range id {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%q contains control characters", id)
+		}
+	}
+	if !strings.Contains(id, `\`) {
+		return fmt.Errorf(`%q is not a device ID (expected e.g. USBSTOR\Disk&Ven_SanDisk...)`, id)
+	}
+	return nil
+}
+
+// validate reports every problem with the policy, so the admin can fix them in one go
+func (p DeviceControlPolicy) validate() []string {
+	var problems []string
+	for _, c := range deviceClasses {
+		switch c.mode(p) {
+		case "", "allow", "read_only", "deny":
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown mode %q (use allow, read_only, deny)", c.Key, c.mode(p)))
+		}
+	}
+	for _, list := range [][]string{p.ApprovedHardwareIDs, p.ApprovedInstanceIDs} {
+		for _, id := range list {
+			if err := validateDeviceID(id); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	if p.hasApprovals() && p.RemovableDisks != "deny" {
+		problems = append(problems, "approved devices only apply when removable_disks is \"deny\"")
+	}
+	return problems
+}
+
+// applyDeviceApprovals blocks installation of removable devices and lets the approved IDs
+// win over that deny (AllowDenyLayered). Without approvals the installation policy is left
+// alone, so restrictions set by Group Policy or by hand are kept.
+func applyDeviceApprovals(x *executor, p DeviceControlPolicy) error {
+	if !p.hasApprovals() {
+		return nil
+	}
+	values := map[string]uint32{"DenyRemovableDevices": 1, "AllowDenyLayered": 1}
+	for _, l := range []struct {
+		name string
+		ids  []string
+	}{{"AllowDeviceIDs", p.ApprovedHardwareIDs}, {"AllowInstanceIDs", p.ApprovedInstanceIDs}} {
+		if len(l.ids) == 0 {
+			continue
+		}
+		values[l.name] = 1
+		if err := writeRegList(x, registry.LOCAL_MACHINE, deviceInstallPolicyPath+`\`+l.name, l.ids); err != nil {
+			return err
+		}
+	}
+	for _, name := range deviceInstallValues {
+		if v, ok := values[name]; ok {
+			if err := x.regSet(registry.LOCAL_MACHINE, deviceInstallPolicyPath, name, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *App) applyDeviceControl(x *executor, p DeviceControlPolicy) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to set device control policies."); !ok {
+		return msg
+	}
+	if problems := p.validate(); len(problems) > 0 {
+		return "❌ Error: Invalid device control policy - " + strings.Join(problems, "; ")
+	}
+
+	var applied []string
+	for _, c := range deviceClasses {
+		if c.mode(p) == "" {
+			continue
+		}
+		read, write, set := p.denyValues(c)
+		for _, guid := range c.GUIDs {
+			path := removableStoragePolicyPath + `\` + guid
+			var err error
+			if set {
+				err = x.regSet(registry.LOCAL_MACHINE, path, "Deny_Read", read)
+				if err == nil {
+					err = x.regSet(registry.LOCAL_MACHINE, path, "Deny_Write", write)
+				}
+			} else {
+				err = x.regDeleteKey(registry.LOCAL_MACHINE, path)
+			}
+			if err != nil {
+				return "❌ Error: Failed to set " + c.Title + " policy: " + err.Error()
+			}
+		}
+		applied = append(applied, c.Title+" "+c.mode(p))
+	}
+	if err := applyDeviceApprovals(x, p); err != nil {
+		return "❌ Error: Failed to set approved devices: " + err.Error()
+	}
+	if p.hasApprovals() {
+		applied = append(applied, fmt.Sprintf("%d approved device(s)", len(p.ApprovedHardwareIDs)+len(p.ApprovedInstanceIDs)))
+	}
+	return "✅ Success: Device control applied (" + listOrNone(applied) + "). Reconnect devices for it to take effect."
+}
+
+// deviceClassDrift compares a class's Deny_Read/Deny_Write with the policy
+func deviceClassDrift(c deviceClass, p DeviceControlPolicy) (actual string, pass bool) {
+	read, write, set := p.denyValues(c)
+	pass = true
+	var states []string
+	for _, guid := range c.GUIDs {
+		path := removableStoragePolicyPath + `\` + guid
+		r := regValueOrNotSet(registry.LOCAL_MACHINE, path, "Deny_Read")
+		w := regValueOrNotSet(registry.LOCAL_MACHINE, path, "Deny_Write")
+		states = append(states, fmt.Sprintf("Deny_Read=%s Deny_Write=%s", r, w))
+		if set {
+			pass = pass && r == fmt.Sprint(read) && w == fmt.Sprint(write)
+		} else {
+			pass = pass && (r == "(not set)" || r == "0") && (w == "(not set)" || w == "0")
+		}
+	}
+	return strings.Join(states, "; "), pass
+}
+
+// verifyDeviceControl reads the device policies back and reports drift
+func verifyDeviceControl(p DeviceControlPolicy) []ComplianceControl {
+	var controls []ComplianceControl
+	for _, c := range deviceClasses {
+		if c.mode(p) == "" {
+			continue
+		}
+		actual, pass := deviceClassDrift(c, p)
+		controls = append(controls, ComplianceControl{
+			Control:     c.Title,
+			Expected:    c.mode(p),
+			Actual:      actual,
+			Pass:        pass,
+			Remediation: "Re-apply the device control policy",
+			FixOp:       "ApplyDeviceControl",
+		})
+	}
+	if !p.hasApprovals() {
+		return controls
+	}
+
+	deny := regValueOrNotSet(registry.LOCAL_MACHINE, deviceInstallPolicyPath, "DenyRemovableDevices")
+	layered := regValueOrNotSet(registry.LOCAL_MACHINE, deviceInstallPolicyPath, "AllowDenyLayered")
+	pass := deny == "1" && layered == "1"
+	actual := []string{"DenyRemovableDevices=" + deny, "AllowDenyLayered=" + layered}
+	for _, l := range []struct {
+		name string
+		want []string
+	}{{"AllowDeviceIDs", p.ApprovedHardwareIDs}, {"AllowInstanceIDs", p.ApprovedInstanceIDs}} {
+		if len(l.want) == 0 {
+			continue
+		}
+		got := readRegList(registry.LOCAL_MACHINE, deviceInstallPolicyPath+`\`+l.name)
+		on := regValueOrNotSet(registry.LOCAL_MACHINE, deviceInstallPolicyPath, l.name)
+		pass = pass && on == "1" && strings.EqualFold(strings.Join(got, "|"), strings.Join(l.want, "|"))
+		actual = append(actual, fmt.Sprintf("%s=%s: %s", l.name, on, listOrNone(got)))
+	}
+	return append(controls, ComplianceControl{
+		Control:     "Approved removable devices",
+		Expected:    fmt.Sprintf("only approved devices install (hardware IDs: %s, instance IDs: %s)", listOrNone(p.ApprovedHardwareIDs), listOrNone(p.ApprovedInstanceIDs)),
+		Actual:      strings.Join(actual, ", "),
+		Pass:        pass,
+		Remediation: "Re-apply the device control policy",
+		FixOp:       "ApplyDeviceControl",
+	})
+}
+
+// connectedDevices lists present storage-capable devices with their hardware IDs
+func connectedDevices() ([]ConnectedDevice, error) {
+	var classes []string
+	for _, c := range deviceClasses {
+		classes = append(classes, c.PnPClass...)
+	}
+	ps := `Get-PnpDevice -PresentOnly -Class ` + strings.Join(classes, ",") + ` -ErrorAction SilentlyContinue | ForEach-Object {
+		$ids = (Get-PnpDeviceProperty -InstanceId $_.InstanceId -KeyName DEVPKEY_Device_HardwareIds -ErrorAction SilentlyContinue).Data
+		$removal = (Get-PnpDeviceProperty -InstanceId $_.InstanceId -KeyName DEVPKEY_Device_RemovalPolicy -ErrorAction SilentlyContinue).Data
+		[PSCustomObject]@{
+			class        = [string]$_.Class
+			name         = [string]$_.FriendlyName
+			instance_id  = [string]$_.InstanceId
+			hardware_ids = @($ids | ForEach-Object { [string]$_ })
+			status       = [string]$_.Status
+			removable    = ($_.Class -ne 'DiskDrive') -or ($removal -ge 2)
+		}
+	} | ConvertTo-Json -Compress -Depth 3`
+	out, err := runPowerShell(ps)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+	devices := []ConnectedDevice{}
+	if out == "" {
+		return devices, nil
+	}
+	if strings.HasPrefix(out, "{") {
+		out = "[" + out + "]"
+	}
+	return devices, json.Unmarshal([]byte(out), &devices)
+}
+
+// classify fills in whether the device is approved and what access its class gets
+func (p DeviceControlPolicy) classify(d *ConnectedDevice) {
+	for _, id := range p.ApprovedInstanceIDs {
+		if strings.EqualFold(id, d.InstanceID) {
+			d.Approved = true
+		}
+	}
+	for _, want := range p.ApprovedHardwareIDs {
+		for _, id := range d.HardwareIDs {
+			if strings.EqualFold(want, id) {
+				d.Approved = true
+			}
+		}
+	}
+
+	d.Access = "allow"
+	for _, c := range deviceClasses {
+		for _, class := range c.PnPClass {
+			if strings.EqualFold(class, d.Class) && c.mode(p) != "" {
+				d.Access = c.mode(p)
+			}
+		}
+	}
+	switch {
+	case !d.Removable:
+		d.Access = "allow (fixed disk)"
+	case d.Access == "deny" && d.Approved:
+		d.Access = "allow (approved)"
+	case d.Access == "deny" && d.Class == "DiskDrive" && p.hasApprovals():
+		d.Access = "deny (blocked from installing; already-installed drives keep working until removed)"
+	}
+}
+
+// deviceControlChangeScope lists everything applyDeviceControl can touch, for Rollback
+func deviceControlChangeScope() changeScope {
+	var scope changeScope
+	for _, c := range deviceClasses {
+		for _, guid := range c.GUIDs {
+			scope.Registry = append(scope.Registry, regRef{registry.LOCAL_MACHINE, removableStoragePolicyPath + `\` + guid, "*"})
+		}
+	}
+	for _, name := range deviceInstallValues {
+		scope.Registry = append(scope.Registry, regRef{registry.LOCAL_MACHINE, deviceInstallPolicyPath, name})
+	}
+	scope.Registry = append(scope.Registry,
+		regRef{registry.LOCAL_MACHINE, deviceInstallPolicyPath + `\AllowDeviceIDs`, "*"},
+		regRef{registry.LOCAL_MACHINE, deviceInstallPolicyPath + `\AllowInstanceIDs`, "*"},
+	)
+	return scope
+}
+
+func deviceControlParams(p DeviceControlPolicy) map[string]string {
+	params := map[string]string{
+		"approved_hardware_ids": strings.Join(p.ApprovedHardwareIDs, ","),
+		"approved_instance_ids": strings.Join(p.ApprovedInstanceIDs, ","),
+	}
+	for _, c := range deviceClasses {
+		params[c.Key] = c.mode(p)
+	}
+	return params
+}
+
+// ApplyDeviceControl writes the removable-storage and device-installation policies
+func (a *App) ApplyDeviceControl(policy DeviceControlPolicy) (result string) {
+	defer beginAudit("ApplyDeviceControl", deviceControlParams(policy), nil).snapshot(deviceControlChangeScope()).finish(&result)
+	return a.applyDeviceControl(liveExecutor(), policy)
+}
+
+// ApplyConfiguredDeviceControl applies device_control from config.json
+func (a *App) ApplyConfiguredDeviceControl() string {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if config.DeviceControl == nil {
+		return "❌ Error: No device_control declared in config.json"
+	}
+	return a.ApplyDeviceControl(*config.DeviceControl)
+}
+
+// GetDeviceControl returns device_control from config.json (empty if not declared)
+func (a *App) GetDeviceControl() DeviceControlPolicy {
+	config, err := loadConfig("config.json")
+	if err != nil || config.DeviceControl == nil {
+		return DeviceControlPolicy{}
+	}
+	return *config.DeviceControl
+}
+
+// GetConnectedDevices lists attached disks, optical drives and phones, marked against device_control
+func (a *App) GetConnectedDevices() []ConnectedDevice {
+	devices, err := connectedDevices()
+	if err != nil {
+		return []ConnectedDevice{}
+	}
+	policy := a.GetDeviceControl()
+	for i := range devices {
+		policy.classify(&devices[i])
+	}
+	return devices
+}
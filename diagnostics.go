@@ -0,0 +1,431 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// DiagnosticCheck is one result of the network diagnostics
+type DiagnosticCheck struct {
+	Category  string `json:"category"` // adapter, addressing, gateway, dns, smb, http, proxy, clock
+	Target    string `json:"target"`
+	Status    string `json:"status"` // pass, warn, fail, skip
+	Detail    string `json:"detail"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// ProxySettings is the proxy configuration the different Windows stacks see
+type ProxySettings struct {
+	Enabled       bool   `json:"enabled"` // Internet Options (WinINET) proxy for the current user
+	Server        string `json:"server"`
+	Bypass        string `json:"bypass"`
+	AutoConfigURL string `json:"auto_config_url"`
+	WinHTTP       string `json:"winhttp"`     // "netsh winhttp show proxy" (used by services)
+	Environment   string `json:"environment"` // HTTPS_PROXY / HTTP_PROXY, which this tool's downloads use
+}
+
+// NetworkDiagnostics is the full diagnostics report
+type NetworkDiagnostics struct {
+	Hostname         string            `json:"hostname"`
+	CheckedAt        string            `json:"checked_at"`
+	Adapters         []NetworkAdapter  `json:"adapters"`
+	Proxy            ProxySettings     `json:"proxy"`
+	ClockSkewSeconds *float64          `json:"clock_skew_seconds"` // local minus server time; nil if no server answered
+	Checks           []DiagnosticCheck `json:"checks"`
+	Failed           int               `json:"failed"`
+	Warnings         int               `json:"warnings"`
+	Summary          string            `json:"summary"`
+}
+
+const (
+	diagnosticTimeout   = 4 * time.Second
+	clockSkewWarnAfter  = 2 * time.Minute
+	clockSkewFailAfter  = 5 * time.Minute // Kerberos rejects tickets beyond this by default
+	diagnosticUserAgent = "Triveni-Control-Center-Diagnostics"
+)
+
+// activeAdapters returns the adapters that are Up and have an IPv4 address
+func activeAdapters(adapters []NetworkAdapter) []NetworkAdapter {
+	var out []NetworkAdapter
+	for _, ad := range adapters {
+		if strings.EqualFold(ad.Status, "Up") && len(ad.IPv4) > 0 {
+			out = append(out, ad)
+		}
+	}
+	return out
+}
+
+// adapterChecks covers link status and addressing (DHCP/static, APIPA, missing gateway or DNS)
+func adapterChecks(adapters []NetworkAdapter) []DiagnosticCheck {
+	var checks []DiagnosticCheck
+	up := 0
+	for _, ad := range adapters {
+		if !ad.Physical && !strings.EqualFold(ad.Status, "Up") {
+			continue
+		}
+		c := DiagnosticCheck{Category: "adapter", Target: ad.Name, Status: "pass",
+			Detail: fmt.Sprintf("%s, %s", ad.Status, ad.LinkSpeed)}
+		if !strings.EqualFold(ad.Status, "Up") {
+			c.Status, c.Detail = "skip", ad.Status+" (not connected)"
+		} else {
+			up++
+		}
+		checks = append(checks, c)
+	}
+	if up == 0 {
+		checks = append(checks, DiagnosticCheck{Category: "adapter", Target: "any", Status: "fail",
+			Detail: "No network adapter is connected"})
+	}
+
+	for _, ad := range activeAdapters(adapters) {
+		c := DiagnosticCheck{Category: "addressing", Target: ad.Name, Status: "pass"}
+		mode := onOff(ad.DHCP, "DHCP", "Static")
+		c.Detail = fmt.Sprintf("%s: %s, gateway %s, DNS %s", mode, strings.Join(ad.IPv4, ", "),
+			listOrNone(nonEmpty(ad.Gateway)), listOrNone(ad.DNS))
+		var problems []string
+		for _, ip := range ad.IPv4 {
+			if p, err := netip.ParsePrefix(ip); err == nil && p.Addr().IsLinkLocalUnicast() {
+				problems = append(problems, "self-assigned 169.254.x.x address (no DHCP server answered)")
+			}
+		}
+		if ad.Gateway == "" {
+			problems = append(problems, "no default gateway")
+		}
+		if len(ad.DNS) == 0 {
+			problems = append(problems, "no DNS servers")
+		}
+		if len(problems) > 0 {
+			c.Status = "warn"
+			c.Detail += " - " + strings.Join(problems, "; ")
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// pingCheck pings target twice
+func pingCheck(category, target string) DiagnosticCheck {
+	c := DiagnosticCheck{Category: category, Target: target}
+	start := time.Now()
+	cmd := exec.Command("ping", "-n", "2", "-w", "1000", target)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, _ := cmd.Output()
+	c.LatencyMs = time.Since(start).Milliseconds()
+	if strings.Contains(string(out), "TTL=") {
+		c.Status, c.Detail = "pass", "Replies received"
+	} else {
+		c.Status, c.Detail = "fail", "No reply"
+	}
+	return c
+}
+
+// dnsCheck resolves host with the system resolver
+func dnsCheck(host string) DiagnosticCheck {
+	c := DiagnosticCheck{Category: "dns", Target: host}
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticTimeout)
+	defer cancel()
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	c.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		c.Status, c.Detail = "fail", err.Error()
+		return c
+	}
+	c.Status, c.Detail = "pass", strings.Join(addrs, ", ")
+	return c
+}
+
+// tcpCheck opens a TCP connection to host:port
+func tcpCheck(category, host, port string) DiagnosticCheck {
+	c := DiagnosticCheck{Category: category, Target: net.JoinHostPort(host, port)}
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", c.Target, diagnosticTimeout)
+	c.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		c.Status, c.Detail = "fail", err.Error()
+		return c
+	}
+	conn.Close()
+	c.Status, c.Detail = "pass", "Port open"
+	return c
+}
+
+// httpCheck sends a HEAD request to rawURL the same way downloads are made.
+// It also returns the local clock's offset from the server's Date header, if there was one.
+func httpCheck(rawURL string) (DiagnosticCheck, *time.Duration) {
+	c := DiagnosticCheck{Category: "http", Target: rawURL}
+	client := &http.Client{Timeout: diagnosticTimeout * 2}
+	req, err := http.NewRequest(http.MethodHead, rawURL, nil)
+	if err != nil {
+		c.Status, c.Detail = "fail", err.Error()
+		return c, nil
+	}
+	req.Header.Set("User-Agent", diagnosticUserAgent)
+	start := time.Now()
+	resp, err := client.Do(req)
+	c.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		c.Status, c.Detail = "fail", err.Error()
+		return c, nil
+	}
+	resp.Body.Close()
+	var skew *time.Duration
+	if t, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		// The server stamped the response roughly half a round trip after the request left
+		d := start.Add(time.Since(start) / 2).Sub(t)
+		skew = &d
+	}
+	c.Detail = resp.Status
+	switch {
+	case resp.StatusCode >= 500:
+		c.Status = "warn"
+	case resp.StatusCode == http.StatusProxyAuthRequired:
+		c.Status, c.Detail = "fail", resp.Status+" (proxy requires authentication)"
+	default:
+		// 403/405 to a HEAD still proves the host is reachable
+		c.Status = "pass"
+	}
+	return c, skew
+}
+
+// diagnosticURLs returns one catalog DownloadUrl per scheme://host, sorted
+func diagnosticURLs(list []Software) []string {
+	byHost := map[string]string{}
+	for _, sw := range list {
+		u, err := url.Parse(sw.DownloadUrl)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		key := u.Scheme + "://" + strings.ToLower(u.Host)
+		if _, ok := byHost[key]; !ok {
+			byHost[key] = sw.DownloadUrl
+		}
+	}
+	urls := make([]string, 0, len(byHost))
+	for _, u := range byHost {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// diagnosticNasServers returns the distinct SMB servers of the NAS roots
+func diagnosticNasServers(config *Config) []string {
+	seen := map[string]bool{}
+	var servers []string
+	for _, root := range config.nasRoots() {
+		if !strings.HasPrefix(root, `\\`) {
+			continue // local mirror folder
+		}
+		s := nasServer(root)
+		if s != "" && !seen[strings.ToLower(s)] {
+			seen[strings.ToLower(s)] = true
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// readProxySettings collects the WinINET, WinHTTP and environment proxy settings
+func readProxySettings() ProxySettings {
+	var p ProxySettings
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Internet Settings`, registry.QUERY_VALUE)
+	if err == nil {
+		if v, _, err := k.GetIntegerValue("ProxyEnable"); err == nil {
+			p.Enabled = v == 1
+		}
+		p.Server, _, _ = k.GetStringValue("ProxyServer")
+		p.Bypass, _, _ = k.GetStringValue("ProxyOverride")
+		p.AutoConfigURL, _, _ = k.GetStringValue("AutoConfigURL")
+		k.Close()
+	}
+	cmd := exec.Command("netsh", "winhttp", "show", "proxy")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if out, err := cmd.Output(); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "Direct access") {
+				p.WinHTTP = "Direct access (no proxy server)"
+			} else if strings.HasPrefix(line, "Proxy Server(s)") {
+				_, v, _ := strings.Cut(line, ":")
+				p.WinHTTP = strings.TrimSpace(v)
+			}
+		}
+	}
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if v := os.Getenv(name); v != "" {
+			p.Environment = name + "=" + v
+			break
+		}
+	}
+	return p
+}
+
+// proxyCheck flags a system proxy that downloads made by this tool would not use
+func proxyCheck(p ProxySettings) DiagnosticCheck {
+	c := DiagnosticCheck{Category: "proxy", Target: "Internet Options", Status: "pass", Detail: "No proxy configured"}
+	switch {
+	case (p.Enabled || p.AutoConfigURL != "") && p.Environment == "":
+		c.Status = "warn"
+		c.Detail = fmt.Sprintf("Proxy %s is set in Internet Options, but internet downloads here only use HTTPS_PROXY/HTTP_PROXY",
+			strings.TrimSpace(onOff(p.Enabled, p.Server, "")+" "+onOff(p.AutoConfigURL != "", "(PAC "+p.AutoConfigURL+")", "")))
+	case p.Environment != "":
+		c.Detail = "Downloads use " + p.Environment
+	case p.Enabled:
+		c.Detail = "Proxy " + p.Server
+	}
+	return c
+}
+
+// clockCheck takes the median of the offsets measured against the servers' Date headers
+func clockCheck(skews []time.Duration) (DiagnosticCheck, *float64) {
+	c := DiagnosticCheck{Category: "clock", Target: "HTTP Date headers"}
+	if len(skews) == 0 {
+		c.Status, c.Detail = "skip", "No web server answered; clock skew unknown"
+		return c, nil
+	}
+	sort.Slice(skews, func(i, j int) bool { return skews[i] < skews[j] })
+	skew := skews[len(skews)/2]
+	seconds := skew.Seconds()
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	c.Detail = fmt.Sprintf("Local clock is %s %s", abs.Round(time.Second), onOff(skew >= 0, "ahead", "behind"))
+	switch {
+	case abs > clockSkewFailAfter:
+		c.Status = "fail"
+		c.Detail += " - NAS (Kerberos) and HTTPS logins will fail; fix the time source"
+	case abs > clockSkewWarnAfter:
+		c.Status = "warn"
+	default:
+		c.Status = "pass"
+	}
+	return c, &seconds
+}
+
+// runNetworkDiagnostics runs every check. Probes run concurrently and are reported in a fixed order.
+func runNetworkDiagnostics(config *Config) NetworkDiagnostics {
+	report := NetworkDiagnostics{Hostname: hostName(), CheckedAt: time.Now().Format(time.RFC3339)}
+	report.Adapters = listNetworkAdapters()
+	report.Checks = adapterChecks(report.Adapters)
+
+	var probes []func() DiagnosticCheck
+	var mu sync.Mutex
+	var skews []time.Duration
+
+	gateways := map[string]bool{}
+	for _, ad := range activeAdapters(report.Adapters) {
+		if ad.Gateway != "" && !gateways[ad.Gateway] {
+			gateways[ad.Gateway] = true
+			gw := ad.Gateway
+			probes = append(probes, func() DiagnosticCheck { return pingCheck("gateway", gw) })
+		}
+	}
+
+	hosts := map[string]bool{}
+	var lookups []string
+	addHost := func(h string) {
+		if _, err := netip.ParseAddr(h); err != nil && h != "" && !hosts[strings.ToLower(h)] {
+			hosts[strings.ToLower(h)] = true
+			lookups = append(lookups, h)
+		}
+	}
+	servers := diagnosticNasServers(config)
+	urls := diagnosticURLs(config.SoftwareList)
+	for _, s := range servers {
+		addHost(s)
+	}
+	for _, u := range urls {
+		parsed, _ := url.Parse(u)
+		addHost(parsed.Hostname())
+	}
+	for _, h := range lookups {
+		h := h
+		probes = append(probes, func() DiagnosticCheck { return dnsCheck(h) })
+	}
+	for _, s := range servers {
+		s := s
+		probes = append(probes, func() DiagnosticCheck { return tcpCheck("smb", s, "445") })
+	}
+	for _, u := range urls {
+		u := u
+		probes = append(probes, func() DiagnosticCheck {
+			c, skew := httpCheck(u)
+			if skew != nil {
+				mu.Lock()
+				skews = append(skews, *skew)
+				mu.Unlock()
+			}
+			return c
+		})
+	}
+
+	results := make([]DiagnosticCheck, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe func() DiagnosticCheck) {
+			defer wg.Done()
+			results[i] = probe()
+		}(i, probe)
+	}
+	wg.Wait()
+	report.Checks = append(report.Checks, results...)
+
+	if len(servers) == 0 {
+		report.Checks = append(report.Checks, DiagnosticCheck{Category: "smb", Target: "NAS", Status: "skip", Detail: "No UNC NAS roots configured"})
+	}
+	report.Proxy = readProxySettings()
+	report.Checks = append(report.Checks, proxyCheck(report.Proxy))
+	clock, skew := clockCheck(skews)
+	report.Checks = append(report.Checks, clock)
+	report.ClockSkewSeconds = skew
+
+	var failed []string
+	for _, c := range report.Checks {
+		switch c.Status {
+		case "fail":
+			report.Failed++
+			failed = append(failed, c.Category+" "+c.Target)
+		case "warn":
+			report.Warnings++
+		}
+	}
+	if report.Failed == 0 {
+		report.Summary = fmt.Sprintf("✅ All checks passed (%d warning(s))", report.Warnings)
+	} else {
+		report.Summary = fmt.Sprintf("❌ %d check(s) failed: %s", report.Failed, strings.Join(failed, ", "))
+	}
+	return report
+}
+
+// RunNetworkDiagnostics checks adapters, addressing, gateway, DNS, SMB to the NAS,
+// HTTP(S) to the catalog download hosts, proxy settings and clock skew
+func (a *App) RunNetworkDiagnostics() NetworkDiagnostics {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return NetworkDiagnostics{Summary: "Error loading config", CheckedAt: time.Now().Format(time.RFC3339)}
+	}
+	return runNetworkDiagnostics(config)
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// DomainJoinConfig holds the defaults for JoinDomain/JoinWorkgroup (domain_join in config.json)
+type DomainJoinConfig struct {
+	Domain    string `json:"domain"`  // e.g. corp.triveni.local
+	OUPath    string `json:"ou_path"` // e.g. OU=Workstations,OU=Ahmedabad,DC=corp,DC=triveni,DC=local
+	Workgroup string `json:"workgroup"`
+}
+
+// DomainStatus is this PC's current membership
+type DomainStatus struct {
+	Hostname     string `json:"hostname"`
+	PendingName  string `json:"pending_name"` // name after the next reboot
+	PartOfDomain bool   `json:"part_of_domain"`
+	Domain       string `json:"domain"` // the domain, or the workgroup when not joined
+	Workgroup    string `json:"workgroup"`
+}
+
+// DomainPreflight is the result of the checks run before joining a domain
+type DomainPreflight struct {
+	Domain      string            `json:"domain"`
+	Controllers []string          `json:"controllers"` // from the _ldap._tcp.dc._msdcs SRV records
+	Checks      []DiagnosticCheck `json:"checks"`
+	Ready       bool              `json:"ready"`
+	Error       string            `json:"error"`
+}
+
+// maxDCProbes caps how many domain controllers are tried for connectivity
+const maxDCProbes = 3
+
+// validateDomainName checks a DNS domain name (at least two labels)
+func validateDomainName(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("domain name is empty")
+	}
+	if len(domain) > 253 {
+		return fmt.Errorf("domain name is too long")
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("%q is not a DNS domain name (e.g. corp.example.local)", domain)
+	}
+	for _, l := range labels {
+		if l == "" || len(l) > 63 || strings.HasPrefix(l, "-") || strings.HasSuffix(l, "-") || strings.Trim(l, namingLiteralChars) != "" {
+			return fmt.Errorf("%q is not a valid DNS domain name", domain)
+		}
+	}
+	return nil
+}
+
+// splitDN splits a distinguished name on commas that are not escaped
+func splitDN(dn string) []string {
+	var parts []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range dn {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			b.WriteRune(r)
+			escaped = true
+		case r == ',':
+			parts = append(parts, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(parts, strings.TrimSpace(b.String()))
+}
+
+// validateOUPath checks that ou is an OU distinguished name inside domain
+func validateOUPath(ou, domain string) error {
+	if ou == "" {
+		return nil
+	}
+	var dcs []string
+	hasOU := false
+	for _, rdn := range splitDN(ou) {
+		k, v, ok := strings.Cut(rdn, "=")
+		k, v = strings.ToUpper(strings.TrimSpace(k)), strings.TrimSpace(v)
+		if !ok || v == "" {
+			return fmt.Errorf("OU path component %q is not key=value", rdn)
+		}
+		switch k {
+		case "OU", "CN":
+			if len(dcs) > 0 {
+				return fmt.Errorf("OU path: %s=%s must come before the DC= components", k, v)
+			}
+			hasOU = hasOU || k == "OU"
+		case "DC":
+			dcs = append(dcs, v)
+		default:
+			return fmt.Errorf("OU path component %q is not OU=, CN= or DC=", rdn)
+		}
+	}
+	if !hasOU {
+		return fmt.Errorf("OU path must name an organizational unit (OU=...)")
+	}
+	if !strings.EqualFold(strings.Join(dcs, "."), domain) {
+		return fmt.Errorf("OU path is in %s, not %s", strings.Join(dcs, "."), domain)
+	}
+	return nil
+}
+
+// domainUser qualifies a bare user name with the domain
+func domainUser(user, domain string) string {
+	if strings.ContainsAny(user, `\@`) {
+		return user
+	}
+	return domain + `\` + user
+}
+
+// srvCheck looks up an SRV record and returns the targets it names
+func srvCheck(service, name string) (DiagnosticCheck, []string) {
+	record := "_" + service + "._tcp." + name
+	c := DiagnosticCheck{Category: "dns", Target: record}
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticTimeout)
+	defer cancel()
+	_, srvs, err := net.DefaultResolver.LookupSRV(ctx, service, "tcp", name)
+	if err != nil || len(srvs) == 0 {
+		c.Status = "fail"
+		c.Detail = "No SRV record"
+		if err != nil {
+			c.Detail = err.Error()
+		}
+		return c, nil
+	}
+	var targets []string
+	for _, s := range srvs {
+		targets = append(targets, strings.TrimSuffix(s.Target, "."))
+	}
+	c.Status, c.Detail = "pass", strings.Join(targets, ", ")
+	return c, targets
+}
+
+// domainPreflight checks that the domain's controllers can be found in DNS and reached
+func domainPreflight(domain string) DomainPreflight {
+	p := DomainPreflight{Domain: domain, Controllers: []string{}, Checks: []DiagnosticCheck{}}
+	if err := validateDomainName(domain); err != nil {
+		p.Error = err.Error()
+		return p
+	}
+
+	ldap, dcs := srvCheck("ldap", "dc._msdcs."+domain)
+	if ldap.Status == "fail" {
+		var dns []string
+		for _, ad := range activeAdapters(listNetworkAdapters()) {
+			dns = append(dns, ad.DNS...)
+		}
+		ldap.Detail += fmt.Sprintf(" - this PC asks %s; its DNS servers must be (or forward to) the domain's DNS", listOrNone(dns))
+	}
+	kerberos, _ := srvCheck("kerberos", domain)
+	p.Checks = append(p.Checks, ldap, kerberos)
+	p.Controllers = append(p.Controllers, dcs...)
+
+	// Connect to the first controller that answers LDAP, then check Kerberos and SMB on it
+	var reached string
+	for i, dc := range dcs {
+		if i == maxDCProbes {
+			break
+		}
+		c := tcpCheck("dc", dc, "389")
+		p.Checks = append(p.Checks, c)
+		if c.Status == "pass" {
+			reached = dc
+			break
+		}
+	}
+	if reached != "" {
+		p.Checks = append(p.Checks, tcpCheck("dc", reached, "88"), tcpCheck("dc", reached, "445"))
+	} else if len(dcs) > 0 {
+		p.Checks = append(p.Checks, DiagnosticCheck{Category: "dc", Target: domain, Status: "fail",
+			Detail: "No domain controller answered on LDAP (389)"})
+	}
+
+	p.Ready = true
+	for _, c := range p.Checks {
+		if c.Status == "fail" {
+			p.Ready = false
+		}
+	}
+	return p
+}
+
+func (p DomainPreflight) failures() string {
+	var out []string
+	for _, c := range p.Checks {
+		if c.Status == "fail" {
+			out = append(out, c.Target+": "+c.Detail)
+		}
+	}
+	return strings.Join(out, "; ")
+}
+
+func readDomainStatus() DomainStatus {
+	s := DomainStatus{Hostname: hostName(), PendingName: pendingComputerName()}
+	out, err := runPowerShell(`$cs = Get-CimInstance Win32_ComputerSystem
+		[PSCustomObject]@{ part_of_domain = [bool]$cs.PartOfDomain; domain = [string]$cs.Domain; workgroup = [string]$cs.Workgroup } | ConvertTo-Json -Compress`)
+	if err == nil {
+		json.Unmarshal([]byte(out), &s)
+	}
+	return s
+}
+
+// domainAuditProbe records the membership before and after a join
+func domainAuditProbe() map[string]string {
+	s := readDomainStatus()
+	return map[string]string{
+		"part_of_domain": fmt.Sprint(s.PartOfDomain),
+		"domain":         s.Domain,
+		"hostname":       s.Hostname,
+		"pending_name":   s.PendingName,
+	}
+}
+
+// credentialCommand runs a PowerShell script that builds $cred from user and a password read
+// from standard input, so the password never appears on a command line, in a plan or in the audit log
+func credentialCommand(user, script string) *exec.Cmd {
+	ps := `$ErrorActionPreference = 'Stop'
+$secure = ConvertTo-SecureString ([Console]::In.ReadLine()) -AsPlainText -Force
+$cred = New-Object System.Management.Automation.PSCredential(` + psQuote(user) + `, $secure)
+` + script
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", ps)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd
+}
+
+// newNameProblem validates an optional new name for a join; "" means it may be used
+func newNameProblem(config *Config, newName string) string {
+	if newName == "" || strings.EqualFold(newName, hostName()) {
+		return ""
+	}
+	if problems := validateNetBIOSName(newName); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	return renameProblem(config, newName)
+}
+
+func (a *App) joinDomain(x *executor, domain, ouPath, newName, user, password string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to join a domain."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if config.DomainJoin != nil {
+		if domain == "" {
+			domain = config.DomainJoin.Domain
+		}
+		if ouPath == "" && strings.EqualFold(domain, config.DomainJoin.Domain) {
+			ouPath = config.DomainJoin.OUPath
+		}
+	}
+	if err := validateDomainName(domain); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if err := validateOUPath(ouPath, domain); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if msg := newNameProblem(config, newName); msg != "" {
+		return msg
+	}
+	if user == "" || (password == "" && !x.dryRun) {
+		return "❌ Error: A domain account and password with permission to join computers are required."
+	}
+
+	status := readDomainStatus()
+	if status.PartOfDomain && strings.EqualFold(status.Domain, domain) {
+		return "ℹ️ PC is already a member of " + domain
+	}
+	if status.PartOfDomain {
+		return fmt.Sprintf("❌ Error: PC is a member of %s. Join a workgroup and restart before joining %s.", status.Domain, domain)
+	}
+
+	preflight := domainPreflight(domain)
+	if !preflight.Ready {
+		return "❌ Error: Domain pre-flight checks failed - " + preflight.failures()
+	}
+	x.record("check", fmt.Sprintf("Domain controllers for %s: %s", domain, strings.Join(preflight.Controllers, ", ")))
+
+	params := fmt.Sprintf("$params = @{ DomainName = %s; Credential = $cred; Force = $true }\n", psQuote(domain))
+	if ouPath != "" {
+		params += fmt.Sprintf("$params.OUPath = %s\n", psQuote(ouPath))
+	}
+	renaming := newName != "" && !strings.EqualFold(newName, hostName())
+	if renaming {
+		// Joining with the new name renames and joins in a single reboot
+		params += fmt.Sprintf("$params.NewName = %s\n", psQuote(newName))
+	}
+	cmd := credentialCommand(domainUser(user, domain), params+"Add-Computer @params")
+	cmd.Stdin = strings.NewReader(password + "\n")
+	if output, err := x.output(cmd); err != nil {
+		return "❌ Error: Domain join failed: " + strings.TrimSpace(string(output))
+	}
+
+	msg := "✅ Success: Joined domain " + domain
+	if ouPath != "" {
+		msg += " in " + ouPath
+	}
+	if renaming {
+		msg += " as " + strings.ToUpper(newName) + ". Restart required." + recordRename(x, config, newName, hostName())
+		return msg
+	}
+	return msg + ". Restart required."
+}
+
+func (a *App) joinWorkgroup(x *executor, workgroup, newName, user, password string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to join a workgroup."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if workgroup == "" && config.DomainJoin != nil {
+		workgroup = config.DomainJoin.Workgroup
+	}
+	if problems := validateNetBIOSName(workgroup); len(problems) > 0 {
+		return "❌ Error: Workgroup - " + strings.Join(problems, "; ")
+	}
+	if msg := newNameProblem(config, newName); msg != "" {
+		return msg
+	}
+	renaming := newName != "" && !strings.EqualFold(newName, hostName())
+
+	status := readDomainStatus()
+	if !status.PartOfDomain && strings.EqualFold(status.Domain, workgroup) && !renaming {
+		return "ℹ️ PC is already in workgroup " + workgroup
+	}
+
+	var cmd *exec.Cmd
+	if status.PartOfDomain {
+		// Leaving a domain disables the computer account, which needs a domain login
+		if user == "" || (password == "" && !x.dryRun) {
+			return fmt.Sprintf("❌ Error: PC is a member of %s. A domain account and password are required to leave it.", status.Domain)
+		}
+		script := fmt.Sprintf("Remove-Computer -UnjoinDomainCredential $cred -WorkgroupName %s -Force", psQuote(workgroup))
+		if renaming {
+			script += fmt.Sprintf("\nRename-Computer -NewName %s -Force", psQuote(newName))
+		}
+		cmd = credentialCommand(domainUser(user, status.Domain), script)
+		cmd.Stdin = strings.NewReader(password + "\n")
+	} else {
+		script := fmt.Sprintf("$ErrorActionPreference = 'Stop'\nAdd-Computer -WorkgroupName %s -Force", psQuote(workgroup))
+		if renaming {
+			script += fmt.Sprintf("\nRename-Computer -NewName %s -Force", psQuote(newName))
+		}
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	}
+	if output, err := x.output(cmd); err != nil {
+		return "❌ Error: Workgroup join failed: " + strings.TrimSpace(string(output))
+	}
+
+	msg := "✅ Success: Joined workgroup " + workgroup
+	if renaming {
+		return msg + " as " + strings.ToUpper(newName) + ". Restart required." + recordRename(x, config, newName, hostName())
+	}
+	return msg + ". Restart required."
+}
+
+// JoinDomain joins the PC to an Active Directory domain, optionally into an OU and under a new name
+// (one reboot for both). The password is passed to PowerShell on standard input and never logged.
+func (a *App) JoinDomain(domain, ouPath, newName, user, password string) (result string) {
+	params := map[string]string{"domain": domain, "ou_path": ouPath, "new_name": newName, "user": user}
+	defer beginAudit("JoinDomain", params, domainAuditProbe).finish(&result)
+	return a.joinDomain(liveExecutor(), domain, ouPath, newName, user, password)
+}
+
+// JoinWorkgroup moves the PC to a workgroup, optionally renaming it. A domain login is only
+// needed when the PC is currently a domain member.
+func (a *App) JoinWorkgroup(workgroup, newName, user, password string) (result string) {
+	params := map[string]string{"workgroup": workgroup, "new_name": newName, "user": user}
+	defer beginAudit("JoinWorkgroup", params, domainAuditProbe).finish(&result)
+	return a.joinWorkgroup(liveExecutor(), workgroup, newName, user, password)
+}
+
+// CheckDomainJoin runs the pre-flight checks for a domain ("" = domain_join.domain from config.json)
+func (a *App) CheckDomainJoin(domain string) DomainPreflight {
+	if domain == "" {
+		if config, err := loadConfig("config.json"); err == nil && config.DomainJoin != nil {
+			domain = config.DomainJoin.Domain
+		}
+	}
+	return domainPreflight(domain)
+}
+
+// GetDomainStatus reports whether the PC is in a domain or workgroup
+func (a *App) GetDomainStatus() DomainStatus {
+	return readDomainStatus()
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	enforcementServiceName    = "TriveniPolicyAgent"
+	enforcementDisplayName    = "Triveni Policy Enforcement Agent"
+	defaultEnforcementMinutes = 15
+	// How long a stop request waits for a running pass to finish, so its audit records get written
+	enforcementStopGrace = 20 * time.Second
+)
+
+// EnforcementEvent is one remediation of a drifted control, kept in enforcement.jsonl
+type EnforcementEvent struct {
+	Time      string `json:"time"`
+	Control   string `json:"control"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+	Operation string `json:"operation"`
+	Result    string `json:"result"`
+	Success   bool   `json:"success"`
+}
+
+// EnforcementStatus describes the service and its most recent evaluation
+type EnforcementStatus struct {
+	Installed       bool   `json:"installed"`
+	State           string `json:"state"`
+	IntervalMinutes int    `json:"interval_minutes"`
+	LastCheck       string `json:"last_check"`
+	LastPassed      int    `json:"last_passed"`
+	LastFailed      int    `json:"last_failed"`
+	LastRemediated  int    `json:"last_remediated"`
+	LastError       string `json:"last_error"`
+}
+
+// enforcementCheck is written after every evaluation cycle
+type enforcementCheck struct {
+	Time       string `json:"time"`
+	Passed     int    `json:"passed"`
+	Failed     int    `json:"failed"`
+	Remediated int    `json:"remediated"`
+	Error      string `json:"error"`
+}
+
+var enforcementMu sync.Mutex
+
+func enforcementLogPath() string {
+	return filepath.Join(appDataDir(), "enforcement.jsonl")
+}
+
+func enforcementCheckPath() string {
+	return filepath.Join(appDataDir(), "enforcement-last.json")
+}
+
+func (c *Config) enforcementInterval() time.Duration {
+	if c != nil && c.EnforceIntervalMin > 0 {
+		return time.Duration(c.EnforceIntervalMin) * time.Minute
+	}
+	return defaultEnforcementMinutes * time.Minute
+}
+
+// enforcementOps re-apply a control through the normal App method, so every
+// remediation is also audited and can be rolled back
+var enforcementOps = map[string]func(a *App, p map[string]string) string{
+	"SetUSBBlock":         func(a *App, p map[string]string) string { return a.SetUSBBlock(p["block"] == "true") },
+	"SetRDPBlock":         func(a *App, p map[string]string) string { return a.SetRDPBlock(p["block"] == "true") },
+	"SetDomainWhitelist":  func(a *App, p map[string]string) string { return a.SetDomainWhitelist(p["domains"]) },
+	"AllowPing":           func(a *App, p map[string]string) string { return a.AllowPing() },
+	"BlockPing":           func(a *App, p map[string]string) string { return a.BlockPing() },
+	"ApplyTightVNCConfig": func(a *App, p map[string]string) string { return a.ApplyTightVNCConfig() },
+	"ApplyBrowserPolicy":  func(a *App, p map[string]string) string { return a.ApplyConfiguredBrowserPolicy() },
+	"ApplyDeviceControl":  func(a *App, p map[string]string) string { return a.ApplyConfiguredDeviceControl() },
+	"ApplyFirewallRules":  func(a *App, p map[string]string) string { return a.ApplyFirewallRules() },
+}
+
+// enforceBaseline evaluates the baseline once and re-applies every drifted control
+func enforceBaseline(a *App) enforcementCheck {
+	check := enforcementCheck{Time: time.Now().Format(time.RFC3339)}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		check.Error = "Error loading config: " + err.Error()
+		saveEnforcementCheck(check)
+		return check
+	}
+
+	report := scanCompliance(config)
+	check.Passed, check.Failed, check.Error = report.Passed, report.Failed, report.Error
+
+	for _, c := range report.Controls {
+		if c.Pass {
+			continue
+		}
+		event := EnforcementEvent{
+			Time:      time.Now().Format(time.RFC3339),
+			Control:   c.Control,
+			Expected:  c.Expected,
+			Actual:    c.Actual,
+			Operation: c.FixOp,
+		}
+		if fix, ok := enforcementOps[c.FixOp]; ok {
+			event.Result = fix(a, c.FixParams)
+			event.Success = resultSucceeded(event.Result)
+		} else {
+			event.Result = "No automatic remediation: " + c.Remediation
+		}
+		if event.Success {
+			check.Remediated++
+		}
+		if err := appendEnforcementEvent(event); err != nil {
+			fmt.Printf("Error writing enforcement log: %v\n", err)
+		}
+	}
+	saveEnforcementCheck(check)
+	return check
+}
+
+func appendEnforcementEvent(e EnforcementEvent) error {
+	enforcementMu.Lock()
+	defer enforcementMu.Unlock()
+
+	if err := os.MkdirAll(appDataDir(), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(enforcementLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func saveEnforcementCheck(c enforcementCheck) {
+	os.MkdirAll(appDataDir(), 0755)
+	data, _ := json.MarshalIndent(c, "", "  ")
+	os.WriteFile(enforcementCheckPath(), data, 0644)
+}
+
+// --- Windows service ---
+
+type policyAgent struct{}
+
+func (p *policyAgent) Execute(args []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	s <- svc.Status{State: svc.StartPending}
+
+	// Services start in System32; config.json lives next to the executable
+	if exe, err := os.Executable(); err == nil {
+		os.Chdir(filepath.Dir(exe))
+	}
+	a := NewApp()
+
+	s <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	// Passes run in the background so stop and interrogate requests are answered during one
+	done := make(chan struct{}, 1)
+	running := false
+	pass := func() {
+		running = true
+		go func() {
+			enforceBaseline(a)
+			done <- struct{}{}
+		}()
+	}
+
+	pass()
+	var next <-chan time.Time // nil while a pass is running
+
+	for {
+		select {
+		case <-done:
+			running = false
+			// Pick up interval changes made in config.json
+			config, _ := loadConfig("config.json")
+			next = time.After(config.enforcementInterval())
+		case <-next:
+			next = nil
+			pass()
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				s <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				s <- svc.Status{State: svc.StopPending, WaitHint: uint32(enforcementStopGrace / time.Millisecond)}
+				if running {
+					select {
+					case <-done:
+					case <-time.After(enforcementStopGrace):
+					}
+				}
+				return false, 0
+			}
+		}
+	}
+}
+
+// runEnforcementService is called by the service control manager via `agent service`
+func runEnforcementService() error {
+	return svc.Run(enforcementServiceName, &policyAgent{})
+}
+
+func installEnforcementService() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(enforcementServiceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", enforcementServiceName)
+	}
+
+	s, err := m.CreateService(enforcementServiceName, exe, mgr.Config{
+		DisplayName: enforcementDisplayName,
+		Description: "Periodically re-applies the Triveni security baseline (USB, RDP, browser policy, ping, VNC).",
+		StartType:   mgr.StartAutomatic,
+	}, "agent", "service")
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Start()
+}
+
+func removeEnforcementService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(enforcementServiceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", enforcementServiceName)
+	}
+	defer s.Close()
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		s.Control(svc.Stop)
+		for i := 0; i < 20; i++ {
+			time.Sleep(500 * time.Millisecond)
+			if status, err := s.Query(); err != nil || status.State == svc.Stopped {
+				break
+			}
+		}
+	}
+	return s.Delete()
+}
+
+func serviceStateName(st svc.State) string {
+	switch st {
+	case svc.Stopped:
+		return "Stopped"
+	case svc.StartPending:
+		return "Starting"
+	case svc.StopPending:
+		return "Stopping"
+	case svc.Running:
+		return "Running"
+	case svc.Paused:
+		return "Paused"
+	}
+	return fmt.Sprintf("State %d", st)
+}
+
+// --- App methods ---
+
+// InstallEnforcementService installs and starts the background policy agent
+func (a *App) InstallEnforcementService() (result string) {
+	defer beginAudit("InstallEnforcementService", nil, nil).finish(&result)
+
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to install the enforcement service."
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if !config.declaresPolicy() {
+		return "❌ Error: " + noPolicyDeclared
+	}
+	if err := installEnforcementService(); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return fmt.Sprintf("✅ Success: Policy agent installed; declared policies are enforced every %d minutes.", int(config.enforcementInterval().Minutes()))
+}
+
+// RemoveEnforcementService stops and removes the background policy agent
+func (a *App) RemoveEnforcementService() (result string) {
+	defer beginAudit("RemoveEnforcementService", nil, nil).finish(&result)
+
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to remove the enforcement service."
+	}
+	if err := removeEnforcementService(); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return "✅ Success: Policy agent removed."
+}
+
+// GetEnforcementStatus reports whether the agent is installed and its last evaluation
+func (a *App) GetEnforcementStatus() EnforcementStatus {
+	config, _ := loadConfig("config.json")
+	status := EnforcementStatus{State: "Not installed", IntervalMinutes: int(config.enforcementInterval().Minutes())}
+
+	if m, err := mgr.Connect(); err == nil {
+		if s, err := m.OpenService(enforcementServiceName); err == nil {
+			status.Installed = true
+			if st, err := s.Query(); err == nil {
+				status.State = serviceStateName(st.State)
+			}
+			s.Close()
+		}
+		m.Disconnect()
+	}
+
+	var check enforcementCheck
+	if data, err := os.ReadFile(enforcementCheckPath()); err == nil && json.Unmarshal(data, &check) == nil {
+		status.LastCheck, status.LastPassed, status.LastFailed = check.Time, check.Passed, check.Failed
+		status.LastRemediated, status.LastError = check.Remediated, check.Error
+	}
+	return status
+}
+
+// GetEnforcementLog returns the most recent remediations, newest first (limit <= 0 returns all)
+func (a *App) GetEnforcementLog(limit int) []EnforcementEvent {
+	events := []EnforcementEvent{}
+	f, err := os.Open(enforcementLogPath())
+	if err != nil {
+		return events
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e EnforcementEvent
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			events = append(events, e)
+		}
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
+
+// EnforceNow evaluates the baseline once in the foreground and fixes drifted controls
+func (a *App) EnforceNow() string {
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to enforce the baseline."
+	}
+	check := enforceBaseline(a)
+	if check.Error != "" {
+		return "❌ Error: " + check.Error
+	}
+	if check.Failed == 0 {
+		return fmt.Sprintf("✅ Success: All %d controls compliant.", check.Passed)
+	}
+	if check.Remediated < check.Failed {
+		return fmt.Sprintf("❌ Error: %d of %d drifted controls remediated; see the enforcement log.", check.Remediated, check.Failed)
+	}
+	return fmt.Sprintf("✅ Success: %d drifted controls re-applied.", check.Remediated)
+}
+
+func cliAgent(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: agent install|remove|status|once")
+		return 2
+	}
+	a := NewApp()
+	switch strings.ToLower(args[0]) {
+	case "service":
+		if err := runEnforcementService(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		return 0
+	case "install":
+		return printResult(a.InstallEnforcementService())
+	case "remove":
+		return printResult(a.RemoveEnforcementService())
+	case "once":
+		return printResult(a.EnforceNow())
+	case "status":
+		st := a.GetEnforcementStatus()
+		fmt.Printf("Service: %s (every %d min)\n", st.State, st.IntervalMinutes)
+		if st.LastCheck != "" {
+			fmt.Printf("Last check: %s - %d passed, %d failed, %d remediated\n", st.LastCheck, st.LastPassed, st.LastFailed, st.LastRemediated)
+		}
+		if st.LastError != "" {
+			fmt.Println("Last error:", st.LastError)
+		}
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, "Unknown agent command:", args[0])
+		return 2
+	}
+}
+
+// printResult prints an App result and turns it into an exit code, so scripts see failures
+func printResult(result string) int {
+	fmt.Println(result)
+	if !resultSucceeded(result) {
+		return 1
+	}
+	return 0
+}
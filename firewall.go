@@ -0,0 +1,678 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// FirewallRule is a Windows Firewall rule (firewall_rules in config.json).
+// Rules created here are put in firewallManagedGroup, so built-in rules are never touched.
+type FirewallRule struct {
+	Name            string   `json:"name"` // unique rule ID
+	DisplayName     string   `json:"display_name"`
+	Description     string   `json:"description"`
+	Direction       string   `json:"direction"` // inbound (default), outbound
+	Action          string   `json:"action"`    // allow (default), block
+	Protocol        string   `json:"protocol"`  // tcp, udp, icmpv4, icmpv6, any (default) or a protocol number
+	LocalPorts      []string `json:"local_ports"`
+	RemotePorts     []string `json:"remote_ports"`
+	IcmpType        string   `json:"icmp_type"`        // e.g. "8" for echo request
+	RemoteAddresses []string `json:"remote_addresses"` // IPs, CIDRs, ranges or keywords such as LocalSubnet
+	Profiles        []string `json:"profiles"`         // domain, private, public (default all)
+	Program         string   `json:"program"`
+	Enabled         *bool    `json:"enabled"` // default true
+	Group           string   `json:"group,omitempty"`
+}
+
+// FirewallApplyResult summarizes an idempotent apply
+type FirewallApplyResult struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Removed   []string `json:"removed"`
+	Unchanged []string `json:"unchanged"`
+}
+
+const firewallManagedGroup = "Triveni Control Center"
+
+var (
+	firewallNamePattern = regexp.MustCompile(`^[A-Za-z0-9 _.()-]{1,100}$`)
+	firewallProtocols   = map[string]string{"tcp": "TCP", "6": "TCP", "udp": "UDP", "17": "UDP", "icmpv4": "ICMPv4", "1": "ICMPv4", "icmpv6": "ICMPv6", "58": "ICMPv6", "any": "Any", "": "Any"}
+	firewallKeywords    = []string{"Any", "LocalSubnet", "DNS", "DHCP", "WINS", "DefaultGateway", "Internet", "Intranet", "IntranetRemoteAccess", "PlayToDevice"}
+)
+
+func canonicalProtocol(p string) (string, error) {
+	if v, ok := firewallProtocols[strings.ToLower(strings.TrimSpace(p))]; ok {
+		return v, nil
+	}
+	if n, err := strconv.Atoi(p); err == nil && n >= 0 && n <= 255 {
+		return strconv.Itoa(n), nil
+	}
+	return "", fmt.Errorf("unknown protocol %q (use tcp, udp, icmpv4, icmpv6, any or 0-255)", p)
+}
+
+func canonicalPort(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if strings.EqualFold(p, "any") {
+		return "Any", nil
+	}
+	check := func(s string) (int, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > 65535 {
+			return 0, fmt.Errorf("port %q must be 1-65535 or a first-last range", p)
+		}
+		return n, nil
+	}
+	if a, b, ok := strings.Cut(p, "-"); ok {
+		lo, err := check(a)
+		if err != nil {
+			return "", err
+		}
+		hi, err := check(b)
+		if err != nil {
+			return "", err
+		}
+		if hi < lo {
+			return "", fmt.Errorf("port range %q ends before it starts", p)
+		}
+		return fmt.Sprintf("%d-%d", lo, hi), nil
+	}
+	n, err := check(p)
+	return strconv.Itoa(n), err
+}
+
+// canonicalAddress renders an address the way Get-NetFirewallAddressFilter reports it:
+// IPv4 subnets as address/dotted-mask, single hosts without a prefix, keywords in their canonical case
+func canonicalAddress(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, k := range firewallKeywords {
+		if strings.EqualFold(s, k) {
+			return k, nil
+		}
+	}
+	if a, b, ok := strings.Cut(s, "-"); ok {
+		first, err1 := netip.ParseAddr(a)
+		last, err2 := netip.ParseAddr(b)
+		if err1 != nil || err2 != nil || first.Is4() != last.Is4() || last.Less(first) {
+			return "", fmt.Errorf("invalid address range %q", s)
+		}
+		return first.String() + "-" + last.String(), nil
+	}
+	if addr, mask, ok := strings.Cut(s, "/"); ok {
+		bits, err := strconv.Atoi(mask)
+		if err != nil {
+			m, perr := netip.ParseAddr(mask)
+			if perr != nil || !m.Is4() {
+				return "", fmt.Errorf("invalid subnet %q", s)
+			}
+			if bits, err = maskBits(m); err != nil {
+				return "", fmt.Errorf("invalid subnet %q: %v", s, err)
+			}
+		}
+		prefix, err := netip.ParsePrefix(addr + "/" + strconv.Itoa(bits))
+		if err != nil {
+			return "", fmt.Errorf("invalid subnet %q", s)
+		}
+		prefix = prefix.Masked()
+		if prefix.IsSingleIP() {
+			return prefix.Addr().String(), nil
+		}
+		if prefix.Addr().Is4() {
+			return prefix.Addr().String() + "/" + bitsToMask(prefix.Bits()), nil
+		}
+		return prefix.String(), nil
+	}
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q", s)
+	}
+	return ip.String(), nil
+}
+
+// maskBits converts a dotted IPv4 mask to a prefix length, rejecting non-contiguous masks
+func maskBits(m netip.Addr) (int, error) {
+	b := m.As4()
+	n := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	bits := 0
+	for n&0x80000000 != 0 {
+		bits++
+		n <<= 1
+	}
+	if n != 0 {
+		return 0, fmt.Errorf("mask %s is not contiguous", m)
+	}
+	return bits, nil
+}
+
+func bitsToMask(bits int) string {
+	n := ^uint32(0) << (32 - bits)
+	if bits == 0 {
+		n = 0
+	}
+	return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}).String()
+}
+
+func canonicalList(values []string, canon func(string) (string, error)) ([]string, []string) {
+	var out, problems []string
+	for _, v := range values {
+		c, err := canon(v)
+		if err != nil {
+			problems = append(problems, err.Error())
+			c = strings.TrimSpace(v)
+		}
+		if c != "Any" {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		out = []string{"Any"}
+	}
+	sort.Strings(out)
+	return out, problems
+}
+
+// normalized fills defaults and canonical forms, so a declared rule compares equal to its read-back
+func (r FirewallRule) normalized() (FirewallRule, []string) {
+	var problems []string
+	add := func(p []string) { problems = append(problems, p...) }
+
+	n := r
+	if n.DisplayName == "" {
+		n.DisplayName = n.Name
+	}
+	switch strings.ToLower(r.Direction) {
+	case "", "in", "inbound":
+		n.Direction = "Inbound"
+	case "out", "outbound":
+		n.Direction = "Outbound"
+	default:
+		problems = append(problems, fmt.Sprintf("direction %q (use inbound or outbound)", r.Direction))
+	}
+	switch strings.ToLower(r.Action) {
+	case "", "allow":
+		n.Action = "Allow"
+	case "block":
+		n.Action = "Block"
+	default:
+		problems = append(problems, fmt.Sprintf("action %q (use allow or block)", r.Action))
+	}
+	var err error
+	if n.Protocol, err = canonicalProtocol(r.Protocol); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	var p []string
+	n.LocalPorts, p = canonicalList(r.LocalPorts, canonicalPort)
+	add(p)
+	n.RemotePorts, p = canonicalList(r.RemotePorts, canonicalPort)
+	add(p)
+	n.RemoteAddresses, p = canonicalList(r.RemoteAddresses, canonicalAddress)
+	add(p)
+	if (n.LocalPorts[0] != "Any" || n.RemotePorts[0] != "Any") && n.Protocol != "TCP" && n.Protocol != "UDP" {
+		problems = append(problems, "ports require protocol tcp or udp")
+	}
+
+	n.IcmpType = strings.TrimSpace(r.IcmpType)
+	if n.IcmpType == "" || strings.EqualFold(n.IcmpType, "any") {
+		n.IcmpType = "Any"
+	} else if !strings.HasPrefix(n.Protocol, "ICMP") {
+		problems = append(problems, "icmp_type requires protocol icmpv4 or icmpv6")
+	}
+
+	profiles := map[string]bool{}
+	for _, pr := range r.Profiles {
+		switch v := strings.ToLower(strings.TrimSpace(pr)); v {
+		case "domain", "private", "public":
+			profiles[strings.ToUpper(v[:1])+v[1:]] = true
+		case "any", "":
+		default:
+			problems = append(problems, fmt.Sprintf("profile %q (use domain, private, public)", pr))
+		}
+	}
+	n.Profiles = []string{"Any"}
+	if len(profiles) > 0 && len(profiles) < 3 {
+		n.Profiles = nil
+		for pr := range profiles {
+			n.Profiles = append(n.Profiles, pr)
+		}
+		sort.Strings(n.Profiles)
+	}
+
+	if n.Program == "" {
+		n.Program = "Any"
+	}
+	if n.Enabled == nil {
+		on := true
+		n.Enabled = &on
+	}
+	return n, problems
+}
+
+// validateFirewallRules reports every problem in a declared list, so the admin can fix them in one go
+func validateFirewallRules(rules []FirewallRule) []string {
+	var problems []string
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if !firewallNamePattern.MatchString(r.Name) {
+			problems = append(problems, fmt.Sprintf("rule name %q must be 1-100 letters, digits, spaces or _.()-", r.Name))
+		}
+		if seen[strings.ToLower(r.Name)] {
+			problems = append(problems, fmt.Sprintf("rule name %q is declared twice", r.Name))
+		}
+		seen[strings.ToLower(r.Name)] = true
+		if _, p := r.normalized(); len(p) > 0 {
+			problems = append(problems, r.Name+": "+strings.Join(p, "; "))
+		}
+	}
+	return problems
+}
+
+// diffFirewallRule lists the fields where the read-back rule differs from the declared one
+func diffFirewallRule(want, got FirewallRule) []string {
+	var diff []string
+	field := func(name, w, g string) {
+		if !strings.EqualFold(w, g) {
+			diff = append(diff, fmt.Sprintf("%s: want %s, found %s", name, w, g))
+		}
+	}
+	field("display name", want.DisplayName, got.DisplayName)
+	field("direction", want.Direction, got.Direction)
+	field("action", want.Action, got.Action)
+	field("protocol", want.Protocol, got.Protocol)
+	field("local ports", strings.Join(want.LocalPorts, ","), strings.Join(got.LocalPorts, ","))
+	field("remote ports", strings.Join(want.RemotePorts, ","), strings.Join(got.RemotePorts, ","))
+	field("icmp type", want.IcmpType, got.IcmpType)
+	field("remote addresses", strings.Join(want.RemoteAddresses, ","), strings.Join(got.RemoteAddresses, ","))
+	field("profiles", strings.Join(want.Profiles, ","), strings.Join(got.Profiles, ","))
+	field("program", want.Program, got.Program)
+	field("enabled", fmt.Sprint(*want.Enabled), fmt.Sprint(got.Enabled != nil && *got.Enabled))
+	return diff
+}
+
+// readFirewallRules reads full rule definitions (with port, address and program filters) for a selector
+func readFirewallRules(selector string) (map[string]FirewallRule, error) {
+	ps := selector + ` | ForEach-Object {
+		$p = $_ | Get-NetFirewallPortFilter
+		$a = $_ | Get-NetFirewallAddressFilter
+		$app = $_ | Get-NetFirewallApplicationFilter
+		[PSCustomObject]@{
+			name             = [string]$_.Name
+			display_name     = [string]$_.DisplayName
+			description      = [string]$_.Description
+			group            = [string]$_.Group
+			direction        = [string]$_.Direction
+			action           = [string]$_.Action
+			protocol         = [string]$p.Protocol
+			local_ports      = @($p.LocalPort | ForEach-Object { [string]$_ })
+			remote_ports     = @($p.RemotePort | ForEach-Object { [string]$_ })
+			icmp_type        = [string](@($p.IcmpType) -join ',')
+			remote_addresses = @($a.RemoteAddress | ForEach-Object { [string]$_ })
+			profiles         = @(([string]$_.Profile) -split ',\s*')
+			program          = [string]$app.Program
+			enabled          = ($_.Enabled -eq 'True')
+		}
+	} | ConvertTo-Json -Compress -Depth 3`
+	out, err := runPowerShell(ps)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+	rules := map[string]FirewallRule{}
+	if out == "" {
+		return rules, nil
+	}
+	if strings.HasPrefix(out, "{") {
+		out = "[" + out + "]"
+	}
+	var list []FirewallRule
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, err
+	}
+	for _, r := range list {
+		n, _ := r.normalized()
+		n.Description, n.Group = r.Description, r.Group
+		rules[r.Name] = n
+	}
+	return rules, nil
+}
+
+func managedFirewallSelector() string {
+	return "Get-NetFirewallRule -Group " + psQuote(firewallManagedGroup) + " -ErrorAction SilentlyContinue"
+}
+
+func psList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = psQuote(v)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// newRuleScript is the New-NetFirewallRule call for a normalized rule
+func (r FirewallRule) newRuleScript() string {
+	args := []string{
+		"New-NetFirewallRule",
+		"-Name " + psQuote(r.Name),
+		"-DisplayName " + psQuote(r.DisplayName),
+		"-Group " + psQuote(firewallManagedGroup),
+	}
+	args = append(args, r.filterArgs()...)
+	if r.Description != "" {
+		args = append(args, "-Description "+psQuote(r.Description))
+	}
+	if r.Protocol == "TCP" || r.Protocol == "UDP" {
+		args = append(args, "-LocalPort "+psList(r.LocalPorts), "-RemotePort "+psList(r.RemotePorts))
+	}
+	if r.IcmpType != "Any" {
+		args = append(args, "-IcmpType "+psList(strings.Split(r.IcmpType, ",")))
+	}
+	if r.Program != "Any" {
+		args = append(args, "-Program "+psQuote(r.Program))
+	}
+	return strings.Join(append(args, "-ErrorAction Stop | Out-Null"), " ")
+}
+
+// setRuleScript is the Set-NetFirewallRule call that changes an existing rule in place.
+// Every filter is passed, "Any" included, so nothing from the old rule is left behind.
+func (r FirewallRule) setRuleScript() string {
+	args := []string{
+		"Set-NetFirewallRule",
+		"-Name " + psQuote(r.Name),
+		"-NewDisplayName " + psQuote(r.DisplayName),
+		"-Description " + psQuote(r.Description),
+	}
+	args = append(args, r.filterArgs()...)
+	ports := []string{"-LocalPort Any", "-RemotePort Any"}
+	if r.Protocol == "TCP" || r.Protocol == "UDP" {
+		ports = []string{"-LocalPort " + psList(r.LocalPorts), "-RemotePort " + psList(r.RemotePorts)}
+	}
+	args = append(args, ports...)
+	args = append(args, "-IcmpType "+psList(strings.Split(r.IcmpType, ",")), "-Program "+psQuote(r.Program))
+	return strings.Join(append(args, "-ErrorAction Stop"), " ")
+}
+
+// filterArgs are the parameters New- and Set-NetFirewallRule share
+func (r FirewallRule) filterArgs() []string {
+	return []string{
+		"-Direction " + r.Direction,
+		"-Action " + r.Action,
+		"-Protocol " + psQuote(r.Protocol),
+		"-RemoteAddress " + psList(r.RemoteAddresses),
+		"-Profile " + psList(r.Profiles),
+		"-Enabled " + onOff(*r.Enabled, "True", "False"),
+	}
+}
+
+func firewallCommand(script string) *exec.Cmd {
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd
+}
+
+// upsertFirewallRule creates the rule, or updates it in place when the read-back differs. Returns created/updated/unchanged.
+func upsertFirewallRule(x *executor, rule FirewallRule, existing map[string]FirewallRule) (string, error) {
+	want, problems := rule.normalized()
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%s: %s", rule.Name, strings.Join(problems, "; "))
+	}
+	script := want.newRuleScript()
+	state := "created"
+	if got, ok := existing[rule.Name]; ok {
+		if len(diffFirewallRule(want, got)) == 0 && got.Description == want.Description {
+			return "unchanged", nil
+		}
+		// Updating in place means a failed call leaves the old rule working, not no rule at all
+		script = want.setRuleScript()
+		state = "updated"
+	}
+	if out, err := x.output(firewallCommand(script)); err != nil {
+		return "", fmt.Errorf("%s: %v %s", rule.Name, err, strings.TrimSpace(string(out)))
+	}
+	return state, nil
+}
+
+func removeFirewallRule(x *executor, name string) error {
+	if out, err := x.output(firewallCommand("Remove-NetFirewallRule -Name " + psQuote(name) + " -ErrorAction Stop")); err != nil {
+		return fmt.Errorf("%s: %v %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// applyFirewallRules makes the managed group match the declared list: create, update, remove stale rules
+func applyFirewallRules(x *executor, rules []FirewallRule) (FirewallApplyResult, error) {
+	result := FirewallApplyResult{Created: []string{}, Updated: []string{}, Removed: []string{}, Unchanged: []string{}}
+	if problems := validateFirewallRules(rules); len(problems) > 0 {
+		return result, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	existing, err := readFirewallRules(managedFirewallSelector())
+	if err != nil {
+		return result, err
+	}
+
+	declared := map[string]bool{}
+	for _, r := range rules {
+		declared[r.Name] = true
+		state, err := upsertFirewallRule(x, r, existing)
+		if err != nil {
+			return result, err
+		}
+		switch state {
+		case "created":
+			result.Created = append(result.Created, r.Name)
+		case "updated":
+			result.Updated = append(result.Updated, r.Name)
+		default:
+			result.Unchanged = append(result.Unchanged, r.Name)
+		}
+	}
+
+	var stale []string
+	for name := range existing {
+		if !declared[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		if err := removeFirewallRule(x, name); err != nil {
+			return result, err
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	return result, nil
+}
+
+func (r FirewallApplyResult) String() string {
+	return fmt.Sprintf("created %d, updated %d, removed %d, unchanged %d", len(r.Created), len(r.Updated), len(r.Removed), len(r.Unchanged))
+}
+
+func (a *App) applyConfiguredFirewallRules(x *executor) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to modify firewall."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if config.FirewallRules == nil {
+		return "❌ Error: No firewall_rules declared in config.json"
+	}
+	result, err := applyFirewallRules(x, config.FirewallRules)
+	if err != nil {
+		return "❌ Error: Firewall rules not applied - " + err.Error()
+	}
+	return "✅ Success: Firewall rules applied (" + result.String() + ")"
+}
+
+// declaredFirewallRules refuses a one-off rule change while config.json declares the list:
+// the next ApplyFirewallRules or enforcement pass would undo it
+func declaredFirewallRules(name string) (string, bool) {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config", true
+	}
+	if config.FirewallRules != nil {
+		return "❌ Error: firewall_rules is declared in config.json, so managed rules are changed there. Edit " + name + " in firewall_rules and apply the firewall rules.", true
+	}
+	return "", false
+}
+
+func (a *App) setFirewallRule(x *executor, rule FirewallRule) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to modify firewall."); !ok {
+		return msg
+	}
+	if msg, declared := declaredFirewallRules(rule.Name); declared {
+		return msg
+	}
+	if problems := validateFirewallRules([]FirewallRule{rule}); len(problems) > 0 {
+		return "❌ Error: Invalid firewall rule - " + strings.Join(problems, "; ")
+	}
+	existing, err := readFirewallRules("Get-NetFirewallRule -Name " + psQuote(rule.Name) + " -ErrorAction SilentlyContinue")
+	if err != nil {
+		return "❌ Error: Failed to read firewall rules: " + err.Error()
+	}
+	if got, ok := existing[rule.Name]; ok && got.Group != firewallManagedGroup {
+		return "❌ Error: Rule " + rule.Name + " exists and is not managed by this tool"
+	}
+	state, err := upsertFirewallRule(x, rule, existing)
+	if err != nil {
+		return "❌ Error: Firewall rule not applied - " + err.Error()
+	}
+	return "✅ Success: Firewall rule " + rule.Name + " " + state
+}
+
+func (a *App) removeManagedFirewallRule(x *executor, name string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to modify firewall."); !ok {
+		return msg
+	}
+	if msg, declared := declaredFirewallRules(name); declared {
+		return msg
+	}
+	existing, err := readFirewallRules(managedFirewallSelector())
+	if err != nil {
+		return "❌ Error: Failed to read firewall rules: " + err.Error()
+	}
+	if _, ok := existing[name]; !ok {
+		return "❌ Error: No managed firewall rule named " + name
+	}
+	if err := removeFirewallRule(x, name); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return "✅ Success: Firewall rule " + name + " removed"
+}
+
+// verifyFirewallRules reads the managed rules back and reports drift from the declared list
+func verifyFirewallRules(rules []FirewallRule) []ComplianceControl {
+	existing, err := readFirewallRules(managedFirewallSelector())
+	if err != nil {
+		return []ComplianceControl{{
+			Control: "Firewall rules", Expected: "declared firewall_rules", Actual: "unreadable: " + err.Error(),
+			Remediation: "Re-apply the firewall rules", FixOp: "ApplyFirewallRules",
+		}}
+	}
+	var controls []ComplianceControl
+	declared := map[string]bool{}
+	for _, r := range rules {
+		declared[r.Name] = true
+		want, _ := r.normalized()
+		c := ComplianceControl{
+			Control:     "Firewall rule " + r.Name,
+			Expected:    firewallRuleSummary(want),
+			Remediation: "Re-apply the firewall rules",
+			FixOp:       "ApplyFirewallRules",
+		}
+		if got, ok := existing[r.Name]; !ok {
+			c.Actual = "missing"
+		} else if diff := diffFirewallRule(want, got); len(diff) > 0 {
+			c.Actual = strings.Join(diff, "; ")
+		} else {
+			c.Actual, c.Pass = "matches", true
+		}
+		controls = append(controls, c)
+	}
+
+	var stale []string
+	for name := range existing {
+		if !declared[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return append(controls, ComplianceControl{
+		Control:     "Undeclared managed firewall rules",
+		Expected:    "none",
+		Actual:      listOrNone(stale),
+		Pass:        len(stale) == 0,
+		Remediation: "Re-apply the firewall rules",
+		FixOp:       "ApplyFirewallRules",
+	})
+}
+
+func firewallRuleSummary(r FirewallRule) string {
+	s := fmt.Sprintf("%s %s %s", r.Action, r.Direction, r.Protocol)
+	if r.Protocol == "TCP" || r.Protocol == "UDP" {
+		s += " local " + strings.Join(r.LocalPorts, ",")
+	}
+	s += " from " + strings.Join(r.RemoteAddresses, ",") + ", profiles " + strings.Join(r.Profiles, ",")
+	if !*r.Enabled {
+		s += " (disabled)"
+	}
+	return s
+}
+
+var firewallChangeScope = changeScope{FirewallGroup: firewallManagedGroup}
+
+// ApplyFirewallRules makes the tool's firewall rules match firewall_rules in config.json
+func (a *App) ApplyFirewallRules() (result string) {
+	defer beginAudit("ApplyFirewallRules", nil, nil).snapshot(firewallChangeScope).finish(&result)
+	return a.applyConfiguredFirewallRules(liveExecutor())
+}
+
+// SetFirewallRule creates a managed rule, or updates it when it differs. Refused when
+// config.json declares firewall_rules, which the managed group is kept in line with.
+func (a *App) SetFirewallRule(rule FirewallRule) (result string) {
+	params := map[string]string{"name": rule.Name}
+	if n, problems := rule.normalized(); len(problems) == 0 {
+		params["rule"] = firewallRuleSummary(n)
+	}
+	defer beginAudit("SetFirewallRule", params, nil).snapshot(firewallChangeScope).finish(&result)
+	return a.setFirewallRule(liveExecutor(), rule)
+}
+
+// RemoveFirewallRule removes a rule created by this tool
+func (a *App) RemoveFirewallRule(name string) (result string) {
+	defer beginAudit("RemoveFirewallRule", map[string]string{"name": name}, nil).snapshot(firewallChangeScope).finish(&result)
+	return a.removeManagedFirewallRule(liveExecutor(), name)
+}
+
+// ListFirewallRules returns the managed rules, or rules whose display name contains filter
+func (a *App) ListFirewallRules(filter string) []FirewallRule {
+	selector := managedFirewallSelector()
+	if filter != "" {
+		selector = "Get-NetFirewallRule -DisplayName " + psQuote("*"+filter+"*") + " -ErrorAction SilentlyContinue"
+	}
+	rules, err := readFirewallRules(selector)
+	if err != nil {
+		return []FirewallRule{}
+	}
+	list := make([]FirewallRule, 0, len(rules))
+	for _, r := range rules {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].DisplayName < list[j].DisplayName })
+	return list
+}
+
+// VerifyFirewallRules reads the managed rules back and compares them with firewall_rules in config.json
+func (a *App) VerifyFirewallRules() []ComplianceControl {
+	config, err := loadConfig("config.json")
+	if err != nil || config.FirewallRules == nil {
+		return []ComplianceControl{}
+	}
+	return verifyFirewallRules(config.FirewallRules)
+}
@@ -41,12 +41,29 @@ import {
     InstallSoftware,
     GetHardwareInfo,
     RenamePC,
+    SuggestHostname,
+    JoinDomain,
+    JoinWorkgroup,
+    GetProfiles,
+    RunProfile,
+    GetJobQueue,
+    GetPendingReboot,
+    ScheduleReboot,
+    CancelReboot,
     SetStaticIP,
+    SetDHCP,
     SetWallpaper,
     SetBrandedWallpaper,
     SyncTime,
+    GetRegionalSettings,
     ShowThisPCIcon,
+    SetLockScreenImage,
+    SetDesktopIcons,
+    ApplyPersonalization,
     SetSleepMode,
+    GetPowerPlans,
+    ApplyPowerPlan,
+    SetActivePowerPlan,
     ConnectNAS,
     DisconnectNAS,
     AllowPing,
@@ -59,6 +76,7 @@ import {
     OptimizeSystem
 } from "../wailsjs/go/main/App";
 import { EventsOn } from "../wailsjs/runtime/runtime";
+import { main } from "../wailsjs/go/models";
 
 interface Software {
     name: string;
@@ -106,27 +124,64 @@ function App() {
         return () => unoff();
     }, []);
 
+    // Job queue progress, including a queue resumed after a restart
+    useEffect(() => {
+        const offQueue = EventsOn("job-queue", (q: main.JobQueue) => setJobQueue(q));
+        const offResult = EventsOn("job-queue-result", (result: string) => {
+            setInstallLog(result);
+            setTimeout(() => setInstallLog(""), 5000);
+        });
+        return () => { offQueue(); offResult(); };
+    }, []);
+
     // System Setup States
     const [newName, setNewName] = useState("");
+    const [domainJoin, setDomainJoin] = useState({ domain: "", ou: "", user: "", pass: "" });
     const [ipConfig, setIpConfig] = useState({ ip: "", subnet: "255.255.252.0", gateway: "", dns: "8.8.8.8, 8.8.4.4" });
     const [wallpaperUrl, setWallpaperUrl] = useState("");
+    const [wallpaperStyle, setWallpaperStyle] = useState("fill");
+    const [lockScreenUrl, setLockScreenUrl] = useState("");
     const [nasUser, setNasUser] = useState("");
     const [nasPass, setNasPass] = useState("");
     const [showNasLogin, setShowNasLogin] = useState(false);
     const [installProgress, setInstallProgress] = useState<Record<string, number>>({});
     const [installSource, setInstallSource] = useState<Record<string, string>>({});
     const [whitelistInput, setWhitelistInput] = useState("");
+    const [profiles, setProfiles] = useState<main.SetupProfile[]>([]);
+    const [jobQueue, setJobQueue] = useState<main.JobQueue | null>(null);
+    const [pendingReboot, setPendingReboot] = useState<main.PendingReboot | null>(null);
+    const [powerPlans, setPowerPlans] = useState<main.PowerReport | null>(null);
+    const [regional, setRegional] = useState<main.RegionalStatus | null>(null);
+    const [checkingRegional, setCheckingRegional] = useState(false);
+
+    // Read-back measures the clock offset, which takes a few seconds, so it only runs on demand
+    const checkRegional = async () => {
+        setCheckingRegional(true);
+        try {
+            setRegional(await GetRegionalSettings(""));
+        } finally {
+            setCheckingRegional(false);
+        }
+    };
 
     const refreshData = () => {
         setIsRefreshing(true);
         Promise.all([
             GetSystemStatus(),
             GetSoftwareList(),
-            GetHardwareInfo()
-        ]).then(([status, list, hw]) => {
+            GetHardwareInfo(),
+            GetProfiles(),
+            GetJobQueue(),
+            GetPendingReboot(),
+            GetPowerPlans()
+        ]).then(([status, list, hw, profs, queue, reboot, power]) => {
             setSystemStatus(status);
             setSoftwares(list);
             setHwInfo(hw);
+            setProfiles(profs);
+            setJobQueue(queue);
+            setPendingReboot(reboot);
+            setPowerPlans(power);
             setTimeout(() => setIsRefreshing(false), 1000);
         });
     }
@@ -164,6 +219,18 @@ function App() {
         }
     };
 
+    // Fill the PC name field with the next free name from the naming convention
+    const suggestName = () => {
+        SuggestHostname("", {}).then(s => {
+            if (s.error) {
+                setInstallLog("❌ Error: " + s.error);
+            } else {
+                setNewName(s.name);
+                setInstallLog(s.existing ? "ℹ️ PC already follows the naming convention" : "Suggested name: " + s.name);
+            }
+        });
+    };
+
     const handleAction = (promise: Promise<string>, softwareName?: string) => {
         // Only set global blocking loading if no softwareName is provided (system-wide tasks)
         if (!softwareName) {
@@ -552,21 +619,104 @@ function App() {
                                     style={{ marginBottom: 0, flex: 1 }}
                                     onChange={e => setNewName(e.target.value)}
                                 />
+                                <button className="install-btn" style={{ padding: '0.6rem 1.2rem', background: 'transparent', border: '1px solid var(--accent-primary)' }} onClick={suggestName} disabled={loading}>
+                                    SUGGEST
+                                </button>
                                 <button className="install-btn" style={{ padding: '0.6rem 1.2rem' }} onClick={() => handleAction(RenamePC(newName))} disabled={loading || !newName}>
                                     APPLY NAME
                                 </button>
                             </div>
                         </div>
 
+                        {/* [ DOMAIN_JOIN ] */}
+                        <div className="software-card" style={{ gridColumn: 'span 2' }}>
+                            <div className="card-header"><span className="category-badge">[ DOMAIN_JOIN ]</span><Network size={18} /></div>
+                            <div style={{ display: 'flex', flexDirection: 'column', gap: '12px' }}>
+                                <div style={{ display: 'flex', gap: '10px' }}>
+                                    <input className="setup-input" style={{ marginBottom: 0, flex: 1 }} placeholder="Domain (blank = config)" value={domainJoin.domain} onChange={e => setDomainJoin({ ...domainJoin, domain: e.target.value })} />
+                                    <input className="setup-input" style={{ marginBottom: 0, flex: 2 }} placeholder="OU path (optional)" value={domainJoin.ou} onChange={e => setDomainJoin({ ...domainJoin, ou: e.target.value })} />
+                                </div>
+                                <div style={{ display: 'flex', gap: '10px' }}>
+                                    <input className="setup-input" style={{ marginBottom: 0, flex: 1 }} placeholder="Domain user" value={domainJoin.user} onChange={e => setDomainJoin({ ...domainJoin, user: e.target.value })} />
+                                    <input className="setup-input" type="password" style={{ marginBottom: 0, flex: 1 }} placeholder="Password" value={domainJoin.pass} onChange={e => setDomainJoin({ ...domainJoin, pass: e.target.value })} />
+                                </div>
+                                <div style={{ display: 'flex', alignItems: 'center', gap: '10px' }}>
+                                    <span style={{ fontSize: '0.8rem', color: 'var(--text-secondary)', flex: 1 }}>
+                                        {newName ? `Joins as ${newName.toUpperCase()} (one restart)` : "Keeps the current PC name"}
+                                    </span>
+                                    <button className="install-btn" style={{ padding: '0.6rem 1.2rem', background: 'transparent', border: '1px solid var(--accent-primary)' }} disabled={loading}
+                                        onClick={() => { handleAction(JoinWorkgroup("", newName, domainJoin.user, domainJoin.pass)); setDomainJoin({ ...domainJoin, pass: "" }); }}>
+                                        JOIN WORKGROUP
+                                    </button>
+                                    <button className="install-btn" style={{ padding: '0.6rem 1.2rem' }} disabled={loading || !domainJoin.user || !domainJoin.pass}
+                                        onClick={() => { handleAction(JoinDomain(domainJoin.domain, domainJoin.ou, newName, domainJoin.user, domainJoin.pass)); setDomainJoin({ ...domainJoin, pass: "" }); }}>
+                                        JOIN DOMAIN
+                                    </button>
+                                </div>
+                            </div>
+                        </div>
+
+                        {/* [ SETUP_PROFILES ] */}
+                        <div className="software-card" style={{ gridColumn: 'span 2' }}>
+                            <div className="card-header"><span className="category-badge">[ SETUP_PROFILES ]</span><Terminal size={18} /></div>
+                            <div style={{ display: 'flex', flexDirection: 'column', gap: '12px' }}>
+                                <div style={{ display: 'flex', flexWrap: 'wrap', gap: '10px' }}>
+                                    {profiles.map(p => (
+                                        <button key={p.name} className="install-btn" style={{ padding: '0.6rem 1.2rem' }} title={p.description} disabled={loading}
+                                            onClick={() => handleAction(RunProfile(p.name))}>
+                                            RUN {p.name.toUpperCase()}
+                                        </button>
+                                    ))}
+                                    {profiles.length === 0 && <span style={{ fontSize: '0.85rem', color: 'var(--text-secondary)' }}>No profiles in config.json</span>}
+                                </div>
+                                {jobQueue && jobQueue.jobs.length > 0 && (
+                                    <span style={{ fontSize: '0.8rem', color: 'var(--text-secondary)' }}>
+                                        QUEUE {jobQueue.profile || "AD HOC"} : {jobQueue.state.toUpperCase()} ({jobQueue.jobs.filter(j => j.status === "done").length}/{jobQueue.jobs.length} steps)
+                                    </span>
+                                )}
+                                {pendingReboot && pendingReboot.required && (
+                                    <div style={{ display: 'flex', alignItems: 'center', gap: '10px' }}>
+                                        <span style={{ fontSize: '0.8rem', color: 'var(--text-secondary)', flex: 1 }}>
+                                            RESTART PENDING : {pendingReboot.reasons.map(r => r.source).join(", ")}
+                                        </span>
+                                        {pendingReboot.scheduled_for ? (
+                                            <button className="install-btn" style={{ padding: '0.6rem 1.2rem', background: 'transparent', border: '1px solid var(--accent-primary)' }} onClick={() => handleAction(CancelReboot())} disabled={loading}>
+                                                CANCEL RESTART
+                                            </button>
+                                        ) : (
+                                            <button className="install-btn" style={{ padding: '0.6rem 1.2rem' }} onClick={() => handleAction(ScheduleReboot(-1, ""))} disabled={loading}>
+                                                RESTART
+                                            </button>
+                                        )}
+                                    </div>
+                                )}
+                            </div>
+                        </div>
+
                         {/* [ TEMPORAL_SYNC ] */}
                         <div className="software-card" style={{ gridColumn: 'span 2' }}>
                             <div className="card-header"><span className="category-badge">[ TEMPORAL_SYNC ]</span><Clock size={18} /></div>
-                            <div style={{ display: 'flex', alignItems: 'center', gap: '15px' }}>
-                                <span style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', fontWeight: 600 }}>ZONE : INDIA (12HR)</span>
-                                <div style={{ flex: 1 }}></div>
-                                <button className="install-btn" style={{ padding: '0.6rem 1.2rem' }} onClick={() => handleAction(SyncTime())} disabled={loading}>
-                                    SYNC TIME
-                                </button>
+                            <div style={{ display: 'flex', flexDirection: 'column', gap: '10px' }}>
+                                <div style={{ display: 'flex', alignItems: 'center', gap: '15px' }}>
+                                    <span style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', fontWeight: 600 }}>
+                                        {regional
+                                            ? `ZONE : ${regional.time_zone.toUpperCase()} (${regional.short_time || "DEFAULT"}) · NTP : ${regional.ntp_servers?.join(", ") || "DEFAULT"}`
+                                            : "ZONE : FROM CONFIG"}
+                                    </span>
+                                    <div style={{ flex: 1 }}></div>
+                                    <button className="install-btn" style={{ padding: '0.6rem 1.2rem', background: 'transparent', border: '1px solid var(--accent-primary)' }} onClick={checkRegional} disabled={loading || checkingRegional}>
+                                        {checkingRegional ? "CHECKING..." : "CHECK"}
+                                    </button>
+                                    <button className="install-btn" style={{ padding: '0.6rem 1.2rem' }} onClick={() => { setRegional(null); handleAction(SyncTime()); }} disabled={loading}>
+                                        SYNC TIME
+                                    </button>
+                                </div>
+                                {regional && (
+                                    <span style={{ fontSize: '0.8rem', color: regional.mismatches.length > 0 ? 'var(--accent-warning)' : 'var(--text-secondary)' }}>
+                                        {regional.offset_ms != null ? `OFFSET ${regional.offset_ms.toFixed(1)} ms vs ${regional.offset_server}` : "OFFSET UNKNOWN"}
+                                        {regional.mismatches.length > 0 ? ` · ${regional.mismatches.join("; ")}` : " · MATCHES CONFIG"}
+                                    </span>
+                                )}
                             </div>
                         </div>
 
@@ -585,7 +735,10 @@ function App() {
                                             style={{ marginBottom: 0 }}
                                             onChange={e => setWallpaperUrl(e.target.value)}
                                         />
-                                        <button className="install-btn" style={{ padding: '0.6rem 1.2rem', whiteSpace: 'nowrap' }} onClick={() => handleAction(SetWallpaper(wallpaperUrl))} disabled={loading || !wallpaperUrl}>
+                                        <select className="setup-input" style={{ marginBottom: 0, width: 'auto' }} value={wallpaperStyle} onChange={e => setWallpaperStyle(e.target.value)}>
+                                            {["fill", "fit", "stretch", "center", "tile", "span"].map(s => <option key={s} value={s}>{s.toUpperCase()}</option>)}
+                                        </select>
+                                        <button className="install-btn" style={{ padding: '0.6rem 1.2rem', whiteSpace: 'nowrap' }} onClick={() => handleAction(SetWallpaper(wallpaperUrl, wallpaperStyle))} disabled={loading || !wallpaperUrl}>
                                             APPLY URL
                                         </button>
                                         <button className="install-btn" style={{ padding: '0.6rem 1.2rem', whiteSpace: 'nowrap', background: 'var(--accent-primary)', color: 'white' }} onClick={() => handleAction(SetBrandedWallpaper())} disabled={loading}>
@@ -597,6 +750,28 @@ function App() {
                                     </div>
                                 </div>
 
+                                <div style={{ display: 'flex', alignItems: 'center', gap: '15px' }}>
+                                    <span style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', fontWeight: 600 }}>DESKTOP :</span>
+                                    <div style={{ display: 'flex', gap: '10px', flex: 1 }}>
+                                        <input
+                                            className="setup-input"
+                                            placeholder="Lock screen image URL or path..."
+                                            value={lockScreenUrl}
+                                            style={{ marginBottom: 0 }}
+                                            onChange={e => setLockScreenUrl(e.target.value)}
+                                        />
+                                        <button className="install-btn" style={{ padding: '0.6rem 1.2rem', whiteSpace: 'nowrap' }} onClick={() => handleAction(SetLockScreenImage(lockScreenUrl))} disabled={loading || !lockScreenUrl}>
+                                            LOCK SCREEN
+                                        </button>
+                                        <button className="install-btn" style={{ padding: '0.6rem 1.2rem', whiteSpace: 'nowrap' }} onClick={() => handleAction(SetDesktopIcons({ this_pc: true, recycle_bin: true, network: true, user_files: true }))} disabled={loading}>
+                                            ALL ICONS
+                                        </button>
+                                        <button className="install-btn" style={{ padding: '0.6rem 1.2rem', whiteSpace: 'nowrap', background: 'var(--accent-primary)', color: 'white' }} onClick={() => handleAction(ApplyPersonalization(""))} disabled={loading}>
+                                            COMPANY DESKTOP
+                                        </button>
+                                    </div>
+                                </div>
+
                                 <div style={{ display: 'flex', alignItems: 'center', gap: '15px' }}>
                                     <span style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', fontWeight: 600 }}>SLEEP MODE :</span>
                                     <div style={{ display: 'flex', gap: '10px' }}>
@@ -605,6 +780,30 @@ function App() {
                                         <button className="install-btn" style={{ padding: '0.5rem 1.5rem', background: 'transparent', border: '1px solid var(--accent-warning)', color: 'var(--accent-warning)' }} onClick={() => handleAction(SetSleepMode(0))} disabled={loading}>NEVER</button>
                                     </div>
                                 </div>
+
+                                <div style={{ display: 'flex', alignItems: 'center', gap: '15px', flexWrap: 'wrap' }}>
+                                    <span style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', fontWeight: 600 }}>POWER PLAN :</span>
+                                    <div style={{ display: 'flex', gap: '10px', flexWrap: 'wrap', flex: 1 }}>
+                                        {powerPlans?.plans.map(p => (
+                                            <button key={p.guid} className="install-btn" title={p.guid}
+                                                style={{ padding: '0.5rem 1rem', background: p.active ? 'var(--accent-primary)' : 'transparent', color: p.active ? 'white' : undefined, border: '1px solid var(--accent-primary)' }}
+                                                onClick={() => handleAction(SetActivePowerPlan(p.guid))} disabled={loading || p.active}>
+                                                {p.name.toUpperCase()}
+                                            </button>
+                                        ))}
+                                    </div>
+                                    {powerPlans?.company_plan && (
+                                        <button className="install-btn" style={{ padding: '0.5rem 1.5rem', whiteSpace: 'nowrap' }} onClick={() => handleAction(ApplyPowerPlan())} disabled={loading}
+                                            title={powerPlans.mismatches.join("\n")}>
+                                            APPLY {powerPlans.company_plan.toUpperCase()}{powerPlans.mismatches.length > 0 ? ` (${powerPlans.mismatches.length})` : ""}
+                                        </button>
+                                    )}
+                                </div>
+                                {powerPlans && powerPlans.settings.length > 0 && (
+                                    <span style={{ fontSize: '0.8rem', color: 'var(--text-secondary)' }}>
+                                        {powerPlans.settings.map(s => `${s.name}: ${s.ac}${s.dc ? ` / ${s.dc} on battery` : ""}`).join(" · ")}
+                                    </span>
+                                )}
                             </div>
                         </div>
 
@@ -621,6 +820,9 @@ function App() {
                                 <button className="install-btn" style={{ flex: 1 }} onClick={() => handleAction(SetStaticIP(ipConfig.ip, ipConfig.subnet, ipConfig.gateway, ipConfig.dns))} disabled={loading || !ipConfig.ip}>
                                     APPLY NETWORK CONFIG
                                 </button>
+                                <button className="install-btn" style={{ padding: '0.6rem 1.5rem', background: 'transparent', border: '1px solid var(--accent-primary)' }} onClick={() => handleAction(SetDHCP(""))} disabled={loading}>
+                                    USE DHCP
+                                </button>
                                 <button className="install-btn" style={{ padding: '0.6rem 1.5rem', background: 'transparent', border: '1px solid var(--accent-primary)' }} onClick={() => handleAction(AllowPing())} disabled={loading}>
                                     ALLOW PING
                                 </button>
@@ -4,36 +4,158 @@ import {main} from '../models';
 
 export function AllowPing():Promise<string>;
 
+export function ApplyBrowserPolicy(arg1:main.BrowserPolicy):Promise<string>;
+
+export function ApplyConfiguredBrowserPolicy():Promise<string>;
+
+export function ApplyConfiguredDeviceControl():Promise<string>;
+
+export function ApplyDeviceControl(arg1:main.DeviceControlPolicy):Promise<string>;
+
+export function ApplyFirewallRules():Promise<string>;
+
+export function ApplyNetworkSettings(arg1:main.NetworkSettings):Promise<string>;
+
+export function ApplyPersonalization(arg1:string):Promise<string>;
+
+export function ApplyPowerPlan():Promise<string>;
+
+export function ApplyRegionalSettings(arg1:string):Promise<string>;
+
 export function ApplyTightVNCConfig():Promise<string>;
 
+export function AssignIPFromPlan(arg1:string,arg2:string):Promise<string>;
+
+export function BlockPing():Promise<string>;
+
 export function BulkInstall(arg1:Array<string>):Promise<Array<string>>;
 
 export function BulkUninstall(arg1:Array<string>):Promise<Array<string>>;
 
+export function CancelJobQueue():Promise<string>;
+
+export function CancelReboot():Promise<string>;
+
+export function CheckDomainJoin(arg1:string):Promise<main.DomainPreflight>;
+
+export function ComplianceScan():Promise<main.ComplianceReport>;
+
 export function ConnectNAS(arg1:string,arg2:string):Promise<string>;
 
 export function DisconnectNAS():Promise<string>;
 
+export function DuplicatePowerPlan(arg1:string,arg2:string):Promise<string>;
+
+export function EnforceNow():Promise<string>;
+
+export function ExportAuditCSV(arg1:main.AuditFilter,arg2:string):Promise<string>;
+
+export function ExportPowerPlan(arg1:string,arg2:string):Promise<string>;
+
+export function GetAuditLog(arg1:main.AuditFilter):Promise<Array<main.AuditRecord>>;
+
+export function GetBrowserPolicy():Promise<main.BrowserPolicy>;
+
+export function GetChanges():Promise<Array<main.ChangeSummary>>;
+
+export function GetConnectedDevices():Promise<Array<main.ConnectedDevice>>;
+
+export function GetDeviceControl():Promise<main.DeviceControlPolicy>;
+
+export function GetDomainStatus():Promise<main.DomainStatus>;
+
+export function GetEnforcementLog(arg1:number):Promise<Array<main.EnforcementEvent>>;
+
+export function GetEnforcementStatus():Promise<main.EnforcementStatus>;
+
 export function GetHardwareInfo():Promise<main.HardwareInfo>;
 
+export function GetIPPlan():Promise<main.IPPlan>;
+
+export function GetJobQueue():Promise<main.JobQueue>;
+
+export function GetNamingPolicy():Promise<main.NamingPolicy>;
+
+export function GetNasHealth():Promise<Array<main.NasRootStatus>>;
+
+export function GetNetworkAdapters():Promise<Array<main.NetworkAdapter>>;
+
+export function GetPeerServerStatus():Promise<string>;
+
+export function GetPendingReboot():Promise<main.PendingReboot>;
+
+export function GetPowerPlans():Promise<main.PowerReport>;
+
+export function GetProfiles():Promise<Array<main.SetupProfile>>;
+
+export function GetRegionalSettings(arg1:string):Promise<main.RegionalStatus>;
+
 export function GetSoftwareList():Promise<Array<main.Software>>;
 
 export function GetSystemStatus():Promise<string>;
 
+export function GetVNCPasswordStatus():Promise<main.VNCPasswordStatus>;
+
+export function ImportPowerPlan(arg1:string,arg2:string):Promise<string>;
+
 export function InstallDocker():Promise<string>;
 
+export function InstallEnforcementService():Promise<string>;
+
 export function InstallSQLyog():Promise<string>;
 
 export function InstallSoftware(arg1:string):Promise<string>;
 
+export function JoinDomain(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<string>;
+
+export function JoinWorkgroup(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function ListFirewallRules(arg1:string):Promise<Array<main.FirewallRule>>;
+
+export function MirrorCatalog(arg1:string):Promise<string>;
+
 export function OptimizeSystem(arg1:string):Promise<string>;
 
+export function ParseURLFilters(arg1:string):Promise<main.URLFilterParseResult>;
+
+export function PlanOperation(arg1:string,arg2:Record<string, string>):Promise<main.OperationPlan>;
+
+export function PreviewURLAccess(arg1:Array<string>,arg2:Array<string>,arg3:string):Promise<main.URLAccessPreview>;
+
+export function ProposeIPAddress(arg1:string):Promise<main.IPProposal>;
+
+export function RemoveEnforcementService():Promise<string>;
+
+export function RemoveFirewallRule(arg1:string):Promise<string>;
+
 export function RenamePC(arg1:string):Promise<string>;
 
+export function ResumeJobQueue():Promise<string>;
+
+export function Rollback(arg1:string):Promise<string>;
+
+export function RunJobQueue(arg1:Array<main.JobStep>):Promise<string>;
+
+export function RunNetworkDiagnostics():Promise<main.NetworkDiagnostics>;
+
+export function RunProfile(arg1:string):Promise<string>;
+
+export function ScheduleReboot(arg1:number,arg2:string):Promise<string>;
+
+export function SetActivePowerPlan(arg1:string):Promise<string>;
+
 export function SetBrandedWallpaper():Promise<string>;
 
+export function SetDHCP(arg1:string):Promise<string>;
+
+export function SetDesktopIcons(arg1:Record<string, boolean>):Promise<string>;
+
 export function SetDomainWhitelist(arg1:string):Promise<string>;
 
+export function SetFirewallRule(arg1:main.FirewallRule):Promise<string>;
+
+export function SetLockScreenImage(arg1:string):Promise<string>;
+
 export function SetRDPBlock(arg1:boolean):Promise<string>;
 
 export function SetSleepMode(arg1:number):Promise<string>;
@@ -42,12 +164,30 @@ export function SetStaticIP(arg1:string,arg2:string,arg3:string,arg4:string):Pro
 
 export function SetUSBBlock(arg1:boolean):Promise<string>;
 
-export function SetWallpaper(arg1:string):Promise<string>;
+export function SetVNCPasswords(arg1:string,arg2:string):Promise<string>;
+
+export function SetWallpaper(arg1:string,arg2:string):Promise<string>;
 
 export function ShowThisPCIcon():Promise<string>;
 
+export function StartPeerServer():Promise<string>;
+
+export function StopPeerServer():Promise<string>;
+
+export function SuggestHostname(arg1:string,arg2:Record<string, string>):Promise<main.HostnameSuggestion>;
+
 export function SyncTime():Promise<string>;
 
 export function TestSoftware(arg1:string):Promise<string>;
 
 export function UninstallSoftware(arg1:string):Promise<string>;
+
+export function ValidateHostname(arg1:string):Promise<main.HostnameCheck>;
+
+export function ValidateNetworkSettings(arg1:main.NetworkSettings):Promise<Array<string>>;
+
+export function VerifyAudit():Promise<main.AuditVerifyReport>;
+
+export function VerifyBrowserPolicy(arg1:main.BrowserPolicy):Promise<Array<main.ComplianceControl>>;
+
+export function VerifyFirewallRules():Promise<Array<main.ComplianceControl>>;
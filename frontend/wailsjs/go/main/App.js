@@ -6,10 +6,54 @@ export function AllowPing() {
   return window['go']['main']['App']['AllowPing']();
 }
 
+export function ApplyBrowserPolicy(arg1) {
+  return window['go']['main']['App']['ApplyBrowserPolicy'](arg1);
+}
+
+export function ApplyConfiguredBrowserPolicy() {
+  return window['go']['main']['App']['ApplyConfiguredBrowserPolicy']();
+}
+
+export function ApplyConfiguredDeviceControl() {
+  return window['go']['main']['App']['ApplyConfiguredDeviceControl']();
+}
+
+export function ApplyDeviceControl(arg1) {
+  return window['go']['main']['App']['ApplyDeviceControl'](arg1);
+}
+
+export function ApplyFirewallRules() {
+  return window['go']['main']['App']['ApplyFirewallRules']();
+}
+
+export function ApplyNetworkSettings(arg1) {
+  return window['go']['main']['App']['ApplyNetworkSettings'](arg1);
+}
+
+export function ApplyPersonalization(arg1) {
+  return window['go']['main']['App']['ApplyPersonalization'](arg1);
+}
+
+export function ApplyPowerPlan() {
+  return window['go']['main']['App']['ApplyPowerPlan']();
+}
+
+export function ApplyRegionalSettings(arg1) {
+  return window['go']['main']['App']['ApplyRegionalSettings'](arg1);
+}
+
 export function ApplyTightVNCConfig() {
   return window['go']['main']['App']['ApplyTightVNCConfig']();
 }
 
+export function AssignIPFromPlan(arg1, arg2) {
+  return window['go']['main']['App']['AssignIPFromPlan'](arg1, arg2);
+}
+
+export function BlockPing() {
+  return window['go']['main']['App']['BlockPing']();
+}
+
 export function BulkInstall(arg1) {
   return window['go']['main']['App']['BulkInstall'](arg1);
 }
@@ -18,6 +62,22 @@ export function BulkUninstall(arg1) {
   return window['go']['main']['App']['BulkUninstall'](arg1);
 }
 
+export function CancelJobQueue() {
+  return window['go']['main']['App']['CancelJobQueue']();
+}
+
+export function CancelReboot() {
+  return window['go']['main']['App']['CancelReboot']();
+}
+
+export function CheckDomainJoin(arg1) {
+  return window['go']['main']['App']['CheckDomainJoin'](arg1);
+}
+
+export function ComplianceScan() {
+  return window['go']['main']['App']['ComplianceScan']();
+}
+
 export function ConnectNAS(arg1, arg2) {
   return window['go']['main']['App']['ConnectNAS'](arg1, arg2);
 }
@@ -26,10 +86,98 @@ export function DisconnectNAS() {
   return window['go']['main']['App']['DisconnectNAS']();
 }
 
+export function DuplicatePowerPlan(arg1, arg2) {
+  return window['go']['main']['App']['DuplicatePowerPlan'](arg1, arg2);
+}
+
+export function EnforceNow() {
+  return window['go']['main']['App']['EnforceNow']();
+}
+
+export function ExportAuditCSV(arg1, arg2) {
+  return window['go']['main']['App']['ExportAuditCSV'](arg1, arg2);
+}
+
+export function ExportPowerPlan(arg1, arg2) {
+  return window['go']['main']['App']['ExportPowerPlan'](arg1, arg2);
+}
+
+export function GetAuditLog(arg1) {
+  return window['go']['main']['App']['GetAuditLog'](arg1);
+}
+
+export function GetBrowserPolicy() {
+  return window['go']['main']['App']['GetBrowserPolicy']();
+}
+
+export function GetChanges() {
+  return window['go']['main']['App']['GetChanges']();
+}
+
+export function GetConnectedDevices() {
+  return window['go']['main']['App']['GetConnectedDevices']();
+}
+
+export function GetDeviceControl() {
+  return window['go']['main']['App']['GetDeviceControl']();
+}
+
+export function GetDomainStatus() {
+  return window['go']['main']['App']['GetDomainStatus']();
+}
+
+export function GetEnforcementLog(arg1) {
+  return window['go']['main']['App']['GetEnforcementLog'](arg1);
+}
+
+export function GetEnforcementStatus() {
+  return window['go']['main']['App']['GetEnforcementStatus']();
+}
+
 export function GetHardwareInfo() {
   return window['go']['main']['App']['GetHardwareInfo']();
 }
 
+export function GetIPPlan() {
+  return window['go']['main']['App']['GetIPPlan']();
+}
+
+export function GetJobQueue() {
+  return window['go']['main']['App']['GetJobQueue']();
+}
+
+export function GetNamingPolicy() {
+  return window['go']['main']['App']['GetNamingPolicy']();
+}
+
+export function GetNasHealth() {
+  return window['go']['main']['App']['GetNasHealth']();
+}
+
+export function GetNetworkAdapters() {
+  return window['go']['main']['App']['GetNetworkAdapters']();
+}
+
+export function GetPeerServerStatus() {
+  return window['go']['main']['App']['GetPeerServerStatus']();
+}
+
+export function GetPendingReboot() {
+  return window['go']['main']['App']['GetPendingReboot']();
+}
+
+export function GetPowerPlans() {
+  return window['go']['main']['App']['GetPowerPlans']();
+}
+
+export function GetProfiles() {
+  return window['go']['main']['App']['GetProfiles']();
+}
+
+export function GetRegionalSettings(arg1) {
+  return window['go']['main']['App']['GetRegionalSettings'](arg1);
+}
+
 export function GetSoftwareList() {
   return window['go']['main']['App']['GetSoftwareList']();
 }
@@ -38,10 +186,22 @@ export function GetSystemStatus() {
   return window['go']['main']['App']['GetSystemStatus']();
 }
 
+export function GetVNCPasswordStatus() {
+  return window['go']['main']['App']['GetVNCPasswordStatus']();
+}
+
+export function ImportPowerPlan(arg1, arg2) {
+  return window['go']['main']['App']['ImportPowerPlan'](arg1, arg2);
+}
+
 export function InstallDocker() {
   return window['go']['main']['App']['InstallDocker']();
 }
 
+export function InstallEnforcementService() {
+  return window['go']['main']['App']['InstallEnforcementService']();
+}
+
 export function InstallSQLyog() {
   return window['go']['main']['App']['InstallSQLyog']();
 }
@@ -50,22 +210,106 @@ export function InstallSoftware(arg1) {
   return window['go']['main']['App']['InstallSoftware'](arg1);
 }
 
+export function JoinDomain(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['JoinDomain'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function JoinWorkgroup(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['JoinWorkgroup'](arg1, arg2, arg3, arg4);
+}
+
+export function ListFirewallRules(arg1) {
+  return window['go']['main']['App']['ListFirewallRules'](arg1);
+}
+
+export function MirrorCatalog(arg1) {
+  return window['go']['main']['App']['MirrorCatalog'](arg1);
+}
+
 export function OptimizeSystem(arg1) {
   return window['go']['main']['App']['OptimizeSystem'](arg1);
 }
 
+export function ParseURLFilters(arg1) {
+  return window['go']['main']['App']['ParseURLFilters'](arg1);
+}
+
+export function PlanOperation(arg1, arg2) {
+  return window['go']['main']['App']['PlanOperation'](arg1, arg2);
+}
+
+export function PreviewURLAccess(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewURLAccess'](arg1, arg2, arg3);
+}
+
+export function ProposeIPAddress(arg1) {
+  return window['go']['main']['App']['ProposeIPAddress'](arg1);
+}
+
+export function RemoveEnforcementService() {
+  return window['go']['main']['App']['RemoveEnforcementService']();
+}
+
+export function RemoveFirewallRule(arg1) {
+  return window['go']['main']['App']['RemoveFirewallRule'](arg1);
+}
+
 export function RenamePC(arg1) {
   return window['go']['main']['App']['RenamePC'](arg1);
 }
 
+export function ResumeJobQueue() {
+  return window['go']['main']['App']['ResumeJobQueue']();
+}
+
+export function Rollback(arg1) {
+  return window['go']['main']['App']['Rollback'](arg1);
+}
+
+export function RunJobQueue(arg1) {
+  return window['go']['main']['App']['RunJobQueue'](arg1);
+}
+
+export function RunNetworkDiagnostics() {
+  return window['go']['main']['App']['RunNetworkDiagnostics']();
+}
+
+export function RunProfile(arg1) {
+  return window['go']['main']['App']['RunProfile'](arg1);
+}
+
+export function ScheduleReboot(arg1, arg2) {
+  return window['go']['main']['App']['ScheduleReboot'](arg1, arg2);
+}
+
+export function SetActivePowerPlan(arg1) {
+  return window['go']['main']['App']['SetActivePowerPlan'](arg1);
+}
+
 export function SetBrandedWallpaper() {
   return window['go']['main']['App']['SetBrandedWallpaper']();
 }
 
+export function SetDHCP(arg1) {
+  return window['go']['main']['App']['SetDHCP'](arg1);
+}
+
+export function SetDesktopIcons(arg1) {
+  return window['go']['main']['App']['SetDesktopIcons'](arg1);
+}
+
 export function SetDomainWhitelist(arg1) {
   return window['go']['main']['App']['SetDomainWhitelist'](arg1);
 }
 
+export function SetFirewallRule(arg1) {
+  return window['go']['main']['App']['SetFirewallRule'](arg1);
+}
+
+export function SetLockScreenImage(arg1) {
+  return window['go']['main']['App']['SetLockScreenImage'](arg1);
+}
+
 export function SetRDPBlock(arg1) {
   return window['go']['main']['App']['SetRDPBlock'](arg1);
 }
@@ -82,14 +326,30 @@ export function SetUSBBlock(arg1) {
   return window['go']['main']['App']['SetUSBBlock'](arg1);
 }
 
-export function SetWallpaper(arg1) {
-  return window['go']['main']['App']['SetWallpaper'](arg1);
+export function SetVNCPasswords(arg1, arg2) {
+  return window['go']['main']['App']['SetVNCPasswords'](arg1, arg2);
+}
+
+export function SetWallpaper(arg1, arg2) {
+  return window['go']['main']['App']['SetWallpaper'](arg1, arg2);
 }
 
 export function ShowThisPCIcon() {
   return window['go']['main']['App']['ShowThisPCIcon']();
 }
 
+export function StartPeerServer() {
+  return window['go']['main']['App']['StartPeerServer']();
+}
+
+export function StopPeerServer() {
+  return window['go']['main']['App']['StopPeerServer']();
+}
+
+export function SuggestHostname(arg1, arg2) {
+  return window['go']['main']['App']['SuggestHostname'](arg1, arg2);
+}
+
 export function SyncTime() {
   return window['go']['main']['App']['SyncTime']();
 }
@@ -101,3 +361,23 @@ export function TestSoftware(arg1) {
 export function UninstallSoftware(arg1) {
   return window['go']['main']['App']['UninstallSoftware'](arg1);
 }
+
+export function ValidateHostname(arg1) {
+  return window['go']['main']['App']['ValidateHostname'](arg1);
+}
+
+export function ValidateNetworkSettings(arg1) {
+  return window['go']['main']['App']['ValidateNetworkSettings'](arg1);
+}
+
+export function VerifyAudit() {
+  return window['go']['main']['App']['VerifyAudit']();
+}
+
+export function VerifyBrowserPolicy(arg1) {
+  return window['go']['main']['App']['VerifyBrowserPolicy'](arg1);
+}
+
+export function VerifyFirewallRules() {
+  return window['go']['main']['App']['VerifyFirewallRules']();
+}
@@ -1,5 +1,487 @@
 export namespace main {
 	
+	export class AuditFilter {
+	    operation: string;
+	    user: string;
+	    since: string;
+	    until: string;
+	    text: string;
+	    failures_only: boolean;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation = source["operation"];
+	        this.user = source["user"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.text = source["text"];
+	        this.failures_only = source["failures_only"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class AuditRecord {
+	    time: string;
+	    user: string;
+	    host: string;
+	    operation: string;
+	    params: Record<string, string>;
+	    result: string;
+	    success: boolean;
+	    before: Record<string, string>;
+	    after: Record<string, string>;
+	    change_id?: string;
+	    seq: number;
+	    prev_hash: string;
+	    hash: string;
+	    signature?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.user = source["user"];
+	        this.host = source["host"];
+	        this.operation = source["operation"];
+	        this.params = source["params"];
+	        this.result = source["result"];
+	        this.success = source["success"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.change_id = source["change_id"];
+	        this.seq = source["seq"];
+	        this.prev_hash = source["prev_hash"];
+	        this.hash = source["hash"];
+	        this.signature = source["signature"];
+	    }
+	}
+	export class AuditVerifyReport {
+	    valid: boolean;
+	    records: number;
+	    legacy_records: number;
+	    checkpoints: number;
+	    last_seq: number;
+	    last_checkpoint_seq: number;
+	    problems: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditVerifyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.valid = source["valid"];
+	        this.records = source["records"];
+	        this.legacy_records = source["legacy_records"];
+	        this.checkpoints = source["checkpoints"];
+	        this.last_seq = source["last_seq"];
+	        this.last_checkpoint_seq = source["last_checkpoint_seq"];
+	        this.problems = source["problems"];
+	    }
+	}
+	export class BrowserPolicy {
+	    browsers: string[];
+	    allowlist: string[];
+	    blocklist: string[];
+	    force_install_extensions: string[];
+	    blocked_extensions: string[];
+	    firefox_extensions: FirefoxExtension[];
+	    firefox_blocked_extensions: string[];
+	    homepage: string;
+	    dns_over_https: string;
+	    doh_template: string;
+	    disable_incognito: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrowserPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.browsers = source["browsers"];
+	        this.allowlist = source["allowlist"];
+	        this.blocklist = source["blocklist"];
+	        this.force_install_extensions = source["force_install_extensions"];
+	        this.blocked_extensions = source["blocked_extensions"];
+	        this.firefox_extensions = this.convertValues(source["firefox_extensions"], FirefoxExtension);
+	        this.firefox_blocked_extensions = source["firefox_blocked_extensions"];
+	        this.homepage = source["homepage"];
+	        this.dns_over_https = source["dns_over_https"];
+	        this.doh_template = source["doh_template"];
+	        this.disable_incognito = source["disable_incognito"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ChangeSummary {
+	    id: string;
+	    time: string;
+	    user: string;
+	    operation: string;
+	    params: Record<string, string>;
+	    items: string[];
+	    problems: string[];
+	    failed: boolean;
+	    rolled_back: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChangeSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = source["time"];
+	        this.user = source["user"];
+	        this.operation = source["operation"];
+	        this.params = source["params"];
+	        this.items = source["items"];
+	        this.problems = source["problems"];
+	        this.failed = source["failed"];
+	        this.rolled_back = source["rolled_back"];
+	    }
+	}
+	export class CompanyShortcut {
+	    name: string;
+	    target: string;
+	    arguments?: string;
+	    icon?: string;
+	    location?: string;
+	    folder?: string;
+	    all_users?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompanyShortcut(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.target = source["target"];
+	        this.arguments = source["arguments"];
+	        this.icon = source["icon"];
+	        this.location = source["location"];
+	        this.folder = source["folder"];
+	        this.all_users = source["all_users"];
+	    }
+	}
+	export class ComplianceControl {
+	    control: string;
+	    expected: string;
+	    actual: string;
+	    pass: boolean;
+	    remediation: string;
+	    fix_operation: string;
+	    fix_params: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComplianceControl(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.control = source["control"];
+	        this.expected = source["expected"];
+	        this.actual = source["actual"];
+	        this.pass = source["pass"];
+	        this.remediation = source["remediation"];
+	        this.fix_operation = source["fix_operation"];
+	        this.fix_params = source["fix_params"];
+	    }
+	}
+	export class ComplianceReport {
+	    time: string;
+	    host: string;
+	    passed: number;
+	    failed: number;
+	    controls: ComplianceControl[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComplianceReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.host = source["host"];
+	        this.passed = source["passed"];
+	        this.failed = source["failed"];
+	        this.controls = this.convertValues(source["controls"], ComplianceControl);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConnectedDevice {
+	    class: string;
+	    name: string;
+	    instance_id: string;
+	    hardware_ids: string[];
+	    status: string;
+	    removable: boolean;
+	    approved: boolean;
+	    access: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectedDevice(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.class = source["class"];
+	        this.name = source["name"];
+	        this.instance_id = source["instance_id"];
+	        this.hardware_ids = source["hardware_ids"];
+	        this.status = source["status"];
+	        this.removable = source["removable"];
+	        this.approved = source["approved"];
+	        this.access = source["access"];
+	    }
+	}
+	export class DeviceControlPolicy {
+	    removable_disks: string;
+	    cd_dvd: string;
+	    phones: string;
+	    floppy: string;
+	    tape: string;
+	    approved_hardware_ids: string[];
+	    approved_instance_ids: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceControlPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removable_disks = source["removable_disks"];
+	        this.cd_dvd = source["cd_dvd"];
+	        this.phones = source["phones"];
+	        this.floppy = source["floppy"];
+	        this.tape = source["tape"];
+	        this.approved_hardware_ids = source["approved_hardware_ids"];
+	        this.approved_instance_ids = source["approved_instance_ids"];
+	    }
+	}
+	export class DiagnosticCheck {
+	    category: string;
+	    target: string;
+	    status: string;
+	    detail: string;
+	    latency_ms: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiagnosticCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category = source["category"];
+	        this.target = source["target"];
+	        this.status = source["status"];
+	        this.detail = source["detail"];
+	        this.latency_ms = source["latency_ms"];
+	    }
+	}
+	export class DomainPreflight {
+	    domain: string;
+	    controllers: string[];
+	    checks: DiagnosticCheck[];
+	    ready: boolean;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DomainPreflight(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.domain = source["domain"];
+	        this.controllers = source["controllers"];
+	        this.checks = this.convertValues(source["checks"], DiagnosticCheck);
+	        this.ready = source["ready"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DomainStatus {
+	    hostname: string;
+	    pending_name: string;
+	    part_of_domain: boolean;
+	    domain: string;
+	    workgroup: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DomainStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.pending_name = source["pending_name"];
+	        this.part_of_domain = source["part_of_domain"];
+	        this.domain = source["domain"];
+	        this.workgroup = source["workgroup"];
+	    }
+	}
+	export class EnforcementEvent {
+	    time: string;
+	    control: string;
+	    expected: string;
+	    actual: string;
+	    operation: string;
+	    result: string;
+	    success: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnforcementEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.control = source["control"];
+	        this.expected = source["expected"];
+	        this.actual = source["actual"];
+	        this.operation = source["operation"];
+	        this.result = source["result"];
+	        this.success = source["success"];
+	    }
+	}
+	export class EnforcementStatus {
+	    installed: boolean;
+	    state: string;
+	    interval_minutes: number;
+	    last_check: string;
+	    last_passed: number;
+	    last_failed: number;
+	    last_remediated: number;
+	    last_error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnforcementStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.installed = source["installed"];
+	        this.state = source["state"];
+	        this.interval_minutes = source["interval_minutes"];
+	        this.last_check = source["last_check"];
+	        this.last_passed = source["last_passed"];
+	        this.last_failed = source["last_failed"];
+	        this.last_remediated = source["last_remediated"];
+	        this.last_error = source["last_error"];
+	    }
+	}
+	export class FirefoxExtension {
+	    id: string;
+	    install_url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FirefoxExtension(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.install_url = source["install_url"];
+	    }
+	}
+	export class FirewallRule {
+	    name: string;
+	    display_name: string;
+	    description: string;
+	    direction: string;
+	    action: string;
+	    protocol: string;
+	    local_ports: string[];
+	    remote_ports: string[];
+	    icmp_type: string;
+	    remote_addresses: string[];
+	    profiles: string[];
+	    program: string;
+	    enabled: boolean;
+	    group?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FirewallRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.display_name = source["display_name"];
+	        this.description = source["description"];
+	        this.direction = source["direction"];
+	        this.action = source["action"];
+	        this.protocol = source["protocol"];
+	        this.local_ports = source["local_ports"];
+	        this.remote_ports = source["remote_ports"];
+	        this.icmp_type = source["icmp_type"];
+	        this.remote_addresses = source["remote_addresses"];
+	        this.profiles = source["profiles"];
+	        this.program = source["program"];
+	        this.enabled = source["enabled"];
+	        this.group = source["group"];
+	    }
+	}
 	export class HardwareInfo {
 	    cpu: string;
 	    ram: string;
@@ -22,6 +504,784 @@ export namespace main {
 	        this.disk = source["disk"];
 	    }
 	}
+	export class HostnameCheck {
+	    name: string;
+	    valid: boolean;
+	    template: string;
+	    values: Record<string, string>;
+	    problems: string[];
+	    conflicts: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HostnameCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.valid = source["valid"];
+	        this.template = source["template"];
+	        this.values = source["values"];
+	        this.problems = source["problems"];
+	        this.conflicts = source["conflicts"];
+	    }
+	}
+	export class HostnameSuggestion {
+	    name: string;
+	    template: string;
+	    number: number;
+	    existing: boolean;
+	    skipped: string[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostnameSuggestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.template = source["template"];
+	        this.number = source["number"];
+	        this.existing = source["existing"];
+	        this.skipped = source["skipped"];
+	        this.error = source["error"];
+	    }
+	}
+	export class IPAssignment {
+	    hostname: string;
+	    ip: string;
+	    subnet: string;
+	    mac?: string;
+	    assigned_at?: string;
+	    assigned_by?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPAssignment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.ip = source["ip"];
+	        this.subnet = source["subnet"];
+	        this.mac = source["mac"];
+	        this.assigned_at = source["assigned_at"];
+	        this.assigned_by = source["assigned_by"];
+	    }
+	}
+	export class IPPlan {
+	    subnets: IPPlanSubnet[];
+	    reserved: string[];
+	    assignments: IPAssignment[];
+	
+	    static createFrom(source: any = {}) {
+	        return new IPPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subnets = this.convertValues(source["subnets"], IPPlanSubnet);
+	        this.reserved = source["reserved"];
+	        this.assignments = this.convertValues(source["assignments"], IPAssignment);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IPPlanSubnet {
+	    name: string;
+	    network: string;
+	    gateway: string;
+	    dns: string[];
+	    range_start: string;
+	    range_end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPPlanSubnet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.network = source["network"];
+	        this.gateway = source["gateway"];
+	        this.dns = source["dns"];
+	        this.range_start = source["range_start"];
+	        this.range_end = source["range_end"];
+	    }
+	}
+	export class IPProposal {
+	    hostname: string;
+	    subnet: string;
+	    ip: string;
+	    prefix: number;
+	    gateway: string;
+	    dns: string[];
+	    existing: boolean;
+	    skipped: string[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPProposal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.subnet = source["subnet"];
+	        this.ip = source["ip"];
+	        this.prefix = source["prefix"];
+	        this.gateway = source["gateway"];
+	        this.dns = source["dns"];
+	        this.existing = source["existing"];
+	        this.skipped = source["skipped"];
+	        this.error = source["error"];
+	    }
+	}
+	export class JobQueue {
+	    profile: string;
+	    jobs: QueuedJob[];
+	    state: string;
+	    reboots: number;
+	    created: string;
+	    updated: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobQueue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profile = source["profile"];
+	        this.jobs = this.convertValues(source["jobs"], QueuedJob);
+	        this.state = source["state"];
+	        this.reboots = source["reboots"];
+	        this.created = source["created"];
+	        this.updated = source["updated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JobStep {
+	    operation: string;
+	    params: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation = source["operation"];
+	        this.params = source["params"];
+	    }
+	}
+	export class NamingPolicy {
+	    templates: NamingTemplate[];
+	    values: Record<string, string[]>;
+	    inventory_path: string;
+	    enforce: boolean;
+	    skip_dns: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NamingPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.templates = this.convertValues(source["templates"], NamingTemplate);
+	        this.values = source["values"];
+	        this.inventory_path = source["inventory_path"];
+	        this.enforce = source["enforce"];
+	        this.skip_dns = source["skip_dns"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NamingTemplate {
+	    name: string;
+	    pattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NamingTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.pattern = source["pattern"];
+	    }
+	}
+	export class NasRootStatus {
+	    path: string;
+	    reachable: boolean;
+	    latency_ms: number;
+	    error: string;
+	    checked_at: string;
+	    cached: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NasRootStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.reachable = source["reachable"];
+	        this.latency_ms = source["latency_ms"];
+	        this.error = source["error"];
+	        this.checked_at = source["checked_at"];
+	        this.cached = source["cached"];
+	    }
+	}
+	export class NetworkAdapter {
+	    name: string;
+	    description: string;
+	    mac: string;
+	    status: string;
+	    link_speed: string;
+	    physical: boolean;
+	    dhcp: boolean;
+	    ipv4: string[];
+	    ipv6: string[];
+	    gateway: string;
+	    gateway_v6: string;
+	    dns: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkAdapter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.mac = source["mac"];
+	        this.status = source["status"];
+	        this.link_speed = source["link_speed"];
+	        this.physical = source["physical"];
+	        this.dhcp = source["dhcp"];
+	        this.ipv4 = source["ipv4"];
+	        this.ipv6 = source["ipv6"];
+	        this.gateway = source["gateway"];
+	        this.gateway_v6 = source["gateway_v6"];
+	        this.dns = source["dns"];
+	    }
+	}
+	export class NetworkDiagnostics {
+	    hostname: string;
+	    checked_at: string;
+	    adapters: NetworkAdapter[];
+	    proxy: ProxySettings;
+	    clock_skew_seconds: number;
+	    checks: DiagnosticCheck[];
+	    failed: number;
+	    warnings: number;
+	    summary: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkDiagnostics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.checked_at = source["checked_at"];
+	        this.adapters = this.convertValues(source["adapters"], NetworkAdapter);
+	        this.proxy = this.convertValues(source["proxy"], ProxySettings);
+	        this.clock_skew_seconds = source["clock_skew_seconds"];
+	        this.checks = this.convertValues(source["checks"], DiagnosticCheck);
+	        this.failed = source["failed"];
+	        this.warnings = source["warnings"];
+	        this.summary = source["summary"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NetworkSettings {
+	    adapter: string;
+	    dhcp: boolean;
+	    ip: string;
+	    subnet: string;
+	    gateway: string;
+	    dns: string[];
+	    ipv6: string;
+	    gateway_v6: string;
+	    rollback_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.adapter = source["adapter"];
+	        this.dhcp = source["dhcp"];
+	        this.ip = source["ip"];
+	        this.subnet = source["subnet"];
+	        this.gateway = source["gateway"];
+	        this.dns = source["dns"];
+	        this.ipv6 = source["ipv6"];
+	        this.gateway_v6 = source["gateway_v6"];
+	        this.rollback_seconds = source["rollback_seconds"];
+	    }
+	}
+	export class OperationPlan {
+	    operation: string;
+	    params: Record<string, string>;
+	    steps: PlanStep[];
+	    outcome: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OperationPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation = source["operation"];
+	        this.params = source["params"];
+	        this.steps = this.convertValues(source["steps"], PlanStep);
+	        this.outcome = source["outcome"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PendingReboot {
+	    required: boolean;
+	    reasons: RebootReason[];
+	    scheduled_for: string;
+	    boot_time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PendingReboot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.required = source["required"];
+	        this.reasons = this.convertValues(source["reasons"], RebootReason);
+	        this.scheduled_for = source["scheduled_for"];
+	        this.boot_time = source["boot_time"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PersonalizationProfile {
+	    wallpaper?: string;
+	    wallpaper_style?: string;
+	    lock_screen?: string;
+	    desktop_icons?: Record<string, boolean>;
+	    shortcuts?: CompanyShortcut[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PersonalizationProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.wallpaper = source["wallpaper"];
+	        this.wallpaper_style = source["wallpaper_style"];
+	        this.lock_screen = source["lock_screen"];
+	        this.desktop_icons = source["desktop_icons"];
+	        this.shortcuts = this.convertValues(source["shortcuts"], CompanyShortcut);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PlanStep {
+	    kind: string;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlanStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class PowerPlan {
+	    guid: string;
+	    name: string;
+	    active: boolean;
+	    company: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PowerPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.guid = source["guid"];
+	        this.name = source["name"];
+	        this.active = source["active"];
+	        this.company = source["company"];
+	    }
+	}
+	export class PowerReport {
+	    plans: PowerPlan[];
+	    active: string;
+	    company_plan: string;
+	    settings: PowerSettingStatus[];
+	    mismatches: string[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PowerReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plans = this.convertValues(source["plans"], PowerPlan);
+	        this.active = source["active"];
+	        this.company_plan = source["company_plan"];
+	        this.settings = this.convertValues(source["settings"], PowerSettingStatus);
+	        this.mismatches = source["mismatches"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PowerSettingStatus {
+	    key: string;
+	    name: string;
+	    ac: string;
+	    dc: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PowerSettingStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.name = source["name"];
+	        this.ac = source["ac"];
+	        this.dc = source["dc"];
+	    }
+	}
+	export class ProxySettings {
+	    enabled: boolean;
+	    server: string;
+	    bypass: string;
+	    auto_config_url: string;
+	    winhttp: string;
+	    environment: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProxySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.server = source["server"];
+	        this.bypass = source["bypass"];
+	        this.auto_config_url = source["auto_config_url"];
+	        this.winhttp = source["winhttp"];
+	        this.environment = source["environment"];
+	    }
+	}
+	export class QueuedJob {
+	    status: string;
+	    result: string;
+	    finished: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueuedJob(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.result = source["result"];
+	        this.finished = source["finished"];
+	    }
+	}
+	export class RebootReason {
+	    source: string;
+	    detail: string;
+	    time?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RebootReason(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.detail = source["detail"];
+	        this.time = source["time"];
+	    }
+	}
+	export class RegionalSettings {
+	    time_zone?: string;
+	    ntp_servers?: string[];
+	    max_offset_ms?: number;
+	    short_date?: string;
+	    long_date?: string;
+	    short_time?: string;
+	    long_time?: string;
+	    decimal_symbol?: string;
+	    thousands_separator?: string;
+	    digit_grouping?: string;
+	    user_locale?: string;
+	    system_locale?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RegionalSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time_zone = source["time_zone"];
+	        this.ntp_servers = source["ntp_servers"];
+	        this.max_offset_ms = source["max_offset_ms"];
+	        this.short_date = source["short_date"];
+	        this.long_date = source["long_date"];
+	        this.short_time = source["short_time"];
+	        this.long_time = source["long_time"];
+	        this.decimal_symbol = source["decimal_symbol"];
+	        this.thousands_separator = source["thousands_separator"];
+	        this.digit_grouping = source["digit_grouping"];
+	        this.user_locale = source["user_locale"];
+	        this.system_locale = source["system_locale"];
+	    }
+	}
+	export class RegionalStatus {
+	    time_zone: string;
+	    ntp_servers: string[];
+	    sync_type: string;
+	    sync_source: string;
+	    last_sync: string;
+	    offset_ms: number;
+	    offset_server: string;
+	    short_date: string;
+	    long_date: string;
+	    short_time: string;
+	    long_time: string;
+	    decimal_symbol: string;
+	    thousands_separator: string;
+	    digit_grouping: string;
+	    user_locale: string;
+	    system_locale: string;
+	    expected: RegionalSettings;
+	    mismatches: string[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RegionalStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time_zone = source["time_zone"];
+	        this.ntp_servers = source["ntp_servers"];
+	        this.sync_type = source["sync_type"];
+	        this.sync_source = source["sync_source"];
+	        this.last_sync = source["last_sync"];
+	        this.offset_ms = source["offset_ms"];
+	        this.offset_server = source["offset_server"];
+	        this.short_date = source["short_date"];
+	        this.long_date = source["long_date"];
+	        this.short_time = source["short_time"];
+	        this.long_time = source["long_time"];
+	        this.decimal_symbol = source["decimal_symbol"];
+	        this.thousands_separator = source["thousands_separator"];
+	        this.digit_grouping = source["digit_grouping"];
+	        this.user_locale = source["user_locale"];
+	        this.system_locale = source["system_locale"];
+	        this.expected = this.convertValues(source["expected"], RegionalSettings);
+	        this.mismatches = source["mismatches"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SetupProfile {
+	    name: string;
+	    description: string;
+	    steps: JobStep[];
+	    regional?: RegionalSettings;
+	    personalization?: PersonalizationProfile;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetupProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.steps = this.convertValues(source["steps"], JobStep);
+	        this.regional = this.convertValues(source["regional"], RegionalSettings);
+	        this.personalization = this.convertValues(source["personalization"], PersonalizationProfile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Software {
 	    name: string;
 	    nas_path: string;
@@ -58,6 +1318,106 @@ export namespace main {
 	        this.is_embedded = source["is_embedded"];
 	    }
 	}
+	export class URLAccessPreview {
+	    url: string;
+	    allowed: boolean;
+	    list: string;
+	    filter: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new URLAccessPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.allowed = source["allowed"];
+	        this.list = source["list"];
+	        this.filter = source["filter"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class URLFilterEntry {
+	    input: string;
+	    valid: boolean;
+	    normalized: string;
+	    reason: string;
+	    note: string;
+	    scheme: string;
+	    host: string;
+	    subdomains: boolean;
+	    port: string;
+	    path: string;
+	    query: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new URLFilterEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.input = source["input"];
+	        this.valid = source["valid"];
+	        this.normalized = source["normalized"];
+	        this.reason = source["reason"];
+	        this.note = source["note"];
+	        this.scheme = source["scheme"];
+	        this.host = source["host"];
+	        this.subdomains = source["subdomains"];
+	        this.port = source["port"];
+	        this.path = source["path"];
+	        this.query = source["query"];
+	    }
+	}
+	export class URLFilterParseResult {
+	    entries: URLFilterEntry[];
+	    normalized: string[];
+	    invalid: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new URLFilterParseResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], URLFilterEntry);
+	        this.normalized = source["normalized"];
+	        this.invalid = source["invalid"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class VNCPasswordStatus {
+	    admin: boolean;
+	    view_only: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new VNCPasswordStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.admin = source["admin"];
+	        this.view_only = source["view_only"];
+	    }
+	}
 
 }
 
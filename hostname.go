@@ -0,0 +1,702 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NamingPolicy is the hostname convention (naming in config.json)
+type NamingPolicy struct {
+	Templates     []NamingTemplate    `json:"templates"`
+	Values        map[string][]string `json:"values"`         // allowed values per placeholder, e.g. "DEPT": ["ACC", "HR", "IT"]
+	InventoryPath string              `json:"inventory_path"` // default %ProgramData%\Triveni-Control-Center\hostnames.json
+	Enforce       bool                `json:"enforce"`        // RenamePC rejects names that match no template
+	SkipDNS       bool                `json:"skip_dns"`       // don't treat names that resolve in DNS as taken
+}
+
+// NamingTemplate is one naming pattern. {X} is a value, {X:000} a zero-padded number that is counted up.
+type NamingTemplate struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"` // e.g. TGS-{DEPT}-{ASSET:000}
+}
+
+// HostnameRecord is one PC name handed out through RenamePC, or held by SuggestHostname
+type HostnameRecord struct {
+	Name       string `json:"name"`
+	Previous   string `json:"previous,omitempty"`
+	Serial     string `json:"serial,omitempty"` // BIOS serial number of the PC the name belongs to
+	MAC        string `json:"mac,omitempty"`
+	Reserved   bool   `json:"reserved,omitempty"` // suggested but not applied yet; lapses after hostnameReservationTTL
+	AssignedAt string `json:"assigned_at,omitempty"`
+	AssignedBy string `json:"assigned_by,omitempty"`
+}
+
+// machineID identifies a PC in the inventory independently of its name
+type machineID struct {
+	Serial string
+	MAC    string
+}
+
+// HostnameInventory is the shared list of names in use (inventory_path, usually on the NAS)
+type HostnameInventory struct {
+	Hosts []HostnameRecord `json:"hosts"`
+}
+
+// HostnameCheck is the result of validating a proposed name
+type HostnameCheck struct {
+	Name      string            `json:"name"`
+	Valid     bool              `json:"valid"`
+	Template  string            `json:"template"` // matching template, "" if none
+	Values    map[string]string `json:"values"`
+	Problems  []string          `json:"problems"`  // NetBIOS or naming policy violations
+	Conflicts []string          `json:"conflicts"` // already used according to the inventory, IP plan or DNS
+}
+
+// HostnameSuggestion is the next free name for a template
+type HostnameSuggestion struct {
+	Name     string   `json:"name"`
+	Template string   `json:"template"`
+	Number   int      `json:"number"`
+	Existing bool     `json:"existing"` // this PC already has a matching name
+	Skipped  []string `json:"skipped"`  // next in sequence but already in use
+	Error    string   `json:"error"`
+}
+
+const (
+	maxNetBIOSLength       = 15
+	maxHostnameProbes      = 50
+	hostnameReservationTTL = 24 * time.Hour
+	hostnameDNSTimeout     = 2 * time.Second
+	namingLiteralChars     = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-"
+	namingValuePattern     = `[A-Za-z0-9]+`
+	namingPlaceholderKey   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
+)
+
+// Names Windows reserves for well-known security principals; a computer cannot use them
+var reservedNetBIOSNames = map[string]bool{
+	"ANONYMOUS": true, "BATCH": true, "BUILTIN": true, "DIALUP": true, "DOMAIN": true,
+	"ENTERPRISE": true, "INTERACTIVE": true, "INTERNET": true, "LOCAL": true, "NETWORK": true,
+	"NULL": true, "PROXY": true, "RESTRICTED": true, "SELF": true, "SERVER": true,
+	"SERVICE": true, "SYSTEM": true, "USERS": true, "WORLD": true,
+}
+
+// validateNetBIOSName checks a computer name against NetBIOS and DNS hostname rules
+func validateNetBIOSName(name string) []string {
+	var problems []string
+	if name == "" {
+		return []string{"Name cannot be empty"}
+	}
+	if len(name) > maxNetBIOSLength {
+		problems = append(problems, fmt.Sprintf("PC Name too long (%d characters, max %d)", len(name), maxNetBIOSLength))
+	}
+	for _, r := range name {
+		if !strings.ContainsRune(namingLiteralChars, r) {
+			problems = append(problems, fmt.Sprintf("PC Name contains illegal character %q (only letters, digits and '-')", r))
+			break
+		}
+	}
+	if strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+		problems = append(problems, "PC Name cannot start or end with '-'")
+	}
+	if strings.Trim(name, "0123456789") == "" {
+		problems = append(problems, "PC Name cannot be only digits")
+	}
+	if reservedNetBIOSNames[strings.ToUpper(name)] {
+		problems = append(problems, name+" is a reserved Windows name")
+	}
+	return problems
+}
+
+// namePart is a literal or a placeholder of a naming template
+type namePart struct {
+	literal  string
+	key      string // placeholder, upper case; "" for a literal
+	numbered bool
+	width    int // zero padding of a numbered placeholder
+}
+
+// parseNamingTemplate splits "TGS-{DEPT}-{ASSET:000}" into literals and placeholders
+func parseNamingTemplate(pattern string) ([]namePart, error) {
+	var parts []namePart
+	numbered := 0
+	rest := pattern
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			parts = append(parts, namePart{literal: rest})
+			break
+		}
+		if open > 0 {
+			parts = append(parts, namePart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%q: unclosed '{'", pattern)
+		}
+		key, format, hasFormat := strings.Cut(rest[open+1:open+end], ":")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if key == "" || strings.Trim(key, namingPlaceholderKey) != "" {
+			return nil, fmt.Errorf("%q: invalid placeholder {%s}", pattern, rest[open+1:open+end])
+		}
+		p := namePart{key: key}
+		if hasFormat {
+			if format == "" || strings.Trim(format, "0") != "" {
+				return nil, fmt.Errorf("%q: number format must be zeros, e.g. {%s:000}", pattern, key)
+			}
+			p.numbered, p.width = true, len(format)
+			numbered++
+		}
+		parts = append(parts, p)
+		rest = rest[open+end+1:]
+	}
+	if numbered > 1 {
+		return nil, fmt.Errorf("%q: only one numbered placeholder is allowed", pattern)
+	}
+	for _, p := range parts {
+		if p.key == "" && strings.Trim(p.literal, namingLiteralChars) != "" {
+			return nil, fmt.Errorf("%q: literal %q may only contain letters, digits and '-'", pattern, p.literal)
+		}
+	}
+	return parts, nil
+}
+
+// render fills in a template; values are upper-cased
+func renderName(parts []namePart, values map[string]string, number int) string {
+	var b strings.Builder
+	for _, p := range parts {
+		switch {
+		case p.key == "":
+			b.WriteString(p.literal)
+		case p.numbered:
+			fmt.Fprintf(&b, "%0*d", p.width, number)
+		default:
+			b.WriteString(strings.ToUpper(values[p.key]))
+		}
+	}
+	return b.String()
+}
+
+// templateRegexp matches names produced by a template, capturing each placeholder in order
+func (p *NamingPolicy) templateRegexp(parts []namePart) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, part := range parts {
+		switch {
+		case part.key == "":
+			b.WriteString(regexp.QuoteMeta(part.literal))
+		case part.numbered:
+			fmt.Fprintf(&b, `(\d{%d,})`, max(part.width, 1))
+		case len(p.allowed(part.key)) > 0:
+			quoted := make([]string, len(p.allowed(part.key)))
+			for i, v := range p.allowed(part.key) {
+				quoted[i] = regexp.QuoteMeta(v)
+			}
+			b.WriteString("(" + strings.Join(quoted, "|") + ")")
+		default:
+			b.WriteString("(" + namingValuePattern + ")")
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// match returns the first template name fits, with its placeholder values and number
+func (p *NamingPolicy) match(name string) (NamingTemplate, map[string]string, int, bool) {
+	for _, t := range p.Templates {
+		parts, err := parseNamingTemplate(t.Pattern)
+		if err != nil {
+			continue
+		}
+		if values, number, ok := p.matchParts(parts, name); ok {
+			return t, values, number, true
+		}
+	}
+	return NamingTemplate{}, nil, 0, false
+}
+
+func (p *NamingPolicy) matchParts(parts []namePart, name string) (map[string]string, int, bool) {
+	m := p.templateRegexp(parts).FindStringSubmatch(name)
+	if m == nil {
+		return nil, 0, false
+	}
+	values := map[string]string{}
+	number := 0
+	i := 1
+	for _, part := range parts {
+		if part.key == "" {
+			continue
+		}
+		if part.numbered {
+			number, _ = strconv.Atoi(m[i])
+		} else {
+			values[part.key] = strings.ToUpper(m[i])
+		}
+		i++
+	}
+	return values, number, true
+}
+
+// allowed returns the permitted values of a placeholder (keys are case-insensitive)
+func (p *NamingPolicy) allowed(key string) []string {
+	for k, values := range p.Values {
+		if strings.EqualFold(k, key) {
+			return values
+		}
+	}
+	return nil
+}
+
+func (p *NamingPolicy) template(name string) (NamingTemplate, bool) {
+	for _, t := range p.Templates {
+		if name == "" || strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return NamingTemplate{}, false
+}
+
+// validate reports problems with the templates themselves
+func (p *NamingPolicy) validate() []string {
+	var problems []string
+	if len(p.Templates) == 0 {
+		problems = append(problems, "no naming templates declared")
+	}
+	for _, t := range p.Templates {
+		if _, err := parseNamingTemplate(t.Pattern); err != nil {
+			problems = append(problems, fmt.Sprintf("template %q: %v", t.Name, err))
+		}
+	}
+	for key, values := range p.Values {
+		for _, v := range values {
+			if v == "" || strings.Trim(v, namingLiteralChars) != "" {
+				problems = append(problems, fmt.Sprintf("values %s: %q may only contain letters, digits and '-'", key, v))
+			}
+		}
+	}
+	return problems
+}
+
+func hostnameInventoryPath(policy *NamingPolicy) string {
+	if policy != nil && policy.InventoryPath != "" {
+		return policy.InventoryPath
+	}
+	return filepath.Join(appDataDir(), "hostnames.json")
+}
+
+// loadHostnameInventory reads the inventory; a missing file is an empty inventory
+func loadHostnameInventory(path string) (*HostnameInventory, error) {
+	inv := &HostnameInventory{Hosts: []HostnameRecord{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, inv); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return inv, nil
+}
+
+// Serial numbers OEMs leave in the BIOS that do not identify anything
+var placeholderSerials = map[string]bool{
+	"": true, "0": true, "DEFAULT STRING": true, "TO BE FILLED BY O.E.M.": true,
+	"SYSTEM SERIAL NUMBER": true, "NONE": true, "N/A": true,
+}
+
+// thisMachine reads the BIOS serial number and primary MAC of this PC
+func thisMachine() machineID {
+	serial, _ := runPowerShell("(Get-CimInstance Win32_BIOS).SerialNumber")
+	serial = strings.TrimSpace(serial)
+	if placeholderSerials[strings.ToUpper(serial)] {
+		serial = ""
+	}
+	return machineID{Serial: serial, MAC: primaryMAC("")}
+}
+
+// owner tells whether a record belongs to the PC id; known is false when the two cannot be
+// compared (records written before hardware IDs were kept, or a PC without a serial or MAC)
+func (h HostnameRecord) owner(id machineID) (mine, known bool) {
+	if h.Serial != "" && id.Serial != "" {
+		return strings.EqualFold(h.Serial, id.Serial), true
+	}
+	if h.MAC != "" && id.MAC != "" {
+		return strings.EqualFold(h.MAC, id.MAC), true
+	}
+	return false, false
+}
+
+// lapsed reports a reservation that was never turned into a rename
+func (h HostnameRecord) lapsed() bool {
+	if !h.Reserved {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, h.AssignedAt)
+	return err != nil || time.Since(at) > hostnameReservationTTL
+}
+
+func (h HostnameRecord) hardware() string {
+	var ids []string
+	if h.Serial != "" {
+		ids = append(ids, "serial "+h.Serial)
+	}
+	if h.MAC != "" {
+		ids = append(ids, "MAC "+h.MAC)
+	}
+	return listOrNone(ids)
+}
+
+// usedNames collects the names in use from the inventory and the IP plan (upper case -> where it was found).
+// Names recorded or reserved for this PC itself are not in use.
+func usedNames(config *Config, id machineID) (map[string]string, error) {
+	used := map[string]string{}
+	inv, err := loadHostnameInventory(hostnameInventoryPath(config.Naming))
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range inv.Hosts {
+		if mine, _ := h.owner(id); mine || h.lapsed() {
+			continue
+		}
+		used[strings.ToUpper(h.Name)] = onOff(h.Reserved, "hostname inventory (reserved)", "hostname inventory")
+	}
+	if plan, err := loadIPPlan(ipPlanPath(config)); err == nil {
+		for _, a := range plan.Assignments {
+			if _, ok := used[strings.ToUpper(a.Hostname)]; !ok {
+				used[strings.ToUpper(a.Hostname)] = "IP plan"
+			}
+		}
+	}
+	return used, nil
+}
+
+// resolvesElsewhere reports the addresses name resolves to, unless they belong to this PC
+func resolvesElsewhere(name string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), hostnameDNSTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, name)
+	if err != nil || len(addrs) == 0 {
+		return nil
+	}
+	own := localIPv4()
+	var others []string
+	for _, s := range addrs {
+		if a, err := netip.ParseAddr(s); err == nil && a.Is4() && !own[a] {
+			others = append(others, s)
+		}
+	}
+	return others
+}
+
+// nameConflicts lists why name is already taken; this PC's own name never conflicts
+func nameConflicts(config *Config, used map[string]string, name string) []string {
+	if strings.EqualFold(name, hostName()) {
+		return nil
+	}
+	var conflicts []string
+	if source, ok := used[strings.ToUpper(name)]; ok {
+		conflicts = append(conflicts, "listed in the "+source)
+	}
+	if config.Naming == nil || !config.Naming.SkipDNS {
+		if addrs := resolvesElsewhere(name); len(addrs) > 0 {
+			conflicts = append(conflicts, "resolves in DNS to "+strings.Join(addrs, ", "))
+		}
+	}
+	return conflicts
+}
+
+// checkHostname validates name against NetBIOS rules, the naming templates and the names in use
+func checkHostname(config *Config, name string) HostnameCheck {
+	check := HostnameCheck{Name: name, Values: map[string]string{}, Problems: validateNetBIOSName(name), Conflicts: []string{}}
+	if policy := config.Naming; policy != nil {
+		if problems := policy.validate(); len(problems) > 0 {
+			check.Problems = append(check.Problems, "Invalid naming policy - "+strings.Join(problems, "; "))
+		} else if t, values, _, ok := policy.match(name); ok {
+			check.Template, check.Values = t.Name, values
+		} else if policy.Enforce {
+			patterns := make([]string, len(policy.Templates))
+			for i, t := range policy.Templates {
+				patterns[i] = t.Pattern
+			}
+			check.Problems = append(check.Problems, "Name does not follow the naming convention ("+strings.Join(patterns, " or ")+")")
+		}
+	}
+	if len(check.Problems) == 0 {
+		used, err := usedNames(config, thisMachine())
+		if err != nil {
+			check.Problems = append(check.Problems, "Cannot read the hostname inventory: "+err.Error())
+		} else if c := nameConflicts(config, used, name); len(c) > 0 {
+			check.Conflicts = c
+		}
+	}
+	if check.Problems == nil {
+		check.Problems = []string{}
+	}
+	check.Valid = len(check.Problems) == 0 && len(check.Conflicts) == 0
+	return check
+}
+
+// suggestHostname returns the next free name for a template. Placeholder values come from vars,
+// then from this PC's current name if it fits the template, then from single-value lists in the policy.
+func suggestHostname(config *Config, templateName string, vars map[string]string) HostnameSuggestion {
+	s := HostnameSuggestion{Skipped: []string{}}
+	policy := config.Naming
+	if policy == nil {
+		s.Error = "No naming section declared in config.json"
+		return s
+	}
+	if problems := policy.validate(); len(problems) > 0 {
+		s.Error = "Invalid naming policy - " + strings.Join(problems, "; ")
+		return s
+	}
+	t, ok := policy.template(templateName)
+	if !ok {
+		s.Error = fmt.Sprintf("Naming template %q is not declared", templateName)
+		return s
+	}
+	s.Template = t.Name
+	parts, _ := parseNamingTemplate(t.Pattern)
+
+	values := map[string]string{}
+	for key, allowed := range policy.Values {
+		if len(allowed) == 1 {
+			values[strings.ToUpper(key)] = strings.ToUpper(allowed[0])
+		}
+	}
+	current, currentNumber, currentFits := policy.matchParts(parts, hostName())
+	for key, v := range current {
+		values[key] = v
+	}
+	for key, v := range vars {
+		if v != "" {
+			values[strings.ToUpper(key)] = strings.ToUpper(v)
+		}
+	}
+	numbered := false
+	for _, part := range parts {
+		if part.numbered {
+			numbered = true
+			continue
+		}
+		if part.key == "" {
+			continue
+		}
+		v := values[part.key]
+		if v == "" {
+			s.Error = fmt.Sprintf("A value for {%s} is required", part.key)
+			return s
+		}
+		if allowed := policy.allowed(part.key); len(allowed) > 0 && !containsFold(allowed, v) {
+			s.Error = fmt.Sprintf("%s is not an allowed {%s} (%s)", v, part.key, strings.Join(allowed, ", "))
+			return s
+		}
+	}
+
+	// Already named for this template and these values
+	if currentFits && renderName(parts, values, currentNumber) == strings.ToUpper(hostName()) {
+		s.Name, s.Number, s.Existing = strings.ToUpper(hostName()), currentNumber, true
+		return s
+	}
+
+	// Pick and reserve the name under one lock, so two PCs are never offered the same name
+	path := hostnameInventoryPath(policy)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		s.Error = "Cannot create the hostname inventory folder: " + err.Error()
+		return s
+	}
+	unlock, err := lockSharedFile(path)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	defer unlock()
+	id := thisMachine()
+	used, err := usedNames(config, id)
+	if err != nil {
+		s.Error = "Cannot read the hostname inventory: " + err.Error()
+		return s
+	}
+
+	next := 1
+	if numbered {
+		for name := range used {
+			if v, n, ok := policy.matchParts(parts, name); ok && sameValues(v, values) && n >= next {
+				next = n + 1
+			}
+		}
+	}
+	for i := 0; i < maxHostnameProbes; i++ {
+		name := renderName(parts, values, next)
+		if problems := validateNetBIOSName(name); len(problems) > 0 {
+			s.Error = name + ": " + strings.Join(problems, "; ")
+			return s
+		}
+		conflicts := nameConflicts(config, used, name)
+		if len(conflicts) == 0 {
+			if err := reserveHostname(path, name, id); err != nil {
+				s.Error = "Cannot reserve " + name + " in the hostname inventory: " + err.Error()
+				return s
+			}
+			s.Name, s.Number = name, next
+			return s
+		}
+		if !numbered {
+			s.Error = name + " is already in use: " + strings.Join(conflicts, "; ")
+			return s
+		}
+		s.Skipped = append(s.Skipped, name)
+		next++
+	}
+	s.Error = fmt.Sprintf("No free name after %d attempts", maxHostnameProbes)
+	return s
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameValues(a, b map[string]string) bool {
+	for k, v := range a {
+		if !strings.EqualFold(b[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// reserveHostname holds a suggested name for this PC until it is applied or the reservation
+// lapses, replacing any earlier reservation of this PC. The caller holds the inventory lock.
+func reserveHostname(path, name string, id machineID) error {
+	inv, err := loadHostnameInventory(path)
+	if err != nil {
+		return err
+	}
+	hosts := []HostnameRecord{}
+	for _, h := range inv.Hosts {
+		if mine, _ := h.owner(id); (mine && h.Reserved) || h.lapsed() {
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+	inv.Hosts = append(hosts, HostnameRecord{
+		Name:       strings.ToUpper(name),
+		Serial:     id.Serial,
+		MAC:        id.MAC,
+		Reserved:   true,
+		AssignedAt: time.Now().Format(time.RFC3339),
+		AssignedBy: currentUserName(),
+	})
+	return writeSharedJSON(path, inv)
+}
+
+// recordHostname replaces this PC's entries in the inventory, re-reading it under the lock.
+// It fails when the name is recorded for a PC with a different serial number or MAC.
+func recordHostname(x *executor, path, name, previous string) error {
+	rec := HostnameRecord{
+		Name:       strings.ToUpper(name),
+		Previous:   previous,
+		AssignedAt: time.Now().Format(time.RFC3339),
+		AssignedBy: currentUserName(),
+	}
+	x.record("file", fmt.Sprintf("Record %s (was %s) in %s", rec.Name, rec.Previous, path))
+	if x.dryRun {
+		return nil
+	}
+	id := thisMachine()
+	rec.Serial, rec.MAC = id.Serial, id.MAC
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockSharedFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	inv, err := loadHostnameInventory(path)
+	if err != nil {
+		return err
+	}
+	hosts := []HostnameRecord{}
+	for _, h := range inv.Hosts {
+		mine, known := h.owner(id)
+		if strings.EqualFold(h.Name, rec.Name) && known && !mine && !h.lapsed() {
+			return fmt.Errorf("%s is recorded for another PC (%s)", rec.Name, h.hardware())
+		}
+		// This PC's earlier names and reservations, and entries for the names involved that predate hardware IDs
+		if mine || h.lapsed() || (!known && (strings.EqualFold(h.Name, rec.Name) || strings.EqualFold(h.Name, rec.Previous))) {
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+	inv.Hosts = append(hosts, rec)
+	return writeSharedJSON(path, inv)
+}
+
+// renameProblem applies the naming policy to a new PC name; "" means the name may be used
+func renameProblem(config *Config, newName string) string {
+	if config.Naming == nil {
+		return ""
+	}
+	check := checkHostname(config, newName)
+	if len(check.Problems) > 0 {
+		return "❌ Error: " + strings.Join(check.Problems, "; ")
+	}
+	if len(check.Conflicts) > 0 {
+		return "❌ Error: " + newName + " is already in use: " + strings.Join(check.Conflicts, "; ")
+	}
+	return ""
+}
+
+// recordRename records a successful rename in the inventory. It returns a warning to append to the result, or "".
+func recordRename(x *executor, config *Config, newName, previous string) string {
+	if config.Naming == nil {
+		return ""
+	}
+	if err := recordHostname(x, hostnameInventoryPath(config.Naming), newName, previous); err != nil {
+		return " ⚠️ The name could not be recorded in the hostname inventory: " + err.Error()
+	}
+	return ""
+}
+
+// ValidateHostname checks a proposed PC name without renaming anything
+func (a *App) ValidateHostname(name string) HostnameCheck {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return HostnameCheck{Name: name, Values: map[string]string{}, Problems: []string{"Error loading config"}, Conflicts: []string{}}
+	}
+	return checkHostname(config, name)
+}
+
+// SuggestHostname returns the next free name for a naming template ("" = the first template)
+func (a *App) SuggestHostname(template string, values map[string]string) HostnameSuggestion {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return HostnameSuggestion{Skipped: []string{}, Error: "Error loading config"}
+	}
+	return suggestHostname(config, template, values)
+}
+
+// GetNamingPolicy returns the naming templates and allowed values (nil if none are declared)
+func (a *App) GetNamingPolicy() *NamingPolicy {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return nil
+	}
+	return config.Naming
+}
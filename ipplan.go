@@ -0,0 +1,459 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// IPPlan is the shared address plan (ip_plan_path in config.json, usually on the NAS)
+type IPPlan struct {
+	Subnets     []IPPlanSubnet `json:"subnets"`
+	Reserved    []string       `json:"reserved"` // addresses or first-last ranges never handed out
+	Assignments []IPAssignment `json:"assignments"`
+}
+
+// IPPlanSubnet is one subnet and the range static addresses are taken from
+type IPPlanSubnet struct {
+	Name       string   `json:"name"`
+	Network    string   `json:"network"` // CIDR, e.g. 10.1.4.0/22
+	Gateway    string   `json:"gateway"`
+	DNS        []string `json:"dns"`
+	RangeStart string   `json:"range_start"`
+	RangeEnd   string   `json:"range_end"`
+}
+
+// IPAssignment maps a hostname to its address. Entries added by hand are kept as they are.
+type IPAssignment struct {
+	Hostname   string `json:"hostname"`
+	IP         string `json:"ip"`
+	Subnet     string `json:"subnet"`
+	MAC        string `json:"mac,omitempty"`
+	AssignedAt string `json:"assigned_at,omitempty"`
+	AssignedBy string `json:"assigned_by,omitempty"`
+}
+
+// IPProposal is the address the plan would give this PC
+type IPProposal struct {
+	Hostname string   `json:"hostname"`
+	Subnet   string   `json:"subnet"`
+	IP       string   `json:"ip"`
+	Prefix   int      `json:"prefix"`
+	Gateway  string   `json:"gateway"`
+	DNS      []string `json:"dns"`
+	Existing bool     `json:"existing"` // the hostname already has this address in the plan
+	Skipped  []string `json:"skipped"`  // free in the plan but answering on the network
+	Error    string   `json:"error"`
+}
+
+const maxIPConflictProbes = 20
+
+var (
+	modiphlpapi = windows.NewLazySystemDLL("iphlpapi.dll")
+	procSendARP = modiphlpapi.NewProc("SendARP")
+)
+
+func ipPlanPath(config *Config) string {
+	if config != nil && config.IPPlanPath != "" {
+		return config.IPPlanPath
+	}
+	return filepath.Join(appDataDir(), "ip-plan.json")
+}
+
+func loadIPPlan(path string) (*IPPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plan IPPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &plan, nil
+}
+
+// parseAddrRange parses "a.b.c.d" or "a.b.c.d-e.f.g.h"
+func parseAddrRange(s string) (netip.Addr, netip.Addr, error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	a, err := netip.ParseAddr(strings.TrimSpace(first))
+	if err != nil {
+		return a, a, fmt.Errorf("%q is not an address or range", s)
+	}
+	if !isRange {
+		return a, a, nil
+	}
+	b, err := netip.ParseAddr(strings.TrimSpace(last))
+	if err != nil || b.Less(a) {
+		return a, a, fmt.Errorf("%q is not a valid range", s)
+	}
+	return a, b, nil
+}
+
+// validate reports every problem with the plan
+func (p *IPPlan) validate() []string {
+	var problems []string
+	names := map[string]bool{}
+	for _, s := range p.Subnets {
+		network, err := netip.ParsePrefix(s.Network)
+		if err != nil || !network.Addr().Is4() {
+			problems = append(problems, fmt.Sprintf("subnet %q: network %q is not an IPv4 CIDR", s.Name, s.Network))
+			continue
+		}
+		if names[strings.ToLower(s.Name)] {
+			problems = append(problems, fmt.Sprintf("subnet %q is declared twice", s.Name))
+		}
+		names[strings.ToLower(s.Name)] = true
+		network = network.Masked()
+		for _, addr := range []string{s.Gateway, s.RangeStart, s.RangeEnd} {
+			if a, err := netip.ParseAddr(addr); err != nil || !network.Contains(a) {
+				problems = append(problems, fmt.Sprintf("subnet %q: %q is not inside %s", s.Name, addr, network))
+			}
+		}
+		if start, err1 := netip.ParseAddr(s.RangeStart); err1 == nil {
+			if end, err2 := netip.ParseAddr(s.RangeEnd); err2 == nil && end.Less(start) {
+				problems = append(problems, fmt.Sprintf("subnet %q: range ends before it starts", s.Name))
+			}
+		}
+	}
+	for _, r := range p.Reserved {
+		if _, _, err := parseAddrRange(r); err != nil {
+			problems = append(problems, "reserved: "+err.Error())
+		}
+	}
+	ips := map[netip.Addr]string{}
+	for _, a := range p.Assignments {
+		ip, err := netip.ParseAddr(a.IP)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("assignment %s: %q is not an address", a.Hostname, a.IP))
+			continue
+		}
+		if other, ok := ips[ip]; ok && !strings.EqualFold(other, a.Hostname) {
+			problems = append(problems, fmt.Sprintf("%s is assigned to both %s and %s", ip, other, a.Hostname))
+		}
+		ips[ip] = a.Hostname
+	}
+	return problems
+}
+
+func (p *IPPlan) subnet(name string) (IPPlanSubnet, netip.Prefix, bool) {
+	for _, s := range p.Subnets {
+		if strings.EqualFold(s.Name, name) {
+			network, err := netip.ParsePrefix(s.Network)
+			return s, network.Masked(), err == nil
+		}
+	}
+	return IPPlanSubnet{}, netip.Prefix{}, false
+}
+
+// assignment returns the hostname's address in the plan, if any
+func (p *IPPlan) assignment(hostname string) (IPAssignment, bool) {
+	for _, a := range p.Assignments {
+		if strings.EqualFold(a.Hostname, hostname) {
+			return a, true
+		}
+	}
+	return IPAssignment{}, false
+}
+
+// taken reports whether ip is reserved or assigned to another host
+func (p *IPPlan) taken(ip netip.Addr, hostname string) bool {
+	for _, r := range p.Reserved {
+		first, last, err := parseAddrRange(r)
+		if err == nil && !ip.Less(first) && !last.Less(ip) {
+			return true
+		}
+	}
+	for _, a := range p.Assignments {
+		if a.IP == ip.String() && !strings.EqualFold(a.Hostname, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates lists free addresses of a subnet's range in order, skipping the gateway
+func (p *IPPlan) candidates(s IPPlanSubnet, network netip.Prefix, hostname string, limit int) []netip.Addr {
+	start, err1 := netip.ParseAddr(s.RangeStart)
+	end, err2 := netip.ParseAddr(s.RangeEnd)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	var out []netip.Addr
+	for ip := start; ip.IsValid() && !end.Less(ip) && len(out) < limit; ip = ip.Next() {
+		if !network.Contains(ip) || ip.String() == s.Gateway || ip == network.Addr() || ip == lastAddress(network) {
+			continue
+		}
+		if !p.taken(ip, hostname) {
+			out = append(out, ip)
+		}
+	}
+	return out
+}
+
+// addressInUse reports whether another device answers on ip (ARP on the local segment, then ping)
+func addressInUse(ip netip.Addr) bool {
+	b := ip.As4()
+	dest := uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+	var mac [8]byte
+	size := uint32(len(mac))
+	if r, _, _ := procSendARP.Call(uintptr(dest), 0, uintptr(unsafe.Pointer(&mac[0])), uintptr(unsafe.Pointer(&size))); r == 0 && size > 0 {
+		return true
+	}
+	cmd := exec.Command("ping", "-n", "1", "-w", "700", ip.String())
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, _ := cmd.Output()
+	return strings.Contains(string(out), "TTL=")
+}
+
+// localIPv4 returns this machine's IPv4 addresses, so its own address is not reported as a conflict
+func localIPv4() map[netip.Addr]bool {
+	own := map[netip.Addr]bool{}
+	out, err := runPowerShell(`(Get-NetIPAddress -AddressFamily IPv4 -ErrorAction SilentlyContinue).IPAddress`)
+	if err != nil {
+		return own
+	}
+	for _, line := range strings.Fields(out) {
+		if a, err := netip.ParseAddr(line); err == nil {
+			own[a] = true
+		}
+	}
+	return own
+}
+
+// proposeIP picks this host's address: its existing assignment, or the first free one that nobody answers on
+func proposeIP(plan *IPPlan, hostname, subnetName string) IPProposal {
+	proposal := IPProposal{Hostname: hostname, Skipped: []string{}}
+	if problems := plan.validate(); len(problems) > 0 {
+		proposal.Error = "Invalid IP plan - " + strings.Join(problems, "; ")
+		return proposal
+	}
+
+	existing, hasExisting := plan.assignment(hostname)
+	if subnetName == "" && hasExisting {
+		subnetName = existing.Subnet
+	}
+	if subnetName == "" && len(plan.Subnets) == 1 {
+		subnetName = plan.Subnets[0].Name
+	}
+	s, network, ok := plan.subnet(subnetName)
+	if !ok {
+		proposal.Error = fmt.Sprintf("Subnet %q is not in the IP plan", subnetName)
+		return proposal
+	}
+	proposal.Subnet, proposal.Prefix, proposal.Gateway, proposal.DNS = s.Name, network.Bits(), s.Gateway, s.DNS
+
+	own := localIPv4()
+	if hasExisting && strings.EqualFold(existing.Subnet, s.Name) {
+		ip, _ := netip.ParseAddr(existing.IP)
+		proposal.IP, proposal.Existing = existing.IP, true
+		if !own[ip] && addressInUse(ip) {
+			proposal.Error = fmt.Sprintf("%s is assigned to %s in the plan but another device answers on it", ip, hostname)
+		}
+		return proposal
+	}
+
+	for _, ip := range plan.candidates(s, network, hostname, maxIPConflictProbes) {
+		if own[ip] || !addressInUse(ip) {
+			proposal.IP = ip.String()
+			return proposal
+		}
+		proposal.Skipped = append(proposal.Skipped, ip.String())
+	}
+	proposal.Error = fmt.Sprintf("No free address in %s (%s-%s)", s.Name, s.RangeStart, s.RangeEnd)
+	if len(proposal.Skipped) > 0 {
+		proposal.Error += fmt.Sprintf("; %d free in the plan but in use on the network: %s", len(proposal.Skipped), strings.Join(proposal.Skipped, ", "))
+	}
+	return proposal
+}
+
+// lockSharedFile takes a lock file next to a shared file (IP plan, hostname inventory) so two
+// technicians don't hand out the same address or name
+func lockSharedFile(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%s %s\n", hostName(), time.Now().Format(time.RFC3339))
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		// A lock left behind by a crashed run
+		if info, statErr := os.Stat(lock); statErr == nil && time.Since(info.ModTime()) > 2*time.Minute {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another run (%s)", filepath.Base(path), lock)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// setLease replaces the hostname's assignment in the plan (nil removes it) and writes the
+// plan back. The caller holds the lock and loaded plan under it.
+func setLease(path string, plan *IPPlan, hostname string, lease *IPAssignment) error {
+	var assignments []IPAssignment
+	for _, a := range plan.Assignments {
+		if !strings.EqualFold(a.Hostname, hostname) {
+			assignments = append(assignments, a)
+		}
+	}
+	if lease != nil {
+		assignments = append(assignments, *lease)
+	}
+	plan.Assignments = assignments
+	return writeSharedJSON(path, plan)
+}
+
+// releaseLease undoes a reservation whose address could not be applied, putting back the
+// hostname's previous assignment if it had one
+func releaseLease(path string, lease IPAssignment, previous *IPAssignment) error {
+	unlock, err := lockSharedFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	plan, err := loadIPPlan(path)
+	if err != nil {
+		return err
+	}
+	if current, ok := plan.assignment(lease.Hostname); !ok || current.IP != lease.IP {
+		return nil // already changed by someone else
+	}
+	return setLease(path, plan, lease.Hostname, previous)
+}
+
+// writeSharedJSON replaces a shared file in one step, so readers never see it half written
+func writeSharedJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func primaryMAC(adapter string) string {
+	out, err := runPowerShell("(" + networkAdapterSelector(adapter) + ").MacAddress")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+func (a *App) assignIPFromPlan(x *executor, subnetName, adapter string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required for network changes."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	path := ipPlanPath(config)
+	plan, err := loadIPPlan(path)
+	if err != nil {
+		return "❌ Error: Cannot read the IP plan: " + err.Error()
+	}
+
+	hostname := hostName()
+	mac := primaryMAC(adapter)
+
+	// Propose and reserve under one lock, so two PCs can't be handed the same address
+	unlock := func() {}
+	if !x.dryRun {
+		if unlock, err = lockSharedFile(path); err != nil {
+			return "❌ Error: " + err.Error()
+		}
+		if plan, err = loadIPPlan(path); err != nil {
+			unlock()
+			return "❌ Error: Cannot read the IP plan: " + err.Error()
+		}
+	}
+	proposal := proposeIP(plan, hostname, subnetName)
+	if proposal.Error != "" {
+		unlock()
+		return "❌ Error: " + proposal.Error
+	}
+	x.record("check", fmt.Sprintf("%s gets %s/%d from %s (conflict check passed)", hostname, proposal.IP, proposal.Prefix, proposal.Subnet))
+
+	lease := IPAssignment{
+		Hostname:   hostname,
+		IP:         proposal.IP,
+		Subnet:     proposal.Subnet,
+		MAC:        mac,
+		AssignedAt: time.Now().Format(time.RFC3339),
+		AssignedBy: currentUserName(),
+	}
+	var previous *IPAssignment
+	if prev, ok := plan.assignment(hostname); ok {
+		previous = &prev
+	}
+	x.record("file", fmt.Sprintf("Record %s = %s in %s", hostname, proposal.IP, path))
+	if !x.dryRun {
+		err = setLease(path, plan, hostname, &lease)
+		unlock()
+		if err != nil {
+			return fmt.Sprintf("❌ Error: Could not reserve %s in the IP plan (network settings left unchanged): %v", proposal.IP, err)
+		}
+	}
+
+	result := a.applyNetworkSettings(x, NetworkSettings{
+		Adapter: adapter,
+		IP:      proposal.IP,
+		Subnet:  fmt.Sprint(proposal.Prefix),
+		Gateway: proposal.Gateway,
+		DNS:     proposal.DNS,
+	})
+	// applyNetworkSettings puts the previous settings back itself when it fails
+	if !resultSucceeded(result) {
+		if x.dryRun {
+			return result
+		}
+		if err := releaseLease(path, lease, previous); err != nil {
+			result += fmt.Sprintf(" ⚠️ The reservation of %s could not be released: %v", proposal.IP, err)
+		}
+		return result
+	}
+	return fmt.Sprintf("✅ Success: %s assigned %s/%d from %s and recorded in the IP plan.", hostname, proposal.IP, proposal.Prefix, proposal.Subnet)
+}
+
+// GetIPPlan returns the address plan (empty if it cannot be read)
+func (a *App) GetIPPlan() IPPlan {
+	config, _ := loadConfig("config.json")
+	plan, err := loadIPPlan(ipPlanPath(config))
+	if err != nil {
+		return IPPlan{Subnets: []IPPlanSubnet{}, Reserved: []string{}, Assignments: []IPAssignment{}}
+	}
+	return *plan
+}
+
+// ProposeIPAddress tells which address this PC would get from the plan ("" subnet = its assigned or only subnet)
+func (a *App) ProposeIPAddress(subnet string) IPProposal {
+	config, _ := loadConfig("config.json")
+	plan, err := loadIPPlan(ipPlanPath(config))
+	if err != nil {
+		return IPProposal{Hostname: hostName(), Skipped: []string{}, Error: "Cannot read the IP plan: " + err.Error()}
+	}
+	return proposeIP(plan, hostName(), subnet)
+}
+
+// AssignIPFromPlan applies this PC's address from the plan after a conflict check and records the lease
+func (a *App) AssignIPFromPlan(subnet, adapter string) (result string) {
+	scope := networkChangeScope
+	scope.NetworkAdapter = adapter
+	defer beginAudit("AssignIPFromPlan", map[string]string{"subnet": subnet, "adapter": adapter}, networkAuditProbe).snapshot(scope).finish(&result)
+	return a.assignIPFromPlan(liveExecutor(), subnet, adapter)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,6 +13,11 @@ import (
 var assets embed.FS
 
 func main() {
+	// Command line mode (mirror, ...) exits without opening the window
+	if runCLI(os.Args[1:]) {
+		return
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
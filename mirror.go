@@ -0,0 +1,447 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const mirrorManifestName = "manifest.json"
+
+const (
+	// How long the HEAD request that reads a download's size and ETag may take
+	mirrorHeadTimeout = 10 * time.Second
+	// How long a single download may take; one cut off resumes on the next run
+	mirrorDownloadTimeout = 30 * time.Minute
+)
+
+// MirrorManifest describes the payloads held in a mirror folder
+type MirrorManifest struct {
+	GeneratedAt string        `json:"generated_at"`
+	Files       []MirrorEntry `json:"files"`
+}
+
+// MirrorEntry is a single mirrored installer
+type MirrorEntry struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mod_time"`
+	ETag    string `json:"etag"`
+	SHA256  string `json:"sha256"`
+	Source  string `json:"source"`
+}
+
+// MirrorReport summarizes a mirror run
+type MirrorReport struct {
+	Dest    string   `json:"dest"`
+	Copied  []string `json:"copied"`
+	Skipped []string `json:"skipped"`
+	Failed  []string `json:"failed"`
+}
+
+// mirrorSource is where a payload is pulled from during a sync
+type mirrorSource struct {
+	Location string
+	IsURL    bool
+	Size     int64 // -1 when unknown
+	ModTime  time.Time
+	ETag     string
+}
+
+// MirrorCatalog syncs all catalog payloads into dest (or the configured mirror_path)
+func (a *App) MirrorCatalog(dest string) string {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if dest == "" {
+		dest = config.MirrorPath
+	}
+	if dest == "" {
+		return "❌ Error: No mirror destination given and no mirror_path configured."
+	}
+
+	report, err := mirrorCatalog(config, dest, func(done, total int64) {
+		if a.ctx != nil && total > 0 {
+			wailsRuntime.EventsEmit(a.ctx, "download-progress", int(float64(done)/float64(total)*100))
+		}
+	}, nil)
+	if err != nil {
+		return "❌ Error: Mirror failed - " + err.Error()
+	}
+
+	summary := fmt.Sprintf("%d copied, %d up to date, %d failed", len(report.Copied), len(report.Skipped), len(report.Failed))
+	if len(report.Failed) > 0 {
+		return "⚠️ Mirror finished with errors (" + summary + "): " + strings.Join(report.Failed, "; ")
+	}
+	return "✅ Success: Mirror synced to " + dest + " (" + summary + ")."
+}
+
+// mirrorCatalog copies every non-embedded catalog payload into dest, skipping
+// files whose size/mtime (or hash) already match, and rewrites the manifest.
+func mirrorCatalog(config *Config, dest string, progress func(done, total int64), logf func(format string, args ...any)) (*MirrorReport, error) {
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+
+	manifest := loadMirrorManifest(dest)
+	previous := map[string]MirrorEntry{}
+	for _, e := range manifest.Files {
+		previous[strings.ToLower(e.File)] = e
+	}
+
+	report := &MirrorReport{Dest: dest}
+	var entries []MirrorEntry
+	seen := map[string]bool{}
+
+	for _, sw := range config.SoftwareList {
+		key := strings.ToLower(sw.NasPath)
+		if sw.IsEmbedded || sw.NasPath == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		destFile := filepath.Join(dest, sw.NasPath)
+		src, err := findMirrorSource(config, sw, dest)
+		if err != nil {
+			// Keep the previous copy listed if we still have it
+			if e, ok := previous[key]; ok && fileExists(destFile) {
+				entries = append(entries, e)
+			}
+			report.Failed = append(report.Failed, sw.Name+": "+err.Error())
+			logf("FAIL  %s: %v\n", sw.Name, err)
+			continue
+		}
+
+		entry, copied, err := syncMirrorFile(sw, src, destFile, previous[key], progress)
+		if err != nil {
+			report.Failed = append(report.Failed, sw.Name+": "+err.Error())
+			logf("FAIL  %s: %v\n", sw.Name, err)
+			continue
+		}
+		entries = append(entries, entry)
+		if copied {
+			report.Copied = append(report.Copied, sw.Name)
+			logf("COPY  %s <- %s\n", sw.Name, src.Location)
+		} else {
+			report.Skipped = append(report.Skipped, sw.Name)
+			logf("OK    %s\n", sw.Name)
+		}
+	}
+
+	manifest = MirrorManifest{GeneratedAt: time.Now().Format(time.RFC3339), Files: entries}
+	if err := saveMirrorManifest(dest, manifest); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// findMirrorSource prefers a reachable NAS root (other than the mirror itself) and falls back to the download URL
+func findMirrorSource(config *Config, sw Software, dest string) (*mirrorSource, error) {
+	timeout, ttl := config.nasProbeOptions()
+	destKey := strings.ToLower(filepath.Clean(dest))
+	for _, root := range probeNasRoots(config.nasRoots(), timeout, ttl) {
+		if !root.Reachable || strings.ToLower(filepath.Clean(root.Path)) == destKey {
+			continue
+		}
+		p := filepath.Join(root.Path, sw.NasPath)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return &mirrorSource{Location: p, Size: info.Size(), ModTime: info.ModTime()}, nil
+		}
+	}
+
+	if sw.DownloadUrl == "" {
+		return nil, fmt.Errorf("not found on NAS and no download URL")
+	}
+
+	src := &mirrorSource{Location: sw.DownloadUrl, IsURL: true, Size: -1}
+	client := &http.Client{Timeout: mirrorHeadTimeout}
+	resp, err := client.Head(sw.DownloadUrl)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			src.Size = resp.ContentLength
+			src.ETag = resp.Header.Get("ETag")
+			if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+				src.ModTime = t
+			}
+		}
+	}
+	return src, nil
+}
+
+// syncMirrorFile brings destFile up to date with src and returns its manifest entry
+func syncMirrorFile(sw Software, src *mirrorSource, destFile string, prev MirrorEntry, progress func(done, total int64)) (MirrorEntry, bool, error) {
+	entry := MirrorEntry{
+		Name:   sw.Name,
+		File:   sw.NasPath,
+		Size:   src.Size,
+		ETag:   src.ETag,
+		Source: src.Location,
+	}
+	if !src.ModTime.IsZero() {
+		entry.ModTime = src.ModTime.UTC().Format(time.RFC3339)
+	}
+
+	if info, err := os.Stat(destFile); err == nil {
+		// Unchanged metadata since the last sync
+		if mirrorEntryMatches(src, prev, info.Size()) {
+			return prev, false, nil
+		}
+
+		// Same size but different timestamp: compare content before re-copying
+		if !src.IsURL && info.Size() == src.Size {
+			destHash, err1 := sha256File(destFile)
+			srcHash, err2 := sha256File(src.Location)
+			if err1 == nil && err2 == nil && destHash == srcHash {
+				entry.SHA256 = destHash
+				os.Chtimes(destFile, src.ModTime, src.ModTime)
+				return entry, false, nil
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destFile), 0755); err != nil {
+		return entry, false, err
+	}
+	size, err := resumableCopy(src, destFile, progress)
+	if err != nil {
+		return entry, false, err
+	}
+	entry.Size = size
+
+	hash, err := sha256File(destFile)
+	if err != nil {
+		return entry, false, err
+	}
+	entry.SHA256 = hash
+	return entry, true, nil
+}
+
+// mirrorEntryMatches reports whether the previous manifest entry still describes the source
+func mirrorEntryMatches(src *mirrorSource, prev MirrorEntry, destSize int64) bool {
+	if prev.SHA256 == "" || prev.Size != destSize {
+		return false
+	}
+	if src.Size >= 0 && src.Size != prev.Size {
+		return false
+	}
+	if src.ETag != "" && prev.ETag != "" {
+		return src.ETag == prev.ETag
+	}
+	if !src.ModTime.IsZero() {
+		return src.ModTime.UTC().Format(time.RFC3339) == prev.ModTime
+	}
+	return src.Size >= 0
+}
+
+// resumableCopy copies src into destFile via a ".part" file. An interrupted
+// copy of the same source version (tracked in ".part.meta") continues where
+// it stopped instead of starting over.
+func resumableCopy(src *mirrorSource, destFile string, progress func(done, total int64)) (int64, error) {
+	partFile := destFile + ".part"
+	metaFile := partFile + ".meta"
+	version := fmt.Sprintf("%s|%d|%s|%s", src.Location, src.Size, src.ModTime.UTC().Format(time.RFC3339), src.ETag)
+
+	var offset int64
+	if data, err := os.ReadFile(metaFile); err == nil && string(data) == version {
+		if info, err := os.Stat(partFile); err == nil {
+			offset = info.Size()
+		}
+	}
+	if offset == 0 {
+		os.Remove(partFile)
+		if err := os.WriteFile(metaFile, []byte(version), 0644); err != nil {
+			return 0, err
+		}
+	}
+
+	var in io.ReadCloser
+	total := src.Size
+	if src.IsURL {
+		body, start, length, err := openMirrorDownload(src, offset)
+		if err != nil {
+			return 0, err
+		}
+		if start != offset {
+			// The server sent the whole file, or the .part no longer matches it; start over
+			offset = 0
+			os.Remove(partFile)
+		}
+		if total < 0 && length >= 0 {
+			total = offset + length
+		}
+		in = body
+	} else {
+		f, err := os.Open(src.Location)
+		if err != nil {
+			return 0, err
+		}
+		if offset > 0 {
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				f.Close()
+				return 0, err
+			}
+		}
+		in = f
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(partFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 256*1024)
+	done := offset
+	for {
+		n, rerr := in.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				out.Close()
+				return 0, err
+			}
+			done += int64(n)
+			if progress != nil {
+				progress(done, total)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			out.Close()
+			return 0, rerr
+		}
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+	if total >= 0 && done != total {
+		return 0, fmt.Errorf("incomplete copy: %d of %d bytes", done, total)
+	}
+
+	if err := os.Rename(partFile, destFile); err != nil {
+		return 0, err
+	}
+	os.Remove(metaFile)
+	if !src.ModTime.IsZero() {
+		os.Chtimes(destFile, src.ModTime, src.ModTime)
+	}
+	return done, nil
+}
+
+// openMirrorDownload requests src from offset. The range is tied to the version read by the
+// HEAD request with If-Range, so a changed file comes back whole; start is where the body
+// begins (0 when the server sent the whole file) and length its size (-1 when unknown).
+func openMirrorDownload(src *mirrorSource, offset int64) (body io.ReadCloser, start, length int64, err error) {
+	client := &http.Client{Timeout: mirrorDownloadTimeout}
+	validator := ""
+	switch {
+	case src.ETag != "" && !strings.HasPrefix(src.ETag, "W/"):
+		validator = src.ETag
+	case !src.ModTime.IsZero():
+		validator = src.ModTime.UTC().Format(http.TimeFormat)
+	}
+	// Without a validator a .part from another version of the file cannot be told apart
+	if validator == "" {
+		offset = 0
+	}
+
+	for {
+		req, err := http.NewRequest("GET", src.Location, nil)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if offset > 0 {
+			req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+			req.Header.Set("If-Range", validator)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent:
+			if first, ok := contentRangeStart(resp.Header.Get("Content-Range")); ok && first == offset {
+				return resp.Body, offset, resp.ContentLength, nil
+			}
+			resp.Body.Close()
+		case resp.StatusCode == http.StatusOK:
+			return resp.Body, 0, resp.ContentLength, nil
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+			resp.Body.Close()
+		default:
+			resp.Body.Close()
+			return nil, 0, 0, fmt.Errorf("download failed: %s", resp.Status)
+		}
+		if offset == 0 {
+			return nil, 0, 0, fmt.Errorf("download failed: unexpected range %q", resp.Header.Get("Content-Range"))
+		}
+		// The range does not fit what is on the server (416, or another start); fetch it whole
+		offset = 0
+	}
+}
+
+// contentRangeStart returns the first byte of a "bytes first-last/size" Content-Range
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	return n, err == nil
+}
+
+func loadMirrorManifest(dir string) MirrorManifest {
+	var m MirrorManifest
+	data, err := os.ReadFile(filepath.Join(dir, mirrorManifestName))
+	if err == nil {
+		json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+// saveMirrorManifest writes the manifest atomically so readers never see a partial file
+func saveMirrorManifest(dir string, m MirrorManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, mirrorManifestName+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, mirrorManifestName))
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
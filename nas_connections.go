@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// netUseEntry is one row of `net use` output
+type netUseEntry struct {
+	Status string
+	Local  string
+	Remote string
+}
+
+// listNetConnections returns the current user's network connections
+func listNetConnections() ([]netUseEntry, error) {
+	cmd := exec.Command("net", "use")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseNetUse(string(output)), nil
+}
+
+// parseNetUse extracts connections from `net use` output. Only rows that
+// contain a UNC path are considered, so localized headers and the wrapped
+// "Network" column of long remote names are skipped naturally.
+func parseNetUse(output string) []netUseEntry {
+	var entries []netUseEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+		idx := strings.Index(line, "\\\\")
+		if idx < 0 {
+			continue
+		}
+
+		// The remote column ends where the provider column starts (two or more spaces)
+		remote := line[idx:]
+		if end := strings.Index(remote, "  "); end > 0 {
+			remote = remote[:end]
+		}
+
+		entry := netUseEntry{Remote: strings.TrimSpace(remote)}
+		for _, f := range strings.Fields(line[:idx]) {
+			if len(f) == 2 && f[1] == ':' {
+				entry.Local = strings.ToUpper(f)
+			} else if entry.Status == "" {
+				entry.Status = f
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// disconnectNasServer removes every connection to the given server and the
+// credentials stored for it, leaving other mapped drives untouched. It returns
+// what was removed and any failures.
+func disconnectNasServer(server string) (sessions, creds, failures []string) {
+	conns, err := listNetConnections()
+	if err != nil {
+		failures = append(failures, "net use: "+err.Error())
+	}
+
+	for _, c := range conns {
+		if !strings.EqualFold(nasServer(c.Remote), server) {
+			continue
+		}
+		name, label := c.Remote, c.Remote
+		if c.Local != "" {
+			name = c.Local
+			label = c.Local + " " + c.Remote
+		}
+		if err := cancelNasConnection(name, true); err != nil {
+			failures = append(failures, label+": "+err.Error())
+			continue
+		}
+		sessions = append(sessions, label)
+	}
+
+	// Our own saved login, plus any Windows credential saved for the server (cmdkey /add)
+	target := nasCredentialTarget(server)
+	if err := nasCredentials.Delete(target); err == nil {
+		creds = append(creds, target)
+	} else if !errors.Is(err, errCredentialNotFound) {
+		failures = append(failures, target+": "+err.Error())
+	}
+	if err := deleteCredential(server, credTypeDomainPassword); err == nil {
+		creds = append(creds, server+" (Windows)")
+	} else if !errors.Is(err, errCredentialNotFound) {
+		failures = append(failures, server+": "+err.Error())
+	}
+
+	return sessions, creds, failures
+}
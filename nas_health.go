@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default NAS search roots, used when config.json does not define "nas_roots"
+var defaultNasRoots = []string{
+	"\\\\174.156.4.3\\fjt\\Automations-Priyanshu",
+	"\\\\174.156.4.3\\fjt\\Automations-Priyanshu\\Basic sw",
+	"\\\\174.156.4.3\\fjt\\Automations-Priyanshu\\Q2C",
+	"\\\\174.156.4.3\\fjt\\Automations-Priyanshu\\rabbitmq,elastic",
+	"\\\\174.156.4.3\\fjt\\Required softwares\\Automation Software\\Automations-Priyanshu",
+	"\\\\174.156.4.3\\fjt\\Required softwares\\Automation Software\\Automations-Priyanshu\\Basic sw",
+	"\\\\174.156.4.3\\fjt\\Required softwares\\Automation Software\\Automations-Priyanshu\\Q2C",
+	"\\\\174.156.4.3\\fjt\\Required softwares\\Update - Dev System",
+}
+
+const (
+	defaultNasProbeTimeout = 4 * time.Second
+	defaultNasCacheTTL     = 30 * time.Second
+)
+
+// NasRootStatus is the health of a single NAS search root
+type NasRootStatus struct {
+	Path      string `json:"path"`
+	Reachable bool   `json:"reachable"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error"`
+	CheckedAt string `json:"checked_at"`
+	Cached    bool   `json:"cached"`
+}
+
+// nasHealthCache remembers probe results for a short TTL so that an
+// unreachable SMB server is only waited on once per refresh window.
+type nasHealthCache struct {
+	mu       sync.Mutex
+	entries  map[string]nasHealthEntry
+	inflight map[string]chan struct{}
+}
+
+type nasHealthEntry struct {
+	status  NasRootStatus
+	expires time.Time
+}
+
+var nasHealth = &nasHealthCache{
+	entries:  map[string]nasHealthEntry{},
+	inflight: map[string]chan struct{}{},
+}
+
+// nasRoots returns the ordered, de-duplicated list of NAS roots to search.
+// A configured local/branch mirror is searched first.
+func (c *Config) nasRoots() []string {
+	roots := c.NasRoots
+	if len(roots) == 0 {
+		roots = defaultNasRoots
+	}
+
+	seen := map[string]bool{}
+	var result []string
+	for _, r := range append([]string{c.MirrorPath, c.NasBasePath}, roots...) {
+		key := strings.ToLower(strings.TrimRight(r, "\\"))
+		if r == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, r)
+	}
+	return result
+}
+
+// nasProbeOptions returns the probe timeout and cache TTL, falling back to defaults
+func (c *Config) nasProbeOptions() (time.Duration, time.Duration) {
+	timeout, ttl := defaultNasProbeTimeout, defaultNasCacheTTL
+	if c.NasProbeTimeoutSec > 0 {
+		timeout = time.Duration(c.NasProbeTimeoutSec) * time.Second
+	}
+	if c.NasCacheTTLSec > 0 {
+		ttl = time.Duration(c.NasCacheTTLSec) * time.Second
+	}
+	return timeout, ttl
+}
+
+// probeNasRoots checks all roots concurrently and returns their status in input order
+func probeNasRoots(roots []string, timeout, ttl time.Duration) []NasRootStatus {
+	results := make([]NasRootStatus, len(roots))
+	var wg sync.WaitGroup
+	for i, root := range roots {
+		wg.Add(1)
+		go func(i int, root string) {
+			defer wg.Done()
+			results[i] = nasHealth.get(root, timeout, ttl)
+		}(i, root)
+	}
+	wg.Wait()
+	return results
+}
+
+// get returns a cached status if still fresh, otherwise probes the root.
+// Concurrent callers for the same root share a single probe.
+func (c *nasHealthCache) get(root string, timeout, ttl time.Duration) NasRootStatus {
+	key := strings.ToLower(root)
+	for {
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok && time.Now().Before(e.expires) {
+			c.mu.Unlock()
+			status := e.status
+			status.Cached = true
+			return status
+		}
+		if wait, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			<-wait
+			continue
+		}
+		done := make(chan struct{})
+		c.inflight[key] = done
+		c.mu.Unlock()
+
+		status := probeNasRoot(root, timeout)
+
+		c.mu.Lock()
+		c.entries[key] = nasHealthEntry{status: status, expires: time.Now().Add(ttl)}
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		close(done)
+		return status
+	}
+}
+
+// invalidate drops all cached results (e.g. after connecting or disconnecting the NAS)
+func (c *nasHealthCache) invalidate() {
+	c.mu.Lock()
+	c.entries = map[string]nasHealthEntry{}
+	c.mu.Unlock()
+}
+
+// probeNasRoot stats a UNC path with a hard timeout. os.Stat cannot be
+// cancelled, so a hung stat is abandoned and finishes in the background.
+func probeNasRoot(root string, timeout time.Duration) NasRootStatus {
+	status := NasRootStatus{Path: root, CheckedAt: time.Now().Format(time.RFC3339)}
+	if runtime.GOOS != "windows" {
+		status.Error = "NAS probing is only supported on Windows"
+		return status
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		_, err := os.Stat(root)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		status.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Reachable = true
+		}
+	case <-time.After(timeout):
+		status.LatencyMs = timeout.Milliseconds()
+		status.Error = fmt.Sprintf("timed out after %s", timeout)
+	}
+	return status
+}
+
+// GetNasHealth returns per-root reachability and latency for all configured NAS roots
+func (a *App) GetNasHealth() []NasRootStatus {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return []NasRootStatus{}
+	}
+	timeout, ttl := config.nasProbeOptions()
+	return probeNasRoots(config.nasRoots(), timeout, ttl)
+}
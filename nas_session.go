@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modmpr                     = windows.NewLazySystemDLL("mpr.dll")
+	procWNetAddConnection2W    = modmpr.NewProc("WNetAddConnection2W")
+	procWNetCancelConnection2W = modmpr.NewProc("WNetCancelConnection2W")
+)
+
+const (
+	resourceTypeDisk = 1
+	connectTemporary = 0x4
+
+	errSessionCredentialConflict = windows.Errno(1219)
+)
+
+// How often an automatic reconnect with stored credentials may be attempted
+const nasReconnectInterval = time.Minute
+
+var (
+	nasReconnectMu   sync.Mutex
+	nasLastReconnect time.Time
+
+	// connectNas opens the session for ensureNasSession; replaced in tests
+	connectNas = connectNasShare
+)
+
+// netResource mirrors the Win32 NETRESOURCEW structure
+type netResource struct {
+	Scope       uint32
+	Type        uint32
+	DisplayType uint32
+	Usage       uint32
+	LocalName   *uint16
+	RemoteName  *uint16
+	Comment     *uint16
+	Provider    *uint16
+}
+
+// nasServer extracts the server name from a UNC path (\\server\share\... -> server)
+func nasServer(path string) string {
+	parts := strings.Split(strings.TrimLeft(path, "\\"), "\\")
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[0]
+}
+
+// nasShareRoot trims a UNC path down to \\server\share, the unit Windows connects to
+func nasShareRoot(path string) string {
+	parts := strings.Split(strings.TrimLeft(path, "\\"), "\\")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return "\\\\" + parts[0] + "\\" + parts[1]
+}
+
+// connectNasShare opens a temporary, deviceless SMB session. The password is
+// handed to the API directly and never appears on a command line.
+func connectNasShare(remote, user, pass string) error {
+	remotePtr, err := windows.UTF16PtrFromString(remote)
+	if err != nil {
+		return err
+	}
+	userPtr, err := windows.UTF16PtrFromString(user)
+	if err != nil {
+		return err
+	}
+	passPtr, err := windows.UTF16PtrFromString(pass)
+	if err != nil {
+		return err
+	}
+
+	res := netResource{Type: resourceTypeDisk, RemoteName: remotePtr}
+	call := func() error {
+		r, _, _ := procWNetAddConnection2W.Call(
+			uintptr(unsafe.Pointer(&res)),
+			uintptr(unsafe.Pointer(passPtr)),
+			uintptr(unsafe.Pointer(userPtr)),
+			connectTemporary)
+		if r != 0 {
+			return windows.Errno(r)
+		}
+		return nil
+	}
+
+	err = call()
+	if errors.Is(err, errSessionCredentialConflict) {
+		// A session with other credentials exists for this share; replace it
+		cancelNasConnection(remote, true)
+		err = call()
+	}
+	return err
+}
+
+// cancelNasConnection removes a single connection (local device or UNC name)
+func cancelNasConnection(name string, force bool) error {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	var f uintptr
+	if force {
+		f = 1
+	}
+	r, _, _ := procWNetCancelConnection2W.Call(uintptr(unsafe.Pointer(namePtr)), 0, f)
+	if r != 0 {
+		return windows.Errno(r)
+	}
+	return nil
+}
+
+// ensureNasSession reconnects to the NAS with stored credentials when the base
+// path is unreachable. Attempts are rate limited so an offline server does not
+// stall every NAS operation.
+func ensureNasSession(config *Config) {
+	if config == nil {
+		return
+	}
+	share := nasShareRoot(config.NasBasePath)
+	if share == "" || checkNasAvailability(config.NasBasePath) {
+		return
+	}
+
+	nasReconnectMu.Lock()
+	if time.Since(nasLastReconnect) < nasReconnectInterval {
+		nasReconnectMu.Unlock()
+		return
+	}
+	nasLastReconnect = time.Now()
+	nasReconnectMu.Unlock()
+
+	user, pass, err := nasCredentials.Read(nasCredentialTarget(nasServer(config.NasBasePath)))
+	if err != nil {
+		return
+	}
+	if connectNas(share, user, pass) == nil {
+		nasHealth.invalidate()
+	}
+}
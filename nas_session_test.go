@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testNasBase = `\\nas.test\fjt\Automations`
+
+type nasConnectCall struct {
+	remote, user, pass string
+}
+
+// fakeNas swaps in the in-memory credential store and records connection attempts
+// instead of opening SMB sessions, then restores the real ones when the test ends
+func fakeNas(t *testing.T) (*memoryCredentialStore, *[]nasConnectCall) {
+	t.Helper()
+	store := newMemoryCredentialStore()
+	var calls []nasConnectCall
+	savedStore, savedConnect := nasCredentials, connectNas
+	nasCredentials = store
+	connectNas = func(remote, user, pass string) error {
+		calls = append(calls, nasConnectCall{remote, user, pass})
+		return nil
+	}
+	nasReconnectMu.Lock()
+	nasLastReconnect = time.Time{}
+	nasReconnectMu.Unlock()
+	t.Cleanup(func() {
+		nasCredentials, connectNas = savedStore, savedConnect
+		nasHealth.invalidate()
+	})
+	return store, &calls
+}
+
+// seedNasHealth makes the next availability check report reachable without probing the network
+func seedNasHealth(path string, reachable bool) {
+	nasHealth.mu.Lock()
+	defer nasHealth.mu.Unlock()
+	nasHealth.entries[strings.ToLower(path)] = nasHealthEntry{
+		status:  NasRootStatus{Path: path, Reachable: reachable},
+		expires: time.Now().Add(time.Minute),
+	}
+}
+
+func TestEnsureNasSessionReconnects(t *testing.T) {
+	store, calls := fakeNas(t)
+	store.Write(nasCredentialTarget("nas.test"), `CORP\svc-setup`, "s3cret")
+	seedNasHealth(testNasBase, false)
+
+	ensureNasSession(&Config{NasBasePath: testNasBase})
+
+	want := nasConnectCall{`\\nas.test\fjt`, `CORP\svc-setup`, "s3cret"}
+	if len(*calls) != 1 || (*calls)[0] != want {
+		t.Fatalf("connect calls = %+v, want [%+v]", *calls, want)
+	}
+	if _, ok := nasHealth.entries[strings.ToLower(testNasBase)]; ok {
+		t.Error("a successful reconnect should drop the cached unreachable status")
+	}
+}
+
+func TestEnsureNasSessionRateLimited(t *testing.T) {
+	store, calls := fakeNas(t)
+	store.Write(nasCredentialTarget("nas.test"), "setup", "s3cret")
+	config := &Config{NasBasePath: testNasBase}
+
+	seedNasHealth(testNasBase, false)
+	ensureNasSession(config)
+	seedNasHealth(testNasBase, false)
+	ensureNasSession(config)
+	if len(*calls) != 1 {
+		t.Fatalf("connected %d times within %v, want once", len(*calls), nasReconnectInterval)
+	}
+
+	nasReconnectMu.Lock()
+	nasLastReconnect = time.Now().Add(-nasReconnectInterval)
+	nasReconnectMu.Unlock()
+	seedNasHealth(testNasBase, false)
+	ensureNasSession(config)
+	if len(*calls) != 2 {
+		t.Errorf("connected %d times after the interval passed, want 2", len(*calls))
+	}
+}
+
+func TestEnsureNasSessionSkips(t *testing.T) {
+	store, calls := fakeNas(t)
+
+	// Unreachable, but nothing stored for the server
+	seedNasHealth(testNasBase, false)
+	ensureNasSession(&Config{NasBasePath: testNasBase})
+
+	// Reachable: stored credentials are not needed
+	store.Write(nasCredentialTarget("nas.test"), "setup", "s3cret")
+	nasReconnectMu.Lock()
+	nasLastReconnect = time.Time{}
+	nasReconnectMu.Unlock()
+	seedNasHealth(testNasBase, true)
+	ensureNasSession(&Config{NasBasePath: testNasBase})
+
+	// No NAS configured
+	ensureNasSession(&Config{})
+	ensureNasSession(nil)
+
+	if len(*calls) != 0 {
+		t.Errorf("connect calls = %+v, want none", *calls)
+	}
+}
+
+func TestMemoryCredentialStore(t *testing.T) {
+	store := newMemoryCredentialStore()
+	target := nasCredentialTarget("nas.test")
+	if _, _, err := store.Read(target); err != errCredentialNotFound {
+		t.Errorf("Read before Write = %v, want errCredentialNotFound", err)
+	}
+	store.Write(target, "setup", "first")
+	store.Write(target, "setup", "second")
+	if user, pass, err := store.Read(target); err != nil || user != "setup" || pass != "second" {
+		t.Errorf("Read = %q, %q, %v; want setup, second", user, pass, err)
+	}
+	if err := store.Delete(target); err != nil {
+		t.Errorf("Delete = %v", err)
+	}
+	if err := store.Delete(target); err != errCredentialNotFound {
+		t.Errorf("second Delete = %v, want errCredentialNotFound", err)
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// NetworkAdapter is one network adapter and its current addressing
+type NetworkAdapter struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	MAC         string   `json:"mac"`
+	Status      string   `json:"status"`
+	LinkSpeed   string   `json:"link_speed"`
+	Physical    bool     `json:"physical"`
+	DHCP        bool     `json:"dhcp"`
+	IPv4        []string `json:"ipv4"` // ip/prefix
+	IPv6        []string `json:"ipv6"`
+	Gateway     string   `json:"gateway"`
+	GatewayV6   string   `json:"gateway_v6"`
+	DNS         []string `json:"dns"`
+}
+
+// NetworkSettings is the configuration ApplyNetworkSettings puts on one adapter
+type NetworkSettings struct {
+	Adapter   string   `json:"adapter"` // "" = first adapter that is Up
+	DHCP      bool     `json:"dhcp"`    // IPv4 from DHCP; IP, Subnet and Gateway must be empty
+	IP        string   `json:"ip"`
+	Subnet    string   `json:"subnet"` // dotted mask ("255.255.252.0") or prefix length ("22", "/22")
+	Gateway   string   `json:"gateway"`
+	DNS       []string `json:"dns"`  // IPv4 and/or IPv6 servers; empty = from DHCP
+	IPv6      string   `json:"ipv6"` // optional static "address/prefix" (prefix defaults to 64)
+	GatewayV6 string   `json:"gateway_v6"`
+	// Seconds to wait for the gateway after applying before rolling back (default 30, -1 disables the check)
+	RollbackSeconds int `json:"rollback_seconds"`
+}
+
+const defaultNetworkRollbackSeconds = 30
+
+// parsePrefixLength accepts a dotted IPv4 mask or a prefix length
+func parsePrefixLength(subnet string) (int, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(subnet), "/")
+	if s == "" {
+		return 0, fmt.Errorf("subnet is required")
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 || n > 32 {
+			return 0, fmt.Errorf("prefix length %d must be 1-32", n)
+		}
+		return n, nil
+	}
+	m, err := netip.ParseAddr(s)
+	if err != nil || !m.Is4() {
+		return 0, fmt.Errorf("subnet %q is neither a mask nor a prefix length", subnet)
+	}
+	bits, err := maskBits(m)
+	if err != nil {
+		return 0, err
+	}
+	if bits == 0 {
+		return 0, fmt.Errorf("subnet mask 0.0.0.0 is not valid for an address")
+	}
+	return bits, nil
+}
+
+// splitAddressList splits DNS input on commas, semicolons and spaces
+func splitAddressList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\t' })
+}
+
+// validate checks IP/mask/gateway consistency and fills in the prefix length.
+// It returns every problem at once, so the form can show them together.
+func (s NetworkSettings) validate() (prefix int, v6 netip.Prefix, problems []string) {
+	bad := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+
+	if s.DHCP {
+		if s.IP != "" || s.Subnet != "" || s.Gateway != "" {
+			bad("ip, subnet and gateway must be empty with DHCP")
+		}
+	} else {
+		ip, err := netip.ParseAddr(strings.TrimSpace(s.IP))
+		switch {
+		case err != nil || !ip.Is4():
+			bad("ip %q is not an IPv4 address", s.IP)
+		case ip.IsLoopback() || ip.IsMulticast() || ip.IsUnspecified() || ip.IsLinkLocalUnicast():
+			bad("ip %s cannot be assigned to an adapter", ip)
+		}
+		if prefix, err = parsePrefixLength(s.Subnet); err != nil {
+			bad("%v", err)
+		}
+
+		if err == nil && ip.Is4() {
+			network := netip.PrefixFrom(ip, prefix).Masked()
+			broadcast := lastAddress(network)
+			if prefix <= 30 && (ip == network.Addr() || ip == broadcast) {
+				bad("ip %s is the network or broadcast address of %s", ip, network)
+			}
+			if s.Gateway != "" {
+				gw, gerr := netip.ParseAddr(strings.TrimSpace(s.Gateway))
+				switch {
+				case gerr != nil || !gw.Is4():
+					bad("gateway %q is not an IPv4 address", s.Gateway)
+				case gw == ip:
+					bad("gateway cannot be the adapter's own address")
+				case !network.Contains(gw):
+					bad("gateway %s is outside %s (check the subnet)", gw, network)
+				case prefix <= 30 && (gw == network.Addr() || gw == broadcast):
+					bad("gateway %s is the network or broadcast address of %s", gw, network)
+				}
+			}
+		}
+	}
+
+	for _, d := range s.DNS {
+		if a, err := netip.ParseAddr(strings.TrimSpace(d)); err != nil || a.IsUnspecified() || a.IsMulticast() {
+			bad("DNS server %q is not a valid address", d)
+		}
+	}
+
+	if s.IPv6 != "" {
+		text := strings.TrimSpace(s.IPv6)
+		if !strings.Contains(text, "/") {
+			text += "/64"
+		}
+		p, err := netip.ParsePrefix(text)
+		switch {
+		case err != nil || !p.Addr().Is6() || p.Addr().Is4In6():
+			bad("ipv6 %q is not an IPv6 address/prefix", s.IPv6)
+		case p.Addr().IsLinkLocalUnicast() || p.Addr().IsLoopback() || p.Addr().IsMulticast():
+			bad("ipv6 %s cannot be assigned statically", p.Addr())
+		default:
+			v6 = p
+		}
+	}
+	if s.GatewayV6 != "" {
+		gw, err := netip.ParseAddr(strings.TrimSpace(s.GatewayV6))
+		switch {
+		case err != nil || !gw.Is6():
+			bad("gateway_v6 %q is not an IPv6 address", s.GatewayV6)
+		case s.IPv6 == "":
+			bad("gateway_v6 needs a static ipv6 address")
+		case !gw.IsLinkLocalUnicast() && v6.IsValid() && !v6.Masked().Contains(gw):
+			bad("gateway_v6 %s is outside %s", gw, v6.Masked())
+		}
+	}
+	return prefix, v6, problems
+}
+
+func lastAddress(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().As4()
+	n := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	n |= ^uint32(0) >> p.Bits()
+	return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+}
+
+func (s NetworkSettings) rollbackWindow() int {
+	if s.RollbackSeconds == 0 {
+		return defaultNetworkRollbackSeconds
+	}
+	return s.RollbackSeconds
+}
+
+// applyScript builds the PowerShell that configures the adapter
+func (s NetworkSettings) applyScript(alias string, prefix int, v6 netip.Prefix) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$alias = %s\n", psQuote(alias))
+	b.WriteString("Remove-NetRoute -InterfaceAlias $alias -DestinationPrefix '0.0.0.0/0' -Confirm:$false -ErrorAction SilentlyContinue\n")
+	if s.DHCP {
+		b.WriteString("Set-NetIPInterface -InterfaceAlias $alias -AddressFamily IPv4 -Dhcp Enabled -ErrorAction Stop\n")
+		b.WriteString("ipconfig /renew $alias | Out-Null\n")
+	} else {
+		b.WriteString("Set-NetIPInterface -InterfaceAlias $alias -AddressFamily IPv4 -Dhcp Disabled -ErrorAction Stop\n")
+		b.WriteString("Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv4 -ErrorAction SilentlyContinue | Remove-NetIPAddress -Confirm:$false -ErrorAction SilentlyContinue\n")
+		fmt.Fprintf(&b, "New-NetIPAddress -InterfaceAlias $alias -IPAddress %s -PrefixLength %d", psQuote(strings.TrimSpace(s.IP)), prefix)
+		if s.Gateway != "" {
+			fmt.Fprintf(&b, " -DefaultGateway %s", psQuote(strings.TrimSpace(s.Gateway)))
+		}
+		b.WriteString(" -ErrorAction Stop | Out-Null\n")
+	}
+
+	if v6.IsValid() {
+		b.WriteString("Remove-NetRoute -InterfaceAlias $alias -DestinationPrefix '::/0' -Protocol NetMgmt -Confirm:$false -ErrorAction SilentlyContinue\n")
+		b.WriteString("Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv6 -PrefixOrigin Manual -ErrorAction SilentlyContinue | Remove-NetIPAddress -Confirm:$false -ErrorAction SilentlyContinue\n")
+		fmt.Fprintf(&b, "New-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv6 -IPAddress %s -PrefixLength %d", psQuote(v6.Addr().String()), v6.Bits())
+		if s.GatewayV6 != "" {
+			fmt.Fprintf(&b, " -DefaultGateway %s", psQuote(strings.TrimSpace(s.GatewayV6)))
+		}
+		b.WriteString(" -ErrorAction Stop | Out-Null\n")
+	}
+
+	if len(s.DNS) == 0 {
+		b.WriteString("Set-DnsClientServerAddress -InterfaceAlias $alias -ResetServerAddresses -ErrorAction Stop\n")
+	} else {
+		quoted := make([]string, len(s.DNS))
+		for i, d := range s.DNS {
+			quoted[i] = psQuote(strings.TrimSpace(d))
+		}
+		fmt.Fprintf(&b, "Set-DnsClientServerAddress -InterfaceAlias $alias -ServerAddresses (%s) -ErrorAction Stop\n", strings.Join(quoted, ","))
+	}
+	return b.String()
+}
+
+// waitForGateway reports whether the adapter reaches its IPv4 gateway within the window.
+// A gateway that drops ping still counts when ARP resolves it.
+func waitForGateway(alias string, seconds int) (string, bool) {
+	ps := fmt.Sprintf(`
+		$alias = %s
+		$deadline = (Get-Date).AddSeconds(%d)
+		do {
+			$gw = (Get-NetRoute -InterfaceAlias $alias -DestinationPrefix '0.0.0.0/0' -ErrorAction SilentlyContinue | Select-Object -First 1).NextHop
+			$ip = Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv4 -ErrorAction SilentlyContinue | Where-Object { $_.AddressState -eq 'Preferred' -and $_.IPAddress -notlike '169.254.*' } | Select-Object -First 1
+			if ($gw -and $ip) {
+				if (Test-Connection -ComputerName $gw -Count 1 -Quiet -ErrorAction SilentlyContinue) { "ok $gw"; return }
+				$n = Get-NetNeighbor -InterfaceAlias $alias -IPAddress $gw -ErrorAction SilentlyContinue
+				if ($n -and $n.State -eq 'Reachable') { "ok $gw"; return }
+			}
+			Start-Sleep -Seconds 2
+		} while ((Get-Date) -lt $deadline)
+		if (-not $ip) { 'no usable IPv4 address' } elseif (-not $gw) { 'no default gateway' } else { "gateway $gw unreachable" }
+	`, psQuote(alias), seconds)
+	out, err := runPowerShell(ps)
+	if err != nil {
+		return err.Error() + ": " + out, false
+	}
+	return strings.TrimPrefix(out, "ok "), strings.HasPrefix(out, "ok ")
+}
+
+func (a *App) applyNetworkSettings(x *executor, s NetworkSettings) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required for network changes."); !ok {
+		return msg
+	}
+	prefix, v6, problems := s.validate()
+	if len(problems) > 0 {
+		return "❌ Error: Invalid network settings - " + strings.Join(problems, "; ")
+	}
+
+	// Resolve the adapter now, so the change, the check and a rollback all hit the same one
+	before, err := snapshotNetwork(s.Adapter)
+	if err != nil {
+		return "❌ Error: Network adapter not found - " + err.Error()
+	}
+
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", s.applyScript(before.Adapter, prefix, v6))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if output, err := x.output(cmd); err != nil {
+		if restoreErr := before.restore(); restoreErr != nil {
+			return "❌ Error: Network change failed (" + strings.TrimSpace(string(output)) + ") and restoring the previous settings failed: " + restoreErr.Error()
+		}
+		return "❌ Error: Network change failed, previous settings restored: " + strings.TrimSpace(string(output)) + " " + err.Error()
+	}
+
+	mode := "DHCP"
+	if !s.DHCP {
+		mode = fmt.Sprintf("%s/%d", strings.TrimSpace(s.IP), prefix)
+	}
+	window := s.rollbackWindow()
+	if window < 0 || (!s.DHCP && s.Gateway == "") {
+		return "✅ Success: " + before.Adapter + " set to " + mode + "."
+	}
+	x.record("check", fmt.Sprintf("Wait up to %ds for %s to reach its gateway; restore the previous settings if it does not", window, before.Adapter))
+	if x.dryRun {
+		return "✅ Success: " + before.Adapter + " set to " + mode + "."
+	}
+	detail, ok := waitForGateway(before.Adapter, window)
+	if ok {
+		return "✅ Success: " + before.Adapter + " set to " + mode + " (gateway " + detail + " reachable)."
+	}
+	if err := before.restore(); err != nil {
+		return "❌ Error: " + detail + " after the change, and restoring the previous settings failed: " + err.Error()
+	}
+	return "❌ Error: " + detail + " after the change; previous settings on " + before.Adapter + " restored."
+}
+
+func networkSettingsParams(s NetworkSettings) map[string]string {
+	return map[string]string{
+		"adapter":    s.Adapter,
+		"dhcp":       fmt.Sprint(s.DHCP),
+		"ip":         s.IP,
+		"subnet":     s.Subnet,
+		"gateway":    s.Gateway,
+		"dns":        strings.Join(s.DNS, ","),
+		"ipv6":       s.IPv6,
+		"gateway_v6": s.GatewayV6,
+	}
+}
+
+func networkSettingsFromParams(p map[string]string) NetworkSettings {
+	s := NetworkSettings{
+		Adapter:   p["adapter"],
+		DHCP:      p["dhcp"] == "true",
+		IP:        p["ip"],
+		Subnet:    p["subnet"],
+		Gateway:   p["gateway"],
+		DNS:       splitAddressList(p["dns"]),
+		IPv6:      p["ipv6"],
+		GatewayV6: p["gateway_v6"],
+	}
+	s.RollbackSeconds, _ = strconv.Atoi(p["rollback_seconds"])
+	return s
+}
+
+// ApplyNetworkSettings configures one adapter (static or DHCP, IPv6, DNS) and rolls back if the gateway is lost
+func (a *App) ApplyNetworkSettings(settings NetworkSettings) (result string) {
+	scope := networkChangeScope
+	scope.NetworkAdapter = settings.Adapter
+	defer beginAudit("ApplyNetworkSettings", networkSettingsParams(settings), networkAuditProbe).snapshot(scope).finish(&result)
+	return a.applyNetworkSettings(liveExecutor(), settings)
+}
+
+// SetStaticIP sets a static IPv4 address on the first active adapter. subnet may be a mask or a prefix length,
+// dns a comma-separated list.
+func (a *App) SetStaticIP(ip, subnet, gateway, dns string) (result string) {
+	defer beginAudit("SetStaticIP", map[string]string{"ip": ip, "subnet": subnet, "gateway": gateway, "dns": dns}, networkAuditProbe).snapshot(networkChangeScope).finish(&result)
+	return a.applyNetworkSettings(liveExecutor(), NetworkSettings{IP: ip, Subnet: subnet, Gateway: gateway, DNS: splitAddressList(dns)})
+}
+
+// SetDHCP reverts an adapter ("" = first active) to DHCP for its address and DNS
+func (a *App) SetDHCP(adapter string) (result string) {
+	scope := networkChangeScope
+	scope.NetworkAdapter = adapter
+	defer beginAudit("SetDHCP", map[string]string{"adapter": adapter}, networkAuditProbe).snapshot(scope).finish(&result)
+	return a.applyNetworkSettings(liveExecutor(), NetworkSettings{Adapter: adapter, DHCP: true})
+}
+
+// ValidateNetworkSettings returns every problem with the settings (empty when they can be applied)
+func (a *App) ValidateNetworkSettings(settings NetworkSettings) []string {
+	_, _, problems := settings.validate()
+	if problems == nil {
+		problems = []string{}
+	}
+	return problems
+}
+
+// GetNetworkAdapters lists the adapters with their current addressing
+func (a *App) GetNetworkAdapters() []NetworkAdapter {
+	return listNetworkAdapters()
+}
+
+func listNetworkAdapters() []NetworkAdapter {
+	ps := `Get-NetAdapter | ForEach-Object {
+		$alias = $_.Name
+		$if = Get-NetIPInterface -InterfaceAlias $alias -AddressFamily IPv4 -ErrorAction SilentlyContinue
+		[PSCustomObject]@{
+			name        = $alias
+			description = [string]$_.InterfaceDescription
+			mac         = [string]$_.MacAddress
+			status      = [string]$_.Status
+			link_speed  = [string]$_.LinkSpeed
+			physical    = [bool]$_.HardwareInterface
+			dhcp        = ($if.Dhcp -eq 'Enabled')
+			ipv4        = @(Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv4 -ErrorAction SilentlyContinue | ForEach-Object { "$($_.IPAddress)/$($_.PrefixLength)" })
+			ipv6        = @(Get-NetIPAddress -InterfaceAlias $alias -AddressFamily IPv6 -ErrorAction SilentlyContinue | ForEach-Object { "$($_.IPAddress)/$($_.PrefixLength)" })
+			gateway     = [string](Get-NetRoute -InterfaceAlias $alias -DestinationPrefix '0.0.0.0/0' -ErrorAction SilentlyContinue | Select-Object -First 1).NextHop
+			gateway_v6  = [string](Get-NetRoute -InterfaceAlias $alias -DestinationPrefix '::/0' -ErrorAction SilentlyContinue | Select-Object -First 1).NextHop
+			dns         = @((Get-DnsClientServerAddress -InterfaceAlias $alias -ErrorAction SilentlyContinue).ServerAddresses)
+		}
+	} | ConvertTo-Json -Compress -Depth 3`
+	out, err := runPowerShell(ps)
+	adapters := []NetworkAdapter{}
+	if err != nil || out == "" {
+		return adapters
+	}
+	if strings.HasPrefix(out, "{") {
+		out = "[" + out + "]"
+	}
+	json.Unmarshal([]byte(out), &adapters)
+	return adapters
+}
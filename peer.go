@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultPeerListen = ":8765"
+
+// How long to wait for a peer's manifest before moving on to the next source
+const peerManifestTimeout = 3 * time.Second
+
+// peerServer serves a mirror folder (installers + manifest.json) to other PCs on the LAN
+type peerServer struct {
+	mu     sync.Mutex
+	server *http.Server
+	dir    string
+	addr   string
+}
+
+var peer = &peerServer{}
+
+// newPeerHandler exposes /manifest.json and /files/<file>. Only files listed in
+// the manifest are served, so nothing else in the folder leaks onto the network.
+func newPeerHandler(dir string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(loadMirrorManifest(dir))
+	})
+
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/files/")
+		var entry *MirrorEntry
+		for _, e := range loadMirrorManifest(dir).Files {
+			if strings.EqualFold(filepath.ToSlash(e.File), name) {
+				entry = &e
+				break
+			}
+		}
+		if entry == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		f, err := os.Open(filepath.Join(dir, entry.File))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-Content-SHA256", entry.SHA256)
+		// ServeContent handles Range requests, so peers can resume downloads
+		http.ServeContent(w, r, filepath.Base(entry.File), info.ModTime(), f)
+	})
+
+	return mux
+}
+
+// start begins serving dir on addr in the background
+func (p *peerServer) start(dir, addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.server != nil {
+		return fmt.Errorf("already serving %s on %s", p.dir, p.addr)
+	}
+	if !fileExists(filepath.Join(dir, mirrorManifestName)) {
+		return fmt.Errorf("no %s in %s (run the mirror first)", mirrorManifestName, dir)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: newPeerHandler(dir), ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+
+	p.server, p.dir, p.addr = srv, dir, ln.Addr().String()
+	return nil
+}
+
+func (p *peerServer) stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.server == nil {
+		return fmt.Errorf("not running")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := p.server.Shutdown(ctx)
+	p.server = nil
+	return err
+}
+
+// peerServeOptions returns the folder and listen address for the peer server
+func (c *Config) peerServeOptions() (string, string) {
+	dir := c.PeerServeDir
+	if dir == "" {
+		dir = c.MirrorPath
+	}
+	addr := c.PeerListen
+	if addr == "" {
+		addr = defaultPeerListen
+	}
+	return dir, addr
+}
+
+// StartPeerServer shares this PC's installer mirror with other PCs over HTTP
+func (a *App) StartPeerServer() string {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	dir, addr := config.peerServeOptions()
+	if dir == "" {
+		return "❌ Error: Configure mirror_path or peer_serve_dir first."
+	}
+	if err := peer.start(dir, addr); err != nil {
+		return "❌ Error: Peer server failed - " + err.Error()
+	}
+	return "✅ Success: Serving " + dir + " on " + peer.addr
+}
+
+// StopPeerServer stops sharing the installer mirror
+func (a *App) StopPeerServer() string {
+	if err := peer.stop(); err != nil {
+		return "ℹ️ Peer server " + err.Error()
+	}
+	return "✅ Success: Peer server stopped."
+}
+
+// GetPeerServerStatus describes whether the peer server is running
+func (a *App) GetPeerServerStatus() string {
+	peer.mu.Lock()
+	defer peer.mu.Unlock()
+	if peer.server == nil {
+		return "⏹ Peer server stopped"
+	}
+	return "📡 Serving " + peer.dir + " on " + peer.addr
+}
+
+// --- Peer source (client side) ---
+
+// fetchFromPeers downloads a payload from the first configured peer that
+// lists it in its manifest, verifying size and SHA-256. It returns the peer used.
+func fetchFromPeers(a *App, config *Config, sw Software, destPath string) (string, error) {
+	if len(config.PeerSources) == 0 {
+		return "", fmt.Errorf("no peers configured")
+	}
+
+	var errs []string
+	for _, base := range config.PeerSources {
+		base = strings.TrimRight(base, "/")
+		entry, err := peerManifestEntry(base, sw.NasPath)
+		if err != nil {
+			errs = append(errs, base+": "+err.Error())
+			continue
+		}
+
+		fileURL := base + "/files/" + peerFilePath(entry.File)
+		if err := downloadFile(a, fileURL, destPath); err != nil {
+			errs = append(errs, base+": "+err.Error())
+			continue
+		}
+
+		info, err := os.Stat(destPath)
+		if err != nil || info.Size() != entry.Size {
+			errs = append(errs, base+": size mismatch")
+			continue
+		}
+		if hash, err := sha256File(destPath); err != nil || !strings.EqualFold(hash, entry.SHA256) {
+			errs = append(errs, base+": hash mismatch")
+			continue
+		}
+		return base, nil
+	}
+	return "", fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// peerManifestEntry fetches a peer's manifest and looks up file
+func peerManifestEntry(base, file string) (*MirrorEntry, error) {
+	client := &http.Client{Timeout: peerManifestTimeout}
+	resp, err := client.Get(base + "/manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("manifest: %s", resp.Status)
+	}
+
+	var m MirrorManifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	for _, e := range m.Files {
+		if strings.EqualFold(filepath.ToSlash(e.File), filepath.ToSlash(file)) && e.SHA256 != "" {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not in manifest", file)
+}
+
+// peerFilePath escapes each segment of a manifest file path for use in a URL
+func peerFilePath(file string) string {
+	parts := strings.Split(filepath.ToSlash(file), "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,551 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// PersonalizationProfile is the desktop look of a PC. Set at the top level of config.json
+// and overridden per profile; empty fields inherit.
+type PersonalizationProfile struct {
+	Wallpaper      string            `json:"wallpaper,omitempty"`       // http(s) URL, UNC/absolute path, or a file in the app folder
+	WallpaperStyle string            `json:"wallpaper_style,omitempty"` // fill, fit, stretch, center, tile, span
+	LockScreen     string            `json:"lock_screen,omitempty"`     // same forms as wallpaper
+	DesktopIcons   map[string]bool   `json:"desktop_icons,omitempty"`   // this_pc, recycle_bin, network, user_files, control_panel
+	Shortcuts      []CompanyShortcut `json:"shortcuts,omitempty"`
+}
+
+// CompanyShortcut is a desktop or Start menu shortcut
+type CompanyShortcut struct {
+	Name      string `json:"name"`
+	Target    string `json:"target"` // program, document, folder or http(s) URL
+	Arguments string `json:"arguments,omitempty"`
+	Icon      string `json:"icon,omitempty"`      // "path,index"
+	Location  string `json:"location,omitempty"`  // desktop (default) or start_menu
+	Folder    string `json:"folder,omitempty"`    // Start menu subfolder
+	AllUsers  bool   `json:"all_users,omitempty"` // public desktop / all users' Start menu
+}
+
+const (
+	desktopKey      = `Control Panel\Desktop`
+	desktopIconsKey = `Software\Microsoft\Windows\CurrentVersion\Explorer\HideDesktopIcons\NewStartPanel`
+	lockScreenKey   = `SOFTWARE\Microsoft\Windows\CurrentVersion\PersonalizationCSP`
+	lockPolicyKey   = `SOFTWARE\Policies\Microsoft\Windows\Personalization`
+	shellFoldersKey = `Software\Microsoft\Windows\CurrentVersion\Explorer\Shell Folders`
+
+	maxImageBytes = 50 << 20
+)
+
+// WallpaperStyle and TileWallpaper values for each style
+var wallpaperStyles = map[string][2]string{
+	"fill":    {"10", "0"},
+	"fit":     {"6", "0"},
+	"stretch": {"2", "0"},
+	"center":  {"0", "0"},
+	"tile":    {"0", "1"},
+	"span":    {"22", "0"},
+}
+
+// Desktop icons by name; the CLSID is the value name under NewStartPanel (0 = shown, 1 = hidden)
+var desktopIcons = map[string]string{
+	"this_pc":       "{20D04FE0-3AEA-1069-A2D8-08002B30309D}",
+	"recycle_bin":   "{645FF040-5081-101B-9F08-00AA002F954E}",
+	"network":       "{F02C1A0D-BE21-4350-88B0-7367FC96EF3C}",
+	"user_files":    "{59031a47-3f72-44a7-89c5-5595fe6b30ee}",
+	"control_panel": "{5399E694-6CE5-4D6C-8FCE-1D8870FDCBA0}",
+}
+
+// Image types Windows accepts for both the wallpaper and the lock screen
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/bmp":  ".bmp",
+}
+
+var (
+	modshell32         = windows.NewLazySystemDLL("shell32.dll")
+	procSHChangeNotify = modshell32.NewProc("SHChangeNotify")
+)
+
+const shcneAssocChanged = 0x08000000
+
+// overlay returns p with every field set in o replacing the inherited one; icons are merged
+func (p PersonalizationProfile) overlay(o *PersonalizationProfile) PersonalizationProfile {
+	if o == nil {
+		return p
+	}
+	if o.Wallpaper != "" {
+		p.Wallpaper = o.Wallpaper
+	}
+	if o.WallpaperStyle != "" {
+		p.WallpaperStyle = o.WallpaperStyle
+	}
+	if o.LockScreen != "" {
+		p.LockScreen = o.LockScreen
+	}
+	if len(o.DesktopIcons) > 0 {
+		icons := map[string]bool{}
+		for k, v := range p.DesktopIcons {
+			icons[k] = v
+		}
+		for k, v := range o.DesktopIcons {
+			icons[k] = v
+		}
+		p.DesktopIcons = icons
+	}
+	if len(o.Shortcuts) > 0 {
+		p.Shortcuts = o.Shortcuts
+	}
+	return p
+}
+
+// personalization resolves the settings for a profile ("" = the config-wide settings)
+func (c *Config) personalization(profile string) (PersonalizationProfile, error) {
+	var p PersonalizationProfile
+	if c == nil {
+		return p, nil
+	}
+	p = p.overlay(c.Personalization)
+	if profile != "" {
+		sp, ok := c.profile(profile)
+		if !ok {
+			return p, fmt.Errorf("profile %s is not declared in config.json", profile)
+		}
+		p = p.overlay(sp.Personalization)
+	}
+	return p, nil
+}
+
+// validate reports every problem before anything is changed
+func (p PersonalizationProfile) validate() []string {
+	var problems []string
+	if p.WallpaperStyle != "" {
+		if _, ok := wallpaperStyles[strings.ToLower(p.WallpaperStyle)]; !ok {
+			problems = append(problems, "wallpaper style "+p.WallpaperStyle+" must be one of "+strings.Join(sortedKeys(wallpaperStyles), ", "))
+		}
+	}
+	for name := range p.DesktopIcons {
+		if _, ok := desktopIcons[strings.ToLower(name)]; !ok {
+			problems = append(problems, "desktop icon "+name+" must be one of "+strings.Join(sortedKeys(desktopIcons), ", "))
+		}
+	}
+	for _, s := range p.Shortcuts {
+		if _, err := shortcutPath(s); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkImage tells what kind of image data is, from its first bytes, and rejects anything
+// that is not a JPEG, PNG or BMP. A declared Content-Type must agree that it is an image.
+func checkImage(declared string, head []byte) (string, error) {
+	if declared != "" {
+		mediaType, _, _ := mime.ParseMediaType(declared)
+		if !strings.HasPrefix(mediaType, "image/") && mediaType != "application/octet-stream" {
+			return "", fmt.Errorf("the server sent %s, not an image", mediaType)
+		}
+	}
+	sniffed := http.DetectContentType(head)
+	ext, ok := imageExtensions[sniffed]
+	if !ok {
+		return "", fmt.Errorf("the file is %s, not a JPEG, PNG or BMP image", sniffed)
+	}
+	return ext, nil
+}
+
+// resolveImagePath finds a local image: absolute and UNC paths as given, anything else in the app folder
+func resolveImagePath(src string) string {
+	if filepath.IsAbs(src) || strings.HasPrefix(src, `\\`) {
+		return src
+	}
+	if exe, err := os.Executable(); err == nil {
+		if p := filepath.Join(filepath.Dir(exe), src); fileExists(p) {
+			return p
+		}
+	}
+	return src
+}
+
+// fetchImage downloads or copies an image into the app data folder after checking it is one.
+// The file is named after its content, so earlier wallpapers stay on disk for rollback.
+func fetchImage(x *executor, src, kind string) (string, error) {
+	dir := filepath.Join(appDataDir(), "personalization")
+	remote := isURL(src)
+	if remote && x.dryRun {
+		x.record("fetch", "Download "+src+" (must be a JPEG, PNG or BMP image) -> "+dir)
+		return filepath.Join(dir, kind+filepath.Ext(src)), nil
+	}
+
+	var (
+		data     []byte
+		declared string
+		err      error
+	)
+	if remote {
+		client := &http.Client{Timeout: 60 * time.Second}
+		resp, err := client.Get(src)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s returned %s", src, resp.Status)
+		}
+		declared = resp.Header.Get("Content-Type")
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+		if err != nil {
+			return "", err
+		}
+	} else {
+		src = resolveImagePath(src)
+		if data, err = os.ReadFile(src); err != nil {
+			return "", err
+		}
+	}
+	if len(data) > maxImageBytes {
+		return "", fmt.Errorf("%s is larger than %d MB", src, maxImageBytes>>20)
+	}
+	ext, err := checkImage(declared, data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", src, err)
+	}
+	sum := sha256.Sum256(data)
+	dest := filepath.Join(dir, fmt.Sprintf("%s-%x%s", kind, sum[:6], ext))
+	if !remote {
+		x.record("fetch", "Copy "+src+" -> "+dest)
+	}
+	if fileExists(dest) {
+		return dest, nil
+	}
+	return dest, x.writeFile(dest, data)
+}
+
+// applyWallpaper sets the style (if any) and then the image, which makes Explorer reload both
+func applyWallpaper(x *executor, path, style string) error {
+	if style != "" {
+		values := wallpaperStyles[strings.ToLower(style)]
+		if err := x.regSet(registry.CURRENT_USER, desktopKey, "WallpaperStyle", values[0]); err != nil {
+			return err
+		}
+		if err := x.regSet(registry.CURRENT_USER, desktopKey, "TileWallpaper", values[1]); err != nil {
+			return err
+		}
+	}
+	x.record("api", "SystemParametersInfo(SPI_SETDESKWALLPAPER, "+path+")")
+	if x.dryRun {
+		return nil
+	}
+	return applyDesktopWallpaper(path)
+}
+
+// Editions that apply a forced lock screen image. Home and Pro ignore both the
+// "Force a specific default lock screen image" policy and the PersonalizationCSP values.
+var lockScreenEditions = []string{"Enterprise", "Education", "Server"}
+
+// checkLockScreenEdition fails on editions where setting the lock screen would have no effect
+func checkLockScreenEdition() error {
+	edition, _ := readRegValue(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, "EditionID")
+	for _, e := range lockScreenEditions {
+		if strings.Contains(edition, e) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Windows edition %s does not support a forced lock screen image (Enterprise, Education and Server only)", onOff(edition != "", edition, "(unknown)"))
+}
+
+// setLockScreen writes the Group Policy value and the PersonalizationCSP values; Windows
+// honours them on the editions in lockScreenEditions only, so check those first
+func setLockScreen(x *executor, path string) error {
+	if err := x.regSet(registry.LOCAL_MACHINE, lockPolicyKey, "LockScreenImage", path); err != nil {
+		return err
+	}
+	if err := x.regSet(registry.LOCAL_MACHINE, lockScreenKey, "LockScreenImagePath", path); err != nil {
+		return err
+	}
+	if err := x.regSet(registry.LOCAL_MACHINE, lockScreenKey, "LockScreenImageUrl", path); err != nil {
+		return err
+	}
+	return x.regSet(registry.LOCAL_MACHINE, lockScreenKey, "LockScreenImageStatus", uint32(1))
+}
+
+// setDesktopIcons shows or hides desktop icons and asks Explorer to redraw
+func setDesktopIcons(x *executor, icons map[string]bool) error {
+	for _, name := range sortedKeys(icons) {
+		clsid, ok := desktopIcons[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("unknown desktop icon %s", name)
+		}
+		hidden := uint32(1)
+		if icons[name] {
+			hidden = 0
+		}
+		if err := x.regSet(registry.CURRENT_USER, desktopIconsKey, clsid, hidden); err != nil {
+			return err
+		}
+	}
+	x.record("api", "SHChangeNotify(SHCNE_ASSOCCHANGED) to refresh the desktop")
+	if !x.dryRun {
+		procSHChangeNotify.Call(shcneAssocChanged, 0, 0, 0)
+	}
+	return nil
+}
+
+func isURL(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// shortcutPath is where a shortcut is written: .url for web links, .lnk for everything else
+func shortcutPath(s CompanyShortcut) (string, error) {
+	if strings.TrimSpace(s.Name) == "" || strings.ContainsAny(s.Name, `<>:"/\|?*`) {
+		return "", fmt.Errorf("shortcut name %q is not a valid file name", s.Name)
+	}
+	if strings.TrimSpace(s.Target) == "" {
+		return "", fmt.Errorf("shortcut %s has no target", s.Name)
+	}
+	if strings.Contains(s.Folder, "..") || strings.ContainsAny(s.Folder, `<>:"|?*`) {
+		return "", fmt.Errorf("shortcut %s: folder %q is not valid", s.Name, s.Folder)
+	}
+	var dir string
+	switch strings.ToLower(s.Location) {
+	case "", "desktop":
+		if s.AllUsers {
+			dir = filepath.Join(os.Getenv("PUBLIC"), "Desktop")
+		} else {
+			dir, _ = readRegValue(registry.CURRENT_USER, shellFoldersKey, "Desktop")
+		}
+	case "start_menu":
+		if s.AllUsers {
+			dir = filepath.Join(os.Getenv("ProgramData"), `Microsoft\Windows\Start Menu\Programs`)
+		} else {
+			dir, _ = readRegValue(registry.CURRENT_USER, shellFoldersKey, "Programs")
+		}
+		dir = filepath.Join(dir, s.Folder)
+	default:
+		return "", fmt.Errorf("shortcut %s: location %s must be desktop or start_menu", s.Name, s.Location)
+	}
+	if dir == "" {
+		return "", fmt.Errorf("shortcut %s: cannot find the %s folder", s.Name, onOff(s.Location == "start_menu", "Start menu", "desktop"))
+	}
+	return filepath.Join(dir, s.Name+onOff(isURL(s.Target), ".url", ".lnk")), nil
+}
+
+// createShortcut writes or replaces one shortcut
+func createShortcut(x *executor, s CompanyShortcut) error {
+	path, err := shortcutPath(s)
+	if err != nil {
+		return err
+	}
+	if isURL(s.Target) {
+		content := "[InternetShortcut]\r\nURL=" + s.Target + "\r\n"
+		if icon, index, ok := strings.Cut(s.Icon, ","); ok {
+			content += "IconFile=" + icon + "\r\nIconIndex=" + index + "\r\n"
+		} else if s.Icon != "" {
+			content += "IconFile=" + s.Icon + "\r\nIconIndex=0\r\n"
+		}
+		return x.writeFile(path, []byte(content))
+	}
+
+	x.mkdirAll(filepath.Dir(path))
+	ps := fmt.Sprintf(`
+		$s = (New-Object -ComObject WScript.Shell).CreateShortcut(%s)
+		$s.TargetPath = %s
+		$s.Arguments = %s
+		$s.WorkingDirectory = %s
+		if (%s) { $s.IconLocation = %s }
+		$s.Save()
+	`, psQuote(path), psQuote(s.Target), psQuote(s.Arguments), psQuote(filepath.Dir(s.Target)), psQuote(s.Icon), psQuote(s.Icon))
+	if out, err := x.output(psCommand(ps)); err != nil {
+		return fmt.Errorf("cannot create %s: %s", path, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// personalizationChangeScope covers what p touches, including the shortcut files it writes
+func personalizationChangeScope(p PersonalizationProfile) changeScope {
+	scope := changeScope{
+		Registry: append(append([]regRef{}, wallpaperChangeScope.Registry...),
+			regRef{registry.LOCAL_MACHINE, lockPolicyKey, "LockScreenImage"},
+			regRef{registry.LOCAL_MACHINE, lockScreenKey, "LockScreenImagePath"},
+			regRef{registry.LOCAL_MACHINE, lockScreenKey, "LockScreenImageUrl"},
+			regRef{registry.LOCAL_MACHINE, lockScreenKey, "LockScreenImageStatus"},
+			regRef{registry.CURRENT_USER, desktopIconsKey, "*"},
+		),
+		Wallpaper: p.Wallpaper != "",
+	}
+	for _, s := range p.Shortcuts {
+		if path, err := shortcutPath(s); err == nil {
+			scope.Files = append(scope.Files, path)
+		}
+	}
+	return scope
+}
+
+// applyPersonalization applies everything set in p, stopping at the first failure
+func applyPersonalization(x *executor, p PersonalizationProfile) string {
+	if problems := p.validate(); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	needsAdmin := p.LockScreen != ""
+	for _, s := range p.Shortcuts {
+		needsAdmin = needsAdmin || s.AllUsers
+	}
+	if needsAdmin {
+		if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required for the lock screen and all-users shortcuts."); !ok {
+			return msg
+		}
+	}
+
+	var done []string
+	fail := func(what string, err error) string {
+		msg := "❌ Error: " + what + ": " + err.Error()
+		if len(done) > 0 {
+			msg += " (already applied: " + strings.Join(done, ", ") + ")"
+		}
+		return msg
+	}
+	if p.Wallpaper != "" {
+		path, err := fetchImage(x, p.Wallpaper, "wallpaper")
+		if err != nil {
+			return fail("Wallpaper", err)
+		}
+		if err := applyWallpaper(x, path, p.WallpaperStyle); err != nil {
+			return fail("Wallpaper", err)
+		}
+		done = append(done, "wallpaper"+onOff(p.WallpaperStyle != "", " ("+strings.ToLower(p.WallpaperStyle)+")", ""))
+	}
+	if p.LockScreen != "" {
+		if err := checkLockScreenEdition(); err != nil {
+			return fail("Lock screen", err)
+		}
+		path, err := fetchImage(x, p.LockScreen, "lockscreen")
+		if err != nil {
+			return fail("Lock screen", err)
+		}
+		if err := setLockScreen(x, path); err != nil {
+			return fail("Lock screen", err)
+		}
+		done = append(done, "lock screen")
+	}
+	if len(p.DesktopIcons) > 0 {
+		if err := setDesktopIcons(x, p.DesktopIcons); err != nil {
+			return fail("Desktop icons", err)
+		}
+		done = append(done, fmt.Sprintf("%d desktop icon(s)", len(p.DesktopIcons)))
+	}
+	for i, s := range p.Shortcuts {
+		if err := createShortcut(x, s); err != nil {
+			return fail("Shortcut "+s.Name, err)
+		}
+		if i == len(p.Shortcuts)-1 {
+			done = append(done, fmt.Sprintf("%d shortcut(s)", len(p.Shortcuts)))
+		}
+	}
+	if len(done) == 0 {
+		return "ℹ️ Nothing to personalize: config.json has no personalization settings."
+	}
+	return "✅ Success: Personalization applied: " + strings.Join(done, ", ") + "."
+}
+
+// ApplyPersonalization applies a profile's wallpaper, lock screen, desktop icons and shortcuts ("" = config-wide)
+func (a *App) ApplyPersonalization(profile string) (result string) {
+	config, _ := loadConfig("config.json")
+	p, _ := config.personalization(profile)
+	defer beginAudit("ApplyPersonalization", map[string]string{"profile": profile}, personalizationAuditProbe).snapshot(personalizationChangeScope(p)).finish(&result)
+	return a.applyPersonalization(liveExecutor(), profile)
+}
+
+func (a *App) applyPersonalization(x *executor, profile string) string {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	p, err := config.personalization(profile)
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return applyPersonalization(x, p)
+}
+
+// SetLockScreenImage sets the lock-screen image from a URL or file
+func (a *App) SetLockScreenImage(source string) (result string) {
+	defer beginAudit("SetLockScreenImage", map[string]string{"source": source}, personalizationAuditProbe).snapshot(personalizationChangeScope(PersonalizationProfile{})).finish(&result)
+	return a.setLockScreenImage(liveExecutor(), source)
+}
+
+func (a *App) setLockScreenImage(x *executor, source string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to set the lock screen."); !ok {
+		return msg
+	}
+	if strings.TrimSpace(source) == "" {
+		return "❌ Error: Enter an image URL or path."
+	}
+	if err := checkLockScreenEdition(); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	path, err := fetchImage(x, source, "lockscreen")
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if err := setLockScreen(x, path); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return "✅ Success: Lock screen image set. It shows from the next lock."
+}
+
+// SetDesktopIcons shows (true) or hides (false) the named desktop icons
+func (a *App) SetDesktopIcons(icons map[string]bool) (result string) {
+	params := map[string]string{}
+	for k, v := range icons {
+		params[k] = onOff(v, "show", "hide")
+	}
+	defer beginAudit("SetDesktopIcons", params, personalizationAuditProbe).snapshot(personalizationChangeScope(PersonalizationProfile{})).finish(&result)
+	return a.setDesktopIcons(liveExecutor(), icons)
+}
+
+func (a *App) setDesktopIcons(x *executor, icons map[string]bool) string {
+	if problems := (PersonalizationProfile{DesktopIcons: icons}).validate(); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	if err := setDesktopIcons(x, icons); err != nil {
+		return "Registry Error: " + err.Error()
+	}
+	var shown, hidden []string
+	for _, name := range sortedKeys(icons) {
+		if icons[name] {
+			shown = append(shown, name)
+		} else {
+			hidden = append(hidden, name)
+		}
+	}
+	return "✅ Success: Desktop icons shown: " + listOrNone(shown) + "; hidden: " + listOrNone(hidden) + "."
+}
+
+// desktopIconParams reads plan params such as this_pc=show recycle_bin=hide
+func desktopIconParams(p map[string]string) map[string]bool {
+	icons := map[string]bool{}
+	for k, v := range p {
+		icons[k] = v == "show" || v == "true" || v == "1"
+	}
+	return icons
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// PlanStep is one side effect an operation performs (or, in a dry run, would perform)
+type PlanStep struct {
+	Kind   string `json:"kind"` // command, powershell, registry, fetch, file, source, install, note
+	Detail string `json:"detail"`
+}
+
+// OperationPlan is the result of a dry run
+type OperationPlan struct {
+	Operation string            `json:"operation"`
+	Params    map[string]string `json:"params"`
+	Steps     []PlanStep        `json:"steps"`
+	Outcome   string            `json:"outcome"` // status text the operation would return
+}
+
+// executor carries out an operation's side effects. In a dry run every step is
+// recorded and nothing is executed; read-only checks (file exists, NAS probe,
+// peer manifests) still run so the selected sources are real.
+type executor struct {
+	dryRun bool
+	steps  []PlanStep
+}
+
+func liveExecutor() *executor {
+	return &executor{}
+}
+
+func (x *executor) record(kind, detail string) {
+	x.steps = append(x.steps, PlanStep{Kind: kind, Detail: detail})
+}
+
+// source records where an installer was found
+func (x *executor) source(detail string) {
+	x.record("source", detail)
+}
+
+// requireAdmin fails the operation when not elevated; a dry run only notes it
+func (x *executor) requireAdmin(msg string) (string, bool) {
+	if isAdmin() {
+		return "", true
+	}
+	if x.dryRun {
+		x.record("note", "Requires administrator privileges (this session is not elevated)")
+		return "", true
+	}
+	return msg, false
+}
+
+// output runs cmd and returns its combined output
+func (x *executor) output(cmd *exec.Cmd) ([]byte, error) {
+	x.record(describeCommand(cmd))
+	if x.dryRun {
+		return nil, nil
+	}
+	return cmd.CombinedOutput()
+}
+
+func (x *executor) run(cmd *exec.Cmd) error {
+	x.record(describeCommand(cmd))
+	if x.dryRun {
+		return nil
+	}
+	return cmd.Run()
+}
+
+// start launches cmd without waiting for it
+func (x *executor) start(cmd *exec.Cmd) error {
+	kind, detail := describeCommand(cmd)
+	x.record(kind, detail+" (not awaited)")
+	if x.dryRun {
+		return nil
+	}
+	return cmd.Start()
+}
+
+func (x *executor) mkdirAll(path string) {
+	if x.dryRun {
+		return
+	}
+	os.MkdirAll(path, 0755)
+}
+
+func (x *executor) copyFile(a *App, src, dst string) error {
+	x.record("fetch", "Copy "+src+" -> "+dst)
+	if x.dryRun {
+		return nil
+	}
+	return copyFile(a, src, dst)
+}
+
+func (x *executor) download(a *App, url, dst string) error {
+	x.record("fetch", "Download "+url+" -> "+dst)
+	if x.dryRun {
+		return nil
+	}
+	return downloadFile(a, url, dst)
+}
+
+// nasSession reconnects to the NAS share with the stored credentials when it is unreachable.
+// A dry run only notes it, since connecting opens a real SMB session.
+func (x *executor) nasSession(config *Config) {
+	if config == nil || nasShareRoot(config.NasBasePath) == "" {
+		return
+	}
+	x.record("fetch", "Reconnect to "+nasShareRoot(config.NasBasePath)+" with stored credentials if unreachable")
+	if x.dryRun {
+		return
+	}
+	ensureNasSession(config)
+}
+
+// extract writes an embedded script to the temp folder and returns its path
+func (x *executor) extract(scriptName string) string {
+	if x.dryRun {
+		if _, err := embeddedScripts.ReadFile("scripts/" + scriptName); err != nil {
+			return ""
+		}
+		path := filepath.Join(TempDir, scriptName)
+		x.record("file", "Extract embedded script "+scriptName+" -> "+path)
+		return path
+	}
+	return extractEmbeddedScript(scriptName)
+}
+
+func (x *executor) install(path string, args []string, interactive bool) error {
+	mode := "silent"
+	if interactive {
+		mode = "interactive"
+	}
+	x.record("install", fmt.Sprintf("Run %s %s (%s)", path, strings.Join(args, " "), mode))
+	if x.dryRun {
+		return nil
+	}
+	return runInstaller(path, args, interactive)
+}
+
+// regSet writes a registry value: string -> REG_SZ, uint32 -> REG_DWORD, []string -> REG_MULTI_SZ, []byte -> REG_BINARY
+func (x *executor) regSet(root registry.Key, path, name string, value any) error {
+	ref := regRef{root, path, name}
+	switch v := value.(type) {
+	case string:
+		x.record("registry", fmt.Sprintf("Set %s = %q (REG_SZ)", ref, v))
+	case uint32:
+		x.record("registry", fmt.Sprintf("Set %s = %d (REG_DWORD)", ref, v))
+	case []string:
+		x.record("registry", fmt.Sprintf("Set %s = %q (REG_MULTI_SZ)", ref, v))
+	case []byte:
+		// Binary values are password hashes here; don't put them in the plan
+		x.record("registry", fmt.Sprintf("Set %s = <%d bytes> (REG_BINARY)", ref, len(v)))
+	default:
+		return fmt.Errorf("unsupported registry value type %T", value)
+	}
+	if x.dryRun {
+		return nil
+	}
+
+	k, _, err := registry.CreateKey(root, path, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	switch v := value.(type) {
+	case string:
+		return k.SetStringValue(name, v)
+	case uint32:
+		return k.SetDWordValue(name, v)
+	case []byte:
+		return k.SetBinaryValue(name, v)
+	default:
+		return k.SetStringsValue(name, value.([]string))
+	}
+}
+
+// regDeleteValue removes a value; a missing key or value is not an error
+func (x *executor) regDeleteValue(root registry.Key, path, name string) error {
+	if _, ok := readRegValue(root, path, name); !ok {
+		return nil
+	}
+	x.record("registry", "Delete "+regRef{root, path, name}.String())
+	if x.dryRun {
+		return nil
+	}
+	k, err := registry.OpenKey(root, path, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	return k.DeleteValue(name)
+}
+
+// regDeleteKey removes a key and everything under it; a missing key is not an error
+func (x *executor) regDeleteKey(root registry.Key, path string) error {
+	k, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	k.Close()
+	x.record("registry", "Delete key "+regRootName(root)+"\\"+path)
+	if x.dryRun {
+		return nil
+	}
+	return deleteRegTree(root, path)
+}
+
+func deleteRegTree(root registry.Key, path string) error {
+	k, err := registry.OpenKey(root, path, registry.ENUMERATE_SUB_KEYS)
+	if err == registry.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	subkeys, _ := k.ReadSubKeyNames(-1)
+	k.Close()
+	for _, sub := range subkeys {
+		if err := deleteRegTree(root, path+`\`+sub); err != nil {
+			return err
+		}
+	}
+	return registry.DeleteKey(root, path)
+}
+
+// writeFile replaces a file's contents, creating its folder if needed
+func (x *executor) writeFile(path string, data []byte) error {
+	x.record("file", fmt.Sprintf("Write %s (%d bytes)", path, len(data)))
+	if x.dryRun {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// fetchFromPeers downloads from the first LAN peer that has sw; a dry run only checks the manifests
+func (x *executor) fetchFromPeers(a *App, config *Config, sw Software, destPath string) (string, error) {
+	if !x.dryRun {
+		return fetchFromPeers(a, config, sw, destPath)
+	}
+	for _, base := range config.PeerSources {
+		base = strings.TrimRight(base, "/")
+		entry, err := peerManifestEntry(base, sw.NasPath)
+		if err != nil {
+			continue
+		}
+		x.record("fetch", "Download "+base+"/files/"+peerFilePath(entry.File)+" -> "+destPath+" (sha256 "+entry.SHA256+")")
+		return base, nil
+	}
+	return "", fmt.Errorf("not available from any peer")
+}
+
+// describeCommand turns a command into a plan step; reg.exe and PowerShell get their own kinds
+func describeCommand(cmd *exec.Cmd) (string, string) {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(cmd.Path), filepath.Ext(cmd.Path)))
+	if len(cmd.Args) > 0 {
+		name = strings.ToLower(strings.TrimSuffix(filepath.Base(cmd.Args[0]), filepath.Ext(cmd.Args[0])))
+	}
+	switch name {
+	case "powershell":
+		for i, arg := range cmd.Args {
+			if strings.EqualFold(arg, "-Command") && i+1 < len(cmd.Args) {
+				return "powershell", dedentScript(cmd.Args[i+1])
+			}
+		}
+	case "reg":
+		return "registry", joinCommandLine(cmd.Args)
+	}
+	return "command", joinCommandLine(cmd.Args)
+}
+
+func joinCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t") {
+			a = strconv.Quote(a)
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
+
+// dedentScript strips the indentation Go raw strings give embedded scripts
+func dedentScript(script string) string {
+	var lines []string
+	for _, l := range strings.Split(script, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plannableOperations maps operation names to their implementations, run against an executor
+var plannableOperations = map[string]func(a *App, x *executor, p map[string]string) string{
+	"InstallSoftware": func(a *App, x *executor, p map[string]string) string {
+		switch p["name"] {
+		case "TightVNC Config":
+			return a.applyTightVNCConfig(x)
+		case "SQLyog":
+			return a.installSQLyog(x)
+		case "Docker Desktop":
+			return a.installDocker(x)
+		}
+		return a.installSoftware(x, p["name"])
+	},
+	"UninstallSoftware": func(a *App, x *executor, p map[string]string) string {
+		return a.uninstallSoftware(x, p["name"])
+	},
+	"ApplyTightVNCConfig": func(a *App, x *executor, p map[string]string) string {
+		return a.applyTightVNCConfig(x)
+	},
+	"SetUSBBlock": func(a *App, x *executor, p map[string]string) string {
+		return a.setUSBBlock(x, p["block"] == "true")
+	},
+	"SetRDPBlock": func(a *App, x *executor, p map[string]string) string {
+		return a.setRDPBlock(x, p["block"] == "true")
+	},
+	"SetDomainWhitelist": func(a *App, x *executor, p map[string]string) string {
+		return a.setDomainWhitelist(x, p["domains"])
+	},
+	"ApplyBrowserPolicy": func(a *App, x *executor, p map[string]string) string {
+		// The policy is too structured for key=value params; plan the one in config.json
+		config, err := loadConfig("config.json")
+		if err != nil || config.BrowserPolicy == nil {
+			return "❌ Error: No browser_policy declared in config.json"
+		}
+		return a.applyBrowserPolicy(x, *config.BrowserPolicy)
+	},
+	"ApplyDeviceControl": func(a *App, x *executor, p map[string]string) string {
+		config, err := loadConfig("config.json")
+		if err != nil || config.DeviceControl == nil {
+			return "❌ Error: No device_control declared in config.json"
+		}
+		return a.applyDeviceControl(x, *config.DeviceControl)
+	},
+	"ApplyFirewallRules": func(a *App, x *executor, p map[string]string) string {
+		return a.applyConfiguredFirewallRules(x)
+	},
+	"RemoveFirewallRule": func(a *App, x *executor, p map[string]string) string {
+		return a.removeManagedFirewallRule(x, p["name"])
+	},
+	"AllowPing": func(a *App, x *executor, p map[string]string) string {
+		return a.allowPing(x)
+	},
+	"BlockPing": func(a *App, x *executor, p map[string]string) string {
+		return a.blockPing(x)
+	},
+	"OptimizeSystem": func(a *App, x *executor, p map[string]string) string {
+		return a.optimizeSystem(x, p["action"])
+	},
+	"RenamePC": func(a *App, x *executor, p map[string]string) string {
+		return a.renamePC(x, p["new_name"])
+	},
+	// Passwords are never plan parameters; the dry run only shows the join command
+	"JoinDomain": func(a *App, x *executor, p map[string]string) string {
+		return a.joinDomain(x, p["domain"], p["ou_path"], p["new_name"], p["user"], "")
+	},
+	"JoinWorkgroup": func(a *App, x *executor, p map[string]string) string {
+		return a.joinWorkgroup(x, p["workgroup"], p["new_name"], p["user"], "")
+	},
+	"SetStaticIP": func(a *App, x *executor, p map[string]string) string {
+		return a.applyNetworkSettings(x, NetworkSettings{IP: p["ip"], Subnet: p["subnet"], Gateway: p["gateway"], DNS: splitAddressList(p["dns"])})
+	},
+	"SetDHCP": func(a *App, x *executor, p map[string]string) string {
+		return a.applyNetworkSettings(x, NetworkSettings{Adapter: p["adapter"], DHCP: true})
+	},
+	"AssignIPFromPlan": func(a *App, x *executor, p map[string]string) string {
+		return a.assignIPFromPlan(x, p["subnet"], p["adapter"])
+	},
+	"ApplyNetworkSettings": func(a *App, x *executor, p map[string]string) string {
+		return a.applyNetworkSettings(x, networkSettingsFromParams(p))
+	},
+	"SetWallpaper": func(a *App, x *executor, p map[string]string) string {
+		return a.setWallpaper(x, p["url"], p["style"])
+	},
+	"SetLockScreenImage": func(a *App, x *executor, p map[string]string) string {
+		return a.setLockScreenImage(x, p["source"])
+	},
+	"SetDesktopIcons": func(a *App, x *executor, p map[string]string) string {
+		return a.setDesktopIcons(x, desktopIconParams(p))
+	},
+	"ApplyPersonalization": func(a *App, x *executor, p map[string]string) string {
+		return a.applyPersonalization(x, p["profile"])
+	},
+	"SetBrandedWallpaper": func(a *App, x *executor, p map[string]string) string {
+		return a.setBrandedWallpaper(x)
+	},
+	"SyncTime": func(a *App, x *executor, p map[string]string) string {
+		return a.syncTime(x)
+	},
+	"ApplyRegionalSettings": func(a *App, x *executor, p map[string]string) string {
+		return a.applyRegionalSettings(x, p["profile"])
+	},
+	"ShowThisPCIcon": func(a *App, x *executor, p map[string]string) string {
+		return a.showThisPCIcon(x)
+	},
+	"ApplyPowerPlan": func(a *App, x *executor, p map[string]string) string {
+		return a.applyPowerPlan(x)
+	},
+	"DuplicatePowerPlan": func(a *App, x *executor, p map[string]string) string {
+		return a.duplicatePowerPlan(x, p["source"], p["name"])
+	},
+	"SetActivePowerPlan": func(a *App, x *executor, p map[string]string) string {
+		return a.setActivePowerPlan(x, p["plan"])
+	},
+	"ExportPowerPlan": func(a *App, x *executor, p map[string]string) string {
+		return a.exportPowerPlan(x, p["plan"], p["path"])
+	},
+	"ImportPowerPlan": func(a *App, x *executor, p map[string]string) string {
+		return a.importPowerPlan(x, p["path"], p["name"])
+	},
+	"SetSleepMode": func(a *App, x *executor, p map[string]string) string {
+		minutes, _ := strconv.Atoi(p["minutes"])
+		return a.setSleepMode(x, minutes)
+	},
+}
+
+// planOperation dry-runs one operation
+func (a *App) planOperation(operation string, params map[string]string) OperationPlan {
+	plan := OperationPlan{Operation: operation, Params: redactParams(params), Steps: []PlanStep{}}
+	fn, ok := plannableOperations[operation]
+	if !ok {
+		plan.Outcome = "❌ Error: Dry run is not supported for " + operation + " (supported: " + strings.Join(plannableOperationNames(), ", ") + ")"
+		return plan
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	x := &executor{dryRun: true}
+	plan.Outcome = fn(a, x, params)
+	plan.Steps = append(plan.Steps, x.steps...)
+	return plan
+}
+
+func plannableOperationNames() []string {
+	names := make([]string, 0, len(plannableOperations))
+	for n := range plannableOperations {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PlanOperation returns the steps an operation would take (commands, registry writes,
+// files fetched, sources selected) without executing anything. Params use the same
+// names as the audit log, e.g. {"name": "Google Chrome"} or {"block": "true"}.
+func (a *App) PlanOperation(operation string, params map[string]string) OperationPlan {
+	return a.planOperation(operation, params)
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"Triveni-Control-Center/powercfg"
+)
+
+// PowerPolicy is the company power plan: created from a built-in plan if it doesn't exist, then configured and activated
+type PowerPolicy struct {
+	PlanName    string      `json:"plan_name"`   // e.g. "Triveni Standard"
+	Description string      `json:"description"` // shown in Control Panel under the plan
+	BaseScheme  string      `json:"base_scheme"` // balanced, high_performance, power_saver, ultimate or a plan GUID
+	AC          PowerValues `json:"ac"`          // plugged in
+	DC          PowerValues `json:"dc"`          // on battery
+}
+
+// PowerValues are the timeouts and actions for one power source; unset fields are left as the plan has them
+type PowerValues struct {
+	MonitorMinutes   *int   `json:"monitor_minutes,omitempty"` // 0 = never
+	SleepMinutes     *int   `json:"sleep_minutes,omitempty"`
+	HibernateMinutes *int   `json:"hibernate_minutes,omitempty"`
+	DiskMinutes      *int   `json:"disk_minutes,omitempty"`
+	LidAction        string `json:"lid_action,omitempty"` // nothing, sleep, hibernate, shutdown
+}
+
+// PowerPlan is a plan installed on this PC
+type PowerPlan struct {
+	GUID    string `json:"guid"`
+	Name    string `json:"name"`
+	Active  bool   `json:"active"`
+	Company bool   `json:"company"` // the plan_name from config.json
+}
+
+// PowerSettingStatus is a managed setting on the active plan, as display text
+type PowerSettingStatus struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	AC   string `json:"ac"`
+	DC   string `json:"dc"` // "" when the PC reports no DC value
+}
+
+// PowerReport lists the installed plans and the managed settings of the active one
+type PowerReport struct {
+	Plans       []PowerPlan          `json:"plans"`
+	Active      string               `json:"active"`
+	CompanyPlan string               `json:"company_plan"`
+	Settings    []PowerSettingStatus `json:"settings"`
+	Mismatches  []string             `json:"mismatches"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// The settings the policy manages. Timeouts are stored in seconds.
+var managedPowerSettings = []struct {
+	key     string
+	name    string
+	ref     powerSettingRef
+	minutes func(*PowerValues) *int // nil for the lid action
+}{
+	{"monitor", "Turn off display", powerSettingRef{"SUB_VIDEO", "VIDEOIDLE"}, func(v *PowerValues) *int { return v.MonitorMinutes }},
+	{"sleep", "Sleep after", powerSettingRef{"SUB_SLEEP", "STANDBYIDLE"}, func(v *PowerValues) *int { return v.SleepMinutes }},
+	{"hibernate", "Hibernate after", powerSettingRef{"SUB_SLEEP", "HIBERNATEIDLE"}, func(v *PowerValues) *int { return v.HibernateMinutes }},
+	{"disk", "Turn off hard disk", powerSettingRef{"SUB_DISK", "DISKIDLE"}, func(v *PowerValues) *int { return v.DiskMinutes }},
+	{"lid", "Lid close action", powerSettingRef{"SUB_BUTTONS", "LIDACTION"}, nil},
+}
+
+// Lid action indexes, as in Control Panel
+var lidActions = []string{"nothing", "sleep", "hibernate", "shutdown"}
+
+var builtinPowerSchemes = map[string]string{
+	"balanced":         powercfg.SchemeBalanced,
+	"high_performance": powercfg.SchemeHighPerformance,
+	"power_saver":      powercfg.SchemePowerSaver,
+	"ultimate":         powercfg.SchemeUltimatePerformance,
+}
+
+var powerGUIDRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// powerIndexes turns the configured values into powercfg value indexes
+func (v PowerValues) powerIndexes() (map[string]uint32, []string) {
+	idx := map[string]uint32{}
+	var problems []string
+	for _, m := range managedPowerSettings {
+		if m.minutes == nil {
+			continue
+		}
+		if p := m.minutes(&v); p != nil {
+			if *p < 0 || *p > 71582788 { // seconds must fit in 32 bits
+				problems = append(problems, fmt.Sprintf("%s %d is not a number of minutes", m.key, *p))
+				continue
+			}
+			idx[m.key] = uint32(*p) * 60
+		}
+	}
+	if v.LidAction != "" {
+		found := false
+		for i, a := range lidActions {
+			if strings.EqualFold(a, v.LidAction) {
+				idx["lid"], found = uint32(i), true
+			}
+		}
+		if !found {
+			problems = append(problems, "lid action "+v.LidAction+" must be one of "+strings.Join(lidActions, ", "))
+		}
+	}
+	return idx, problems
+}
+
+// validate resolves the AC and DC indexes and reports every problem in the policy
+func (p *PowerPolicy) validate() (ac, dc map[string]uint32, problems []string) {
+	if strings.TrimSpace(p.PlanName) == "" {
+		problems = append(problems, "power.plan_name is empty")
+	}
+	if strings.ContainsAny(p.PlanName+p.Description, "\"\r\n") {
+		problems = append(problems, "the plan name and description cannot contain quotes or line breaks")
+	}
+	if _, err := resolveBaseScheme(p.BaseScheme); err != nil {
+		problems = append(problems, err.Error())
+	}
+	ac, acProblems := p.AC.powerIndexes()
+	dc, dcProblems := p.DC.powerIndexes()
+	for _, s := range acProblems {
+		problems = append(problems, "AC "+s)
+	}
+	for _, s := range dcProblems {
+		problems = append(problems, "DC "+s)
+	}
+	return ac, dc, problems
+}
+
+func resolveBaseScheme(base string) (string, error) {
+	if base == "" {
+		return powercfg.SchemeBalanced, nil
+	}
+	if guid, ok := builtinPowerSchemes[strings.ToLower(base)]; ok {
+		return guid, nil
+	}
+	if powerGUIDRe.MatchString(base) {
+		return strings.ToLower(base), nil
+	}
+	return "", fmt.Errorf("base_scheme %s must be balanced, high_performance, power_saver, ultimate or a plan GUID", base)
+}
+
+func listPowerPlans() ([]powercfg.Scheme, error) {
+	out, err := runPowercfg("/list")
+	if err != nil {
+		return nil, err
+	}
+	return powercfg.ParseList(out), nil
+}
+
+// findPowerPlan matches a plan by GUID or, ignoring case, by name
+func findPowerPlan(plans []powercfg.Scheme, ref string) (powercfg.Scheme, bool) {
+	for _, p := range plans {
+		if strings.EqualFold(p.GUID, ref) || strings.EqualFold(p.Name, ref) {
+			return p, true
+		}
+	}
+	return powercfg.Scheme{}, false
+}
+
+// formatPowerIndex shows a value index the way Control Panel would
+func formatPowerIndex(key string, v uint32) string {
+	if key == "lid" {
+		if int(v) < len(lidActions) {
+			return lidActions[v]
+		}
+		return fmt.Sprint(v)
+	}
+	if v == 0 {
+		return "never"
+	}
+	if v%60 != 0 {
+		return fmt.Sprintf("%d s", v)
+	}
+	return fmt.Sprintf("%d min", v/60)
+}
+
+// readPowerReport lists the plans and compares the active plan with the policy (nil = no comparison)
+func readPowerReport(policy *PowerPolicy) PowerReport {
+	r := PowerReport{Plans: []PowerPlan{}, Settings: []PowerSettingStatus{}, Mismatches: []string{}}
+	if policy != nil {
+		r.CompanyPlan = policy.PlanName
+	}
+	plans, err := listPowerPlans()
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	active := ""
+	for _, p := range plans {
+		company := policy != nil && strings.EqualFold(p.Name, policy.PlanName)
+		r.Plans = append(r.Plans, PowerPlan{GUID: p.GUID, Name: p.Name, Active: p.Active, Company: company})
+		if p.Active {
+			r.Active, active = p.Name, p.GUID
+		}
+	}
+	if active == "" {
+		r.Error = "cannot tell which power plan is active"
+		return r
+	}
+
+	var ac, dc map[string]uint32
+	if policy != nil {
+		ac, dc, _ = policy.validate()
+		if !strings.EqualFold(r.Active, policy.PlanName) {
+			r.Mismatches = append(r.Mismatches, fmt.Sprintf("active plan is %s, expected %s", r.Active, policy.PlanName))
+		}
+	}
+	// Each setting is queried by name, since a plain /query leaves out hidden settings
+	for _, m := range managedPowerSettings {
+		out, err := runPowercfg("/query", active, m.ref.Subgroup, m.ref.Setting)
+		if err != nil {
+			continue
+		}
+		s, ok := powercfg.Find(powercfg.ParseQuery(out), m.ref.Subgroup, m.ref.Setting)
+		if !ok {
+			continue
+		}
+		status := PowerSettingStatus{Key: m.key, Name: m.name, AC: formatPowerIndex(m.key, s.AC)}
+		if want, ok := ac[m.key]; ok && want != s.AC {
+			r.Mismatches = append(r.Mismatches, fmt.Sprintf("%s (AC) is %s, expected %s", m.name, status.AC, formatPowerIndex(m.key, want)))
+		}
+		if s.HasDC {
+			status.DC = formatPowerIndex(m.key, s.DC)
+			if want, ok := dc[m.key]; ok && want != s.DC {
+				r.Mismatches = append(r.Mismatches, fmt.Sprintf("%s (DC) is %s, expected %s", m.name, status.DC, formatPowerIndex(m.key, want)))
+			}
+		}
+		r.Settings = append(r.Settings, status)
+	}
+	return r
+}
+
+func powercfgCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("powercfg", args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd
+}
+
+// duplicatePowerScheme copies a plan under a new name and returns the copy's GUID
+func duplicatePowerScheme(x *executor, source, name, description string) (string, error) {
+	out, err := x.output(powercfgCommand("/duplicatescheme", source))
+	if err != nil {
+		return "", fmt.Errorf("cannot copy plan %s: %s", source, strings.TrimSpace(string(out)))
+	}
+	guid := powercfg.ParseGUID(string(out))
+	if x.dryRun {
+		guid = "<new plan GUID>"
+	} else if guid == "" {
+		return "", fmt.Errorf("powercfg did not report the new plan's GUID: %s", strings.TrimSpace(string(out)))
+	}
+	args := []string{"/changename", guid, name}
+	if description != "" {
+		args = append(args, description)
+	}
+	if out, err := x.output(powercfgCommand(args...)); err != nil {
+		return guid, fmt.Errorf("cannot rename the new plan: %s", strings.TrimSpace(string(out)))
+	}
+	return guid, nil
+}
+
+// setPowerIndexes writes the values to a plan, AC and DC separately
+func setPowerIndexes(x *executor, guid string, ac, dc map[string]uint32) error {
+	for _, m := range managedPowerSettings {
+		for _, src := range []struct {
+			verb   string
+			values map[string]uint32
+		}{{"/setacvalueindex", ac}, {"/setdcvalueindex", dc}} {
+			v, ok := src.values[m.key]
+			if !ok {
+				continue
+			}
+			if out, err := x.output(powercfgCommand(src.verb, guid, m.ref.Subgroup, m.ref.Setting, strconv.FormatUint(uint64(v), 10))); err != nil {
+				return fmt.Errorf("cannot set %s: %s", m.name, strings.TrimSpace(string(out)))
+			}
+		}
+	}
+	return nil
+}
+
+// GetPowerPlans lists the installed power plans and the managed settings of the active one
+func (a *App) GetPowerPlans() PowerReport {
+	config, _ := loadConfig("config.json")
+	var policy *PowerPolicy
+	if config != nil {
+		policy = config.Power
+	}
+	return readPowerReport(policy)
+}
+
+// ApplyPowerPlan creates the company plan if needed, sets its AC/DC values and activates it
+func (a *App) ApplyPowerPlan() (result string) {
+	defer beginAudit("ApplyPowerPlan", nil, powerAuditProbe).snapshot(powerPlanChangeScope).finish(&result)
+	return a.applyPowerPlan(liveExecutor())
+}
+
+func (a *App) applyPowerPlan(x *executor) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to change power plans."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	policy := config.Power
+	if policy == nil {
+		return "❌ Error: No power plan is declared in config.json (power)."
+	}
+	ac, dc, problems := policy.validate()
+	if len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	plans, err := listPowerPlans()
+	if err != nil {
+		return "❌ Error: Cannot list power plans: " + err.Error()
+	}
+
+	created := ""
+	plan, ok := findPowerPlan(plans, policy.PlanName)
+	guid := plan.GUID
+	if !ok {
+		base, _ := resolveBaseScheme(policy.BaseScheme)
+		if guid, err = duplicatePowerScheme(x, base, policy.PlanName, policy.Description); err != nil {
+			return "❌ Error: " + err.Error()
+		}
+		created = " (created from " + onOff(policy.BaseScheme != "", policy.BaseScheme, "balanced") + ")"
+	}
+	if err := setPowerIndexes(x, guid, ac, dc); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if out, err := x.output(powercfgCommand("/setactive", guid)); err != nil {
+		return "❌ Error: Cannot activate " + policy.PlanName + ": " + strings.TrimSpace(string(out))
+	}
+	return fmt.Sprintf("✅ Success: Power plan %s%s is active with %d AC and %d DC setting(s).", policy.PlanName, created, len(ac), len(dc))
+}
+
+// DuplicatePowerPlan copies a plan (name, GUID or built-in alias) under a new name
+func (a *App) DuplicatePowerPlan(source, name string) (result string) {
+	defer beginAudit("DuplicatePowerPlan", map[string]string{"source": source, "name": name}, nil).finish(&result)
+	return a.duplicatePowerPlan(liveExecutor(), source, name)
+}
+
+func (a *App) duplicatePowerPlan(x *executor, source, name string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to change power plans."); !ok {
+		return msg
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "\"\r\n") {
+		return "❌ Error: Enter a plan name without quotes."
+	}
+	plans, err := listPowerPlans()
+	if err != nil {
+		return "❌ Error: Cannot list power plans: " + err.Error()
+	}
+	if _, exists := findPowerPlan(plans, name); exists {
+		return "❌ Error: A power plan named " + name + " already exists."
+	}
+	guid, err := resolveBaseScheme(source)
+	if p, ok := findPowerPlan(plans, source); ok {
+		guid, err = p.GUID, nil
+	}
+	if err != nil {
+		return "❌ Error: Power plan " + source + " not found."
+	}
+	newGUID, err := duplicatePowerScheme(x, guid, name, "")
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return "✅ Success: Power plan " + name + " created (" + newGUID + ")."
+}
+
+// SetActivePowerPlan switches to an installed plan by name or GUID
+func (a *App) SetActivePowerPlan(plan string) (result string) {
+	defer beginAudit("SetActivePowerPlan", map[string]string{"plan": plan}, powerAuditProbe).snapshot(powerPlanChangeScope).finish(&result)
+	return a.setActivePowerPlan(liveExecutor(), plan)
+}
+
+func (a *App) setActivePowerPlan(x *executor, plan string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to change power plans."); !ok {
+		return msg
+	}
+	plans, err := listPowerPlans()
+	if err != nil {
+		return "❌ Error: Cannot list power plans: " + err.Error()
+	}
+	p, ok := findPowerPlan(plans, plan)
+	if !ok {
+		return "❌ Error: Power plan " + plan + " not found."
+	}
+	if out, err := x.output(powercfgCommand("/setactive", p.GUID)); err != nil {
+		return "❌ Error: Cannot activate " + p.Name + ": " + strings.TrimSpace(string(out))
+	}
+	return "✅ Success: Power plan " + p.Name + " is active."
+}
+
+// ExportPowerPlan saves a plan to a .pow file ("" plan = the active one, "" path = the app data folder)
+func (a *App) ExportPowerPlan(plan, path string) (result string) {
+	defer beginAudit("ExportPowerPlan", map[string]string{"plan": plan, "path": path}, nil).finish(&result)
+	return a.exportPowerPlan(liveExecutor(), plan, path)
+}
+
+func (a *App) exportPowerPlan(x *executor, plan, path string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to export power plans."); !ok {
+		return msg
+	}
+	plans, err := listPowerPlans()
+	if err != nil {
+		return "❌ Error: Cannot list power plans: " + err.Error()
+	}
+	if plan == "" {
+		for _, candidate := range plans {
+			if candidate.Active {
+				plan = candidate.GUID
+			}
+		}
+	}
+	p, found := findPowerPlan(plans, plan)
+	if !found {
+		return "❌ Error: Power plan " + onOff(plan != "", plan, "(active)") + " not found."
+	}
+	if path == "" {
+		path = filepath.Join(appDataDir(), sanitizeFileName(p.Name)+".pow")
+	}
+	x.mkdirAll(filepath.Dir(path))
+
+	// powercfg refuses to overwrite a file, so export next to the target and move it over;
+	// an existing export is only replaced once the new one is complete
+	tmp := path + ".tmp"
+	if !x.dryRun {
+		os.Remove(tmp)
+	}
+	if out, err := x.output(powercfgCommand("/export", tmp, p.GUID)); err != nil {
+		os.Remove(tmp)
+		return "❌ Error: Cannot export " + p.Name + ": " + strings.TrimSpace(string(out))
+	}
+	x.record("file", "Move "+tmp+" to "+path)
+	if !x.dryRun {
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return "❌ Error: Exported, but cannot replace " + path + ": " + err.Error()
+		}
+	}
+	return "✅ Success: Power plan " + p.Name + " exported to " + path
+}
+
+// ImportPowerPlan installs a plan from a .pow file, optionally renaming it; it is not activated
+func (a *App) ImportPowerPlan(path, name string) (result string) {
+	defer beginAudit("ImportPowerPlan", map[string]string{"path": path, "name": name}, nil).finish(&result)
+	return a.importPowerPlan(liveExecutor(), path, name)
+}
+
+func (a *App) importPowerPlan(x *executor, path, name string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to change power plans."); !ok {
+		return msg
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "❌ Error: Cannot read " + path + ": " + err.Error()
+	}
+	if strings.ContainsAny(name, "\"\r\n") {
+		return "❌ Error: The plan name cannot contain quotes or line breaks."
+	}
+	out, err := x.output(powercfgCommand("/import", path))
+	if err != nil {
+		return "❌ Error: Cannot import " + path + ": " + strings.TrimSpace(string(out))
+	}
+	guid := powercfg.ParseGUID(string(out))
+	if x.dryRun {
+		guid = "<imported plan GUID>"
+	} else if guid == "" {
+		return "❌ Error: powercfg did not report the imported plan's GUID: " + strings.TrimSpace(string(out))
+	}
+	if name != "" {
+		if out, err := x.output(powercfgCommand("/changename", guid, name)); err != nil {
+			return "❌ Error: Imported as " + guid + " but cannot rename it: " + strings.TrimSpace(string(out))
+		}
+	}
+	return "✅ Success: Power plan " + onOff(name != "", name, guid) + " imported. Activate it to use it."
+}
+
+// sanitizeFileName replaces characters Windows does not allow in file names
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, name)
+}
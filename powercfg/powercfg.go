@@ -0,0 +1,145 @@
+// Package powercfg parses the output of Windows' powercfg tool. It has no Windows
+// dependencies so the parsing can be built and checked on any platform.
+package powercfg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Scheme is a power plan as listed by `powercfg /list`
+type Scheme struct {
+	GUID   string `json:"guid"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// Setting is one power setting as shown by `powercfg /query`
+type Setting struct {
+	SubgroupGUID  string   `json:"subgroup_guid"`
+	SubgroupAlias string   `json:"subgroup_alias"`
+	SubgroupName  string   `json:"subgroup_name"`
+	GUID          string   `json:"guid"`
+	Alias         string   `json:"alias"`
+	Name          string   `json:"name"`
+	Units         string   `json:"units"`   // e.g. "Seconds"; empty for settings with Options
+	Options       []string `json:"options"` // friendly names by index, for settings like the lid action
+	AC            uint32   `json:"ac"`
+	DC            uint32   `json:"dc"`
+	HasDC         bool     `json:"has_dc"` // desktops without a battery may omit the DC index
+}
+
+// GUIDs of the built-in plans
+const (
+	SchemeBalanced            = "381b4222-f694-41f0-9685-ff5bb260df2e"
+	SchemeHighPerformance     = "8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c"
+	SchemePowerSaver          = "a1841308-3541-4fab-bc81-f71556f20b4a"
+	SchemeUltimatePerformance = "e9a42b02-d5df-448d-aa00-03f14749eb61"
+)
+
+var (
+	guidRe = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+	// "Power Scheme GUID: 381b4222-...  (Balanced) *"
+	schemeLineRe = regexp.MustCompile(`GUID:\s*(` + guidRe.String() + `)\s*(?:\((.*)\))?\s*(\*)?\s*$`)
+	// "Subgroup GUID: ..." / "Power Setting GUID: ..." with an optional "(Friendly name)"
+	guidLineRe = regexp.MustCompile(`^(Subgroup|Power Setting) GUID:\s*(` + guidRe.String() + `)\s*(?:\((.*)\))?\s*$`)
+)
+
+// ParseGUID returns the first GUID in the output of /getactivescheme, /duplicatescheme or /import
+func ParseGUID(out string) string {
+	return strings.ToLower(guidRe.FindString(out))
+}
+
+// ParseList reads the plans from `powercfg /list`
+func ParseList(out string) []Scheme {
+	var schemes []Scheme
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Power Scheme GUID:") {
+			continue
+		}
+		m := schemeLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		schemes = append(schemes, Scheme{GUID: strings.ToLower(m[1]), Name: strings.TrimSpace(m[2]), Active: m[3] == "*"})
+	}
+	return schemes
+}
+
+// ParseQuery reads the settings from `powercfg /query [scheme [subgroup [setting]]]`
+func ParseQuery(out string) []Setting {
+	var (
+		settings []Setting
+		sub      Setting // subgroup fields of the settings that follow
+		cur      *Setting
+		inSub    bool // the last GUID line was a subgroup, so a GUID Alias belongs to it
+	)
+	for _, raw := range strings.Split(out, "\n") {
+		line := strings.TrimSpace(raw)
+		if m := guidLineRe.FindStringSubmatch(line); m != nil {
+			if m[1] == "Subgroup" {
+				sub = Setting{SubgroupGUID: strings.ToLower(m[2]), SubgroupName: strings.TrimSpace(m[3])}
+				cur, inSub = nil, true
+				continue
+			}
+			settings = append(settings, Setting{
+				SubgroupGUID: sub.SubgroupGUID, SubgroupAlias: sub.SubgroupAlias, SubgroupName: sub.SubgroupName,
+				GUID: strings.ToLower(m[2]), Name: strings.TrimSpace(m[3]),
+			})
+			cur, inSub = &settings[len(settings)-1], false
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if key == "GUID Alias" {
+			if inSub {
+				sub.SubgroupAlias = value
+			} else if cur != nil {
+				cur.Alias = value
+			}
+		}
+		if cur == nil {
+			continue
+		}
+		switch key {
+		case "Possible Settings units":
+			cur.Units = value
+		case "Possible Setting Friendly Name":
+			cur.Options = append(cur.Options, value)
+		case "Current AC Power Setting Index":
+			cur.AC, _ = ParseIndex(value)
+		case "Current DC Power Setting Index":
+			cur.DC, _ = ParseIndex(value)
+			cur.HasDC = true
+		}
+	}
+	return settings
+}
+
+// ParseIndex reads a powercfg value index such as "0x000004b0"
+func ParseIndex(s string) (uint32, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "0x"), "0X")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(v), true
+}
+
+// Find returns the setting with the given alias or GUID, if the query listed it
+func Find(settings []Setting, subgroup, setting string) (Setting, bool) {
+	for _, s := range settings {
+		if (strings.EqualFold(s.SubgroupAlias, subgroup) || strings.EqualFold(s.SubgroupGUID, subgroup)) &&
+			(strings.EqualFold(s.Alias, setting) || strings.EqualFold(s.GUID, setting)) {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
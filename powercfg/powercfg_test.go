@@ -0,0 +1,201 @@
+package powercfg
+
+import (
+	"strings"
+	"testing"
+)
+
+// Output captured from powercfg on Windows 11; Windows writes CRLF line endings
+var listOutput = strings.ReplaceAll(`
+Existing Power Schemes (* Active)
+-----------------------------------
+Power Scheme GUID: 381b4222-f694-41f0-9685-ff5bb260df2e  (Balanced)
+Power Scheme GUID: 8C5E7FDA-E8BF-4A96-9A85-A6E23A8C635C  (High performance) *
+Power Scheme GUID: a1841308-3541-4fab-bc81-f71556f20b4a  (Power saver)
+Power Scheme GUID: 5d2b7c6e-1f3a-4c8e-9b0d-2e4f6a8c0b1d  (Triveni Standard (AC))
+`, "\n", "\r\n")
+
+const queryLaptop = `Power Scheme GUID: 381b4222-f694-41f0-9685-ff5bb260df2e  (Balanced)
+  GUID Alias: SCHEME_BALANCED
+  Subgroup GUID: 0012ee47-9041-4b5d-9b77-535fba8b1442  (Hard disk)
+    GUID Alias: SUB_DISK
+    Power Setting GUID: 6738e2c4-e8a5-4a42-b16a-e040e769756e  (Turn off hard disk after)
+      GUID Alias: DISKIDLE
+      Minimum Possible Setting: 0x00000000
+      Maximum Possible Setting: 0xffffffff
+      Possible Settings increment: 0x00000001
+      Possible Settings units: Seconds
+    Current AC Power Setting Index: 0x000004b0
+    Current DC Power Setting Index: 0x00000258
+
+  Subgroup GUID: 4f971e89-eebd-4455-a8de-9e59040e7347  (Power buttons and lid)
+    GUID Alias: SUB_BUTTONS
+    Power Setting GUID: 5ca83367-6e45-459f-a27b-476b1d01c936  (Lid close action)
+      GUID Alias: LIDACTION
+      Possible Setting Index: 000
+      Possible Setting Friendly Name: Do nothing
+      Possible Setting Index: 001
+      Possible Setting Friendly Name: Sleep
+      Possible Setting Index: 002
+      Possible Setting Friendly Name: Hibernate
+      Possible Setting Index: 003
+      Possible Setting Friendly Name: Shut down
+    Current AC Power Setting Index: 0x00000000
+    Current DC Power Setting Index: 0x00000001
+
+  Subgroup GUID: 54533251-82be-4824-96c1-47b60b740d00  (Processor power management)
+    GUID Alias: SUB_PROCESSOR
+    Power Setting GUID: 0cc5b647-c1df-4637-891a-dec35c318583  (Processor performance core parking min cores)
+      GUID Alias: CPMINCORES
+      Minimum Possible Setting: 0x00000000
+      Maximum Possible Setting: 0x00000064
+      Possible Settings increment: 0x00000001
+      Possible Settings units: %
+    Current AC Power Setting Index: 0x00000064
+    Current DC Power Setting Index: 0x0000000a
+    Power Setting GUID: 4d2b0152-7d5c-498b-88e2-34345392a2c5  (Processor performance time check interval)
+      Minimum Possible Setting: 0x00000001
+      Maximum Possible Setting: 0x00001388
+      Possible Settings increment: 0x00000001
+      Possible Settings units: Milliseconds
+    Current AC Power Setting Index: 0x0000000f
+    Current DC Power Setting Index: 0x0000001e
+`
+
+// A desktop without a battery lists no DC index
+const queryDesktop = `Power Scheme GUID: 8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c  (High performance)
+  GUID Alias: SCHEME_MIN
+  Subgroup GUID: 7516b95f-f776-4464-8c53-06167f40cc99  (Display)
+    GUID Alias: SUB_VIDEO
+    Power Setting GUID: 3c0bc021-c8a8-4e07-a973-6b14cbcb2b7e  (Turn off display after)
+      GUID Alias: VIDEOIDLE
+      Minimum Possible Setting: 0x00000000
+      Maximum Possible Setting: 0xffffffff
+      Possible Settings increment: 0x00000001
+      Possible Settings units: Seconds
+    Current AC Power Setting Index: 0x00000384
+`
+
+const duplicateOutput = "Power Scheme GUID: 5D2B7C6E-1F3A-4C8E-9B0D-2E4F6A8C0B1D  (Balanced)\r\n"
+
+func TestParseList(t *testing.T) {
+	want := []Scheme{
+		{GUID: SchemeBalanced, Name: "Balanced"},
+		{GUID: SchemeHighPerformance, Name: "High performance", Active: true},
+		{GUID: SchemePowerSaver, Name: "Power saver"},
+		{GUID: "5d2b7c6e-1f3a-4c8e-9b0d-2e4f6a8c0b1d", Name: "Triveni Standard (AC)"},
+	}
+	got := ParseList(listOutput)
+	if len(got) != len(want) {
+		t.Fatalf("ParseList returned %d schemes, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("scheme %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got := ParseList(""); len(got) != 0 {
+		t.Errorf("ParseList(\"\") = %+v, want none", got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	settings := ParseQuery(queryLaptop)
+	if len(settings) != 4 {
+		t.Fatalf("ParseQuery returned %d settings, want 4: %+v", len(settings), settings)
+	}
+
+	disk := settings[0]
+	if disk.SubgroupGUID != "0012ee47-9041-4b5d-9b77-535fba8b1442" || disk.SubgroupAlias != "SUB_DISK" || disk.SubgroupName != "Hard disk" {
+		t.Errorf("disk subgroup = %q %q %q", disk.SubgroupGUID, disk.SubgroupAlias, disk.SubgroupName)
+	}
+	if disk.Alias != "DISKIDLE" || disk.Name != "Turn off hard disk after" || disk.Units != "Seconds" {
+		t.Errorf("disk setting = %+v", disk)
+	}
+	if disk.AC != 1200 || disk.DC != 600 || !disk.HasDC {
+		t.Errorf("disk values = AC %d DC %d HasDC %v, want 1200 600 true", disk.AC, disk.DC, disk.HasDC)
+	}
+
+	lid := settings[1]
+	wantOptions := []string{"Do nothing", "Sleep", "Hibernate", "Shut down"}
+	if strings.Join(lid.Options, "|") != strings.Join(wantOptions, "|") {
+		t.Errorf("lid options = %q, want %q", lid.Options, wantOptions)
+	}
+	if lid.Units != "" || lid.AC != 0 || lid.DC != 1 || lid.SubgroupAlias != "SUB_BUTTONS" {
+		t.Errorf("lid setting = %+v", lid)
+	}
+
+	// Hidden settings: the second processor setting has no alias, and must not inherit the previous one
+	cores, timeCheck := settings[2], settings[3]
+	if cores.Alias != "CPMINCORES" || cores.Units != "%" || cores.AC != 100 || cores.DC != 10 {
+		t.Errorf("core parking setting = %+v", cores)
+	}
+	if timeCheck.Alias != "" || timeCheck.SubgroupAlias != "SUB_PROCESSOR" || timeCheck.AC != 15 || timeCheck.DC != 30 {
+		t.Errorf("time check setting = %+v", timeCheck)
+	}
+
+	desktop := ParseQuery(strings.ReplaceAll(queryDesktop, "\n", "\r\n"))
+	if len(desktop) != 1 {
+		t.Fatalf("ParseQuery(desktop) returned %d settings, want 1", len(desktop))
+	}
+	if d := desktop[0]; d.Alias != "VIDEOIDLE" || d.AC != 900 || d.HasDC || d.DC != 0 {
+		t.Errorf("desktop setting = %+v, want VIDEOIDLE AC 900 without DC", d)
+	}
+}
+
+func TestFind(t *testing.T) {
+	settings := ParseQuery(queryLaptop)
+	tests := []struct {
+		subgroup, setting string
+		wantName          string
+	}{
+		{"SUB_DISK", "DISKIDLE", "Turn off hard disk after"},
+		{"sub_buttons", "lidaction", "Lid close action"},
+		{"4f971e89-eebd-4455-a8de-9e59040e7347", "5CA83367-6E45-459F-A27B-476B1D01C936", "Lid close action"},
+		{"SUB_PROCESSOR", "4d2b0152-7d5c-498b-88e2-34345392a2c5", "Processor performance time check interval"},
+	}
+	for _, tt := range tests {
+		s, ok := Find(settings, tt.subgroup, tt.setting)
+		if !ok || s.Name != tt.wantName {
+			t.Errorf("Find(%q, %q) = %q, %v; want %q", tt.subgroup, tt.setting, s.Name, ok, tt.wantName)
+		}
+	}
+	if _, ok := Find(settings, "SUB_DISK", "LIDACTION"); ok {
+		t.Error("Find matched a setting from another subgroup")
+	}
+	if _, ok := Find(settings, "SUB_VIDEO", "VIDEOIDLE"); ok {
+		t.Error("Find matched a setting the query did not list")
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint32
+		ok   bool
+	}{
+		{"0x000004b0", 1200, true},
+		{" 0X00000258\r", 600, true},
+		{"0xffffffff", 0xffffffff, true},
+		{"000", 0, true},
+		{"", 0, false},
+		{"0x", 0, false},
+		{"Sleep", 0, false},
+		{"0x100000000", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseIndex(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseIndex(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseGUID(t *testing.T) {
+	if got := ParseGUID(duplicateOutput); got != "5d2b7c6e-1f3a-4c8e-9b0d-2e4f6a8c0b1d" {
+		t.Errorf("ParseGUID(duplicatescheme) = %q", got)
+	}
+	if got := ParseGUID("Unable to perform operation. An unexpected error (0x1f) has occurred"); got != "" {
+		t.Errorf("ParseGUID(error) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,720 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/sys/windows/registry"
+)
+
+// RebootReason is one thing waiting for a restart
+type RebootReason struct {
+	Source string `json:"source"` // operation name, or the Windows component that asked for it
+	Detail string `json:"detail"`
+	Time   string `json:"time,omitempty"`
+}
+
+// PendingReboot combines our own reasons with the flags Windows sets
+type PendingReboot struct {
+	Required     bool           `json:"required"`
+	Reasons      []RebootReason `json:"reasons"`
+	ScheduledFor string         `json:"scheduled_for"` // a reboot started with ScheduleReboot, "" if none
+	BootTime     string         `json:"boot_time"`
+}
+
+// JobStep is one operation of a setup profile or job queue, with the same params as a dry run
+type JobStep struct {
+	Operation string            `json:"operation"`
+	Params    map[string]string `json:"params"`
+}
+
+// SetupProfile is a named list of steps (profiles in config.json)
+type SetupProfile struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Steps       []JobStep `json:"steps"`
+	// Fields set here override the config-wide regional settings and personalization
+	Regional        *RegionalSettings       `json:"regional,omitempty"`
+	Personalization *PersonalizationProfile `json:"personalization,omitempty"`
+}
+
+// QueuedJob is a step of the job queue and how it went
+type QueuedJob struct {
+	JobStep
+	Status   string `json:"status"` // pending, running, done, failed
+	Result   string `json:"result"`
+	Finished string `json:"finished"`
+}
+
+// JobQueue is the list of steps being worked through, kept across reboots in job-queue.json
+type JobQueue struct {
+	Profile string      `json:"profile"`
+	Jobs    []QueuedJob `json:"jobs"`
+	State   string      `json:"state"` // running, awaiting_reboot, done, failed, cancelled
+	Reboots int         `json:"reboots"`
+	Created string      `json:"created"`
+	Updated string      `json:"updated"`
+}
+
+// rebootSchedule remembers a reboot we started so it can be shown and cancelled
+type rebootSchedule struct {
+	At     string `json:"at"`
+	Reason string `json:"reason"`
+}
+
+const (
+	resumeTaskName            = "Triveni-Control-Center Resume"
+	defaultRebootCountdownSec = 300
+	maxQueueReboots           = 5
+	resumeDelay               = 10 * time.Second // let the network and NAS session come up after login
+	rebootCommentLimit        = 500
+	pendingFileRenameKey      = `SYSTEM\CurrentControlSet\Control\Session Manager`
+	cbsRebootPendingKey       = `SOFTWARE\Microsoft\Windows\CurrentVersion\Component Based Servicing\RebootPending`
+	wuRebootRequiredKey       = `SOFTWARE\Microsoft\Windows\CurrentVersion\WindowsUpdate\Auto Update\RebootRequired`
+	netlogonJoinDomainKey     = `SYSTEM\CurrentControlSet\Services\Netlogon\JoinDomain`
+	activeComputerNameKey     = `SYSTEM\CurrentControlSet\Control\ComputerName\ActiveComputerName`
+)
+
+// Phrases operation results use when they need a restart
+var restartPhrases = []string{"Restart required", "Restart to apply"}
+
+// Operations that cannot run unattended from a queue
+var unqueueableOperations = map[string]string{
+	"JoinDomain":    "needs a password, which is never stored",
+	"JoinWorkgroup": "may need a password, which is never stored",
+	"RunProfile":    "profiles cannot be nested",
+}
+
+// Operations that read their settings from the profile running them (the "profile" param)
+var profileAwareOperations = map[string]bool{
+	"ApplyRegionalSettings": true,
+	"ApplyPersonalization":  true,
+}
+
+var (
+	rebootMu   sync.Mutex
+	jobQueueMu sync.Mutex
+
+	procGetTickCount64 = modkernel32.NewProc("GetTickCount64")
+)
+
+// RunProfile is registered here rather than in the map literal: planning a profile
+// looks up the other operations, which would make plannableOperations refer to itself
+func init() {
+	plannableOperations["RunProfile"] = func(a *App, x *executor, p map[string]string) string {
+		return a.planProfile(x, p["name"])
+	}
+}
+
+func rebootReasonsPath() string {
+	return filepath.Join(appDataDir(), "reboot-reasons.json")
+}
+
+func rebootSchedulePath() string {
+	return filepath.Join(appDataDir(), "reboot-scheduled.json")
+}
+
+func jobQueuePath() string {
+	return filepath.Join(appDataDir(), "job-queue.json")
+}
+
+// bootTime is when Windows last started
+func bootTime() time.Time {
+	ms, _, _ := procGetTickCount64.Call()
+	return time.Now().Add(-time.Duration(ms) * time.Millisecond)
+}
+
+// restartRequired tells whether an operation result asks for a restart
+func restartRequired(result string) bool {
+	for _, phrase := range restartPhrases {
+		if strings.Contains(result, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// noteRebootReason is called for every audited operation; results asking for a restart are remembered until the next boot
+func noteRebootReason(operation, result string) {
+	if !restartRequired(result) {
+		return
+	}
+	rebootMu.Lock()
+	defer rebootMu.Unlock()
+	reasons := loadRebootReasons()
+	reasons = append(reasons, RebootReason{Source: operation, Detail: strings.TrimSpace(result), Time: time.Now().Format(time.RFC3339)})
+	os.MkdirAll(appDataDir(), 0755)
+	data, _ := json.MarshalIndent(reasons, "", "  ")
+	os.WriteFile(rebootReasonsPath(), data, 0644)
+}
+
+// loadRebootReasons returns our reasons recorded since the last boot
+func loadRebootReasons() []RebootReason {
+	var all []RebootReason
+	data, err := os.ReadFile(rebootReasonsPath())
+	if err != nil || json.Unmarshal(data, &all) != nil {
+		return []RebootReason{}
+	}
+	booted := bootTime()
+	current := []RebootReason{}
+	for _, r := range all {
+		if t, err := time.Parse(time.RFC3339, r.Time); err == nil && t.After(booted) {
+			current = append(current, r)
+		}
+	}
+	return current
+}
+
+// windowsRebootReasons reads the flags Windows components set when they need a restart
+func windowsRebootReasons() []RebootReason {
+	var reasons []RebootReason
+	keyExists := func(path string) bool {
+		k, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+		if err != nil {
+			return false
+		}
+		k.Close()
+		return true
+	}
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, pendingFileRenameKey, registry.QUERY_VALUE); err == nil {
+		if files, _, err := k.GetStringsValue("PendingFileRenameOperations"); err == nil && len(files) > 0 {
+			reasons = append(reasons, RebootReason{Source: "PendingFileRenameOperations",
+				Detail: fmt.Sprintf("%d file operation(s) waiting for a restart", len(files)/2)})
+		}
+		k.Close()
+	}
+	if keyExists(cbsRebootPendingKey) {
+		reasons = append(reasons, RebootReason{Source: "Component Based Servicing", Detail: "Windows features or updates are being serviced"})
+	}
+	if keyExists(wuRebootRequiredKey) {
+		reasons = append(reasons, RebootReason{Source: "Windows Update", Detail: "Installed updates need a restart"})
+	}
+	if keyExists(netlogonJoinDomainKey) {
+		reasons = append(reasons, RebootReason{Source: "Domain join", Detail: "A domain join completes on restart"})
+	}
+	active, _ := readRegValue(registry.LOCAL_MACHINE, activeComputerNameKey, "ComputerName")
+	if pending := pendingComputerName(); active != "" && pending != "" && !strings.EqualFold(active, pending) {
+		reasons = append(reasons, RebootReason{Source: "Computer name", Detail: active + " becomes " + pending + " on restart"})
+	}
+	return reasons
+}
+
+func loadRebootSchedule() (rebootSchedule, bool) {
+	var s rebootSchedule
+	data, err := os.ReadFile(rebootSchedulePath())
+	if err != nil || json.Unmarshal(data, &s) != nil {
+		return s, false
+	}
+	// A schedule from before the last boot has happened (or was abandoned)
+	if t, err := time.Parse(time.RFC3339, s.At); err != nil || t.Before(bootTime()) {
+		return s, false
+	}
+	return s, true
+}
+
+func pendingReboot() PendingReboot {
+	p := PendingReboot{Reasons: loadRebootReasons(), BootTime: bootTime().Format(time.RFC3339)}
+	p.Reasons = append(p.Reasons, windowsRebootReasons()...)
+	p.Required = len(p.Reasons) > 0
+	if s, ok := loadRebootSchedule(); ok {
+		p.ScheduledFor = s.At
+	}
+	return p
+}
+
+// scheduleReboot starts Windows' restart countdown, which the logged-on user sees as a notification
+func scheduleReboot(x *executor, seconds int, reason string) error {
+	if seconds < 0 {
+		seconds = defaultRebootCountdownSec
+	}
+	if len(reason) > rebootCommentLimit {
+		reason = reason[:rebootCommentLimit]
+	}
+	// p:4:1 = planned, application maintenance
+	cmd := exec.Command("shutdown", "/r", "/t", fmt.Sprint(seconds), "/d", "p:4:1", "/c", reason)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if output, err := x.output(cmd); err != nil {
+		return fmt.Errorf("%s %v", strings.TrimSpace(string(output)), err)
+	}
+	if x.dryRun {
+		return nil
+	}
+	s := rebootSchedule{At: time.Now().Add(time.Duration(seconds) * time.Second).Format(time.RFC3339), Reason: reason}
+	os.MkdirAll(appDataDir(), 0755)
+	data, _ := json.MarshalIndent(s, "", "  ")
+	return os.WriteFile(rebootSchedulePath(), data, 0644)
+}
+
+func (c *Config) rebootCountdown() int {
+	if c != nil && c.RebootCountdownSec > 0 {
+		return c.RebootCountdownSec
+	}
+	return defaultRebootCountdownSec
+}
+
+// --- Job queue ---
+
+// validateJobSteps checks that every step names an operation that can run unattended
+func validateJobSteps(steps []JobStep) []string {
+	var problems []string
+	if len(steps) == 0 {
+		problems = append(problems, "no steps")
+	}
+	for i, s := range steps {
+		if why, ok := unqueueableOperations[s.Operation]; ok {
+			problems = append(problems, fmt.Sprintf("step %d: %s cannot be queued (%s)", i+1, s.Operation, why))
+		} else if _, ok := plannableOperations[s.Operation]; !ok {
+			problems = append(problems, fmt.Sprintf("step %d: unknown operation %q", i+1, s.Operation))
+		}
+	}
+	return problems
+}
+
+func (c *Config) profile(name string) (SetupProfile, bool) {
+	for _, p := range c.Profiles {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return SetupProfile{}, false
+}
+
+// forProfile fills in the profile of a step that reads its settings from it, unless the step names one
+func (s JobStep) forProfile(profile string) JobStep {
+	params := map[string]string{}
+	for k, v := range s.Params {
+		params[k] = v
+	}
+	if profile != "" && profileAwareOperations[s.Operation] && params["profile"] == "" {
+		params["profile"] = profile
+	}
+	s.Params = params
+	return s
+}
+
+func newJobQueue(profile string, steps []JobStep) *JobQueue {
+	q := &JobQueue{Profile: profile, State: "running", Created: time.Now().Format(time.RFC3339)}
+	for _, s := range steps {
+		q.Jobs = append(q.Jobs, QueuedJob{JobStep: s.forProfile(profile), Status: "pending"})
+	}
+	return q
+}
+
+// loadJobQueue returns the saved queue, or nil if there is none
+func loadJobQueue() (*JobQueue, error) {
+	data, err := os.ReadFile(jobQueuePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var q JobQueue
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("%s: %v", jobQueuePath(), err)
+	}
+	return &q, nil
+}
+
+func (q *JobQueue) save() error {
+	q.Updated = time.Now().Format(time.RFC3339)
+	if err := os.MkdirAll(appDataDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The queue runs elevated after a reboot, so only administrators may write it
+	return writeAdminOnlyFile(jobQueuePath(), data)
+}
+
+func (q *JobQueue) steps() []JobStep {
+	steps := make([]JobStep, len(q.Jobs))
+	for i, j := range q.Jobs {
+		steps[i] = j.JobStep
+	}
+	return steps
+}
+
+func (q *JobQueue) remaining() int {
+	n := 0
+	for _, j := range q.Jobs {
+		if j.Status == "pending" {
+			n++
+		}
+	}
+	return n
+}
+
+func (q *JobQueue) active() bool {
+	return q.State == "running" || q.State == "awaiting_reboot"
+}
+
+// emitJobQueue tells the window about progress (no-op when running without one)
+func (a *App) emitJobQueue(q *JobQueue) {
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "job-queue", q)
+	}
+}
+
+// runQueuedStep runs one step live, audited like the corresponding App method
+func runQueuedStep(a *App, step JobStep) (result string) {
+	defer beginAudit(step.Operation, step.Params, nil).finish(&result)
+	if problems := validateJobSteps([]JobStep{step}); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	return plannableOperations[step.Operation](a, liveExecutor(), step.Params)
+}
+
+// registerResumeTask starts the Control Center elevated at the next logon so the queue continues
+func registerResumeTask(x *executor) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("schtasks", "/Create", "/TN", resumeTaskName,
+		"/TR", `"`+exe+`" --resume`, "/SC", "ONLOGON", "/RL", "HIGHEST", "/IT", "/RU", currentUserName(), "/F")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if output, err := x.output(cmd); err != nil {
+		return fmt.Errorf("%s %v", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+func removeResumeTask(x *executor) {
+	cmd := exec.Command("schtasks", "/Delete", "/TN", resumeTaskName, "/F")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	x.output(cmd)
+}
+
+// runJobQueue works through the pending steps. When a step asks for a restart and steps remain,
+// it schedules the reboot and a logon task that reopens the Control Center to continue.
+func (a *App) runJobQueue(q *JobQueue) string {
+	jobQueueMu.Lock()
+	defer jobQueueMu.Unlock()
+	return a.runJobQueueLocked(q)
+}
+
+// runJobQueueLocked is runJobQueue for a caller that already holds jobQueueMu
+func (a *App) runJobQueueLocked(q *JobQueue) string {
+	x := liveExecutor()
+	config, _ := loadConfig("config.json")
+	q.State = "running"
+	q.save()
+	a.emitJobQueue(q)
+	removeResumeTask(x)
+
+	total := len(q.Jobs)
+	for i := range q.Jobs {
+		j := &q.Jobs[i]
+		if j.Status == "done" {
+			continue
+		}
+		j.Status = "running"
+		q.save()
+		a.emitJobQueue(q)
+
+		j.Result = runQueuedStep(a, j.JobStep)
+		j.Finished = time.Now().Format(time.RFC3339)
+		if !resultSucceeded(j.Result) {
+			j.Status, q.State = "failed", "failed"
+			q.save()
+			a.emitJobQueue(q)
+			return fmt.Sprintf("❌ Error: Step %d/%d (%s) failed: %s Fix it and resume the queue.", i+1, total, j.Operation, j.Result)
+		}
+		j.Status = "done"
+		q.save()
+		a.emitJobQueue(q)
+
+		if !restartRequired(j.Result) {
+			continue
+		}
+		if q.remaining() == 0 {
+			break
+		}
+		if q.Reboots >= maxQueueReboots {
+			q.State = "failed"
+			q.save()
+			return fmt.Sprintf("❌ Error: The queue has already restarted the PC %d times; stopping to avoid a loop.", q.Reboots)
+		}
+		q.State = "awaiting_reboot"
+		q.Reboots++
+		q.save()
+		a.emitJobQueue(q)
+		msg := fmt.Sprintf("✅ Success: %d of %d steps done.", i+1, total)
+		if err := registerResumeTask(x); err != nil {
+			msg += " ⚠️ Could not register the resume task (" + err.Error() + "); open the Control Center after the restart to continue."
+		}
+		countdown := config.rebootCountdown()
+		if err := scheduleReboot(x, countdown, fmt.Sprintf("Triveni setup: restarting to continue (%d step(s) left).", q.remaining())); err != nil {
+			return msg + " ⚠️ Restart the PC to continue; scheduling the restart failed: " + err.Error()
+		}
+		return msg + fmt.Sprintf(" Restarting in %d seconds; the remaining %d step(s) continue after login.", countdown, q.remaining())
+	}
+
+	q.State = "done"
+	q.save()
+	a.emitJobQueue(q)
+	msg := fmt.Sprintf("✅ Success: All %d steps done", total)
+	if q.Profile != "" {
+		msg += " for profile " + q.Profile
+	}
+	if pendingReboot().Required {
+		return msg + ". Restart required."
+	}
+	return msg + "."
+}
+
+// claimJobQueue takes a queue that was waiting for a reboot, so only one process resumes it
+func claimJobQueue() *JobQueue {
+	unlock, err := lockSharedFile(jobQueuePath())
+	if err != nil {
+		return nil
+	}
+	defer unlock()
+	if !adminOwnedFile(jobQueuePath()) {
+		return nil
+	}
+	q, err := loadJobQueue()
+	if err != nil || q == nil || q.State != "awaiting_reboot" {
+		return nil
+	}
+	// Not restarted yet (the countdown was cancelled or is still running)
+	if updated, err := time.Parse(time.RFC3339, q.Updated); err != nil || updated.After(bootTime()) {
+		return nil
+	}
+	if problems := validateJobSteps(q.steps()); len(problems) > 0 {
+		q.State = "failed"
+		q.save()
+		return nil
+	}
+	q.State = "running"
+	if q.save() != nil {
+		return nil
+	}
+	return q
+}
+
+// resumeAfterReboot runs at startup and continues a queue that restarted the PC
+func (a *App) resumeAfterReboot() {
+	q := claimJobQueue()
+	if q == nil {
+		return
+	}
+	time.Sleep(resumeDelay)
+	result := a.runJobQueue(q)
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "job-queue-result", result)
+	}
+}
+
+// --- App methods ---
+
+// GetPendingReboot lists why a restart is needed: operations run since the last boot and Windows' own flags
+func (a *App) GetPendingReboot() PendingReboot {
+	return pendingReboot()
+}
+
+// ScheduleReboot restarts the PC after a countdown the user can see (seconds < 0 = reboot_countdown_seconds)
+func (a *App) ScheduleReboot(seconds int, reason string) (result string) {
+	defer beginAudit("ScheduleReboot", map[string]string{"seconds": fmt.Sprint(seconds), "reason": reason}, nil).finish(&result)
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to restart the PC."
+	}
+	if seconds < 0 {
+		config, _ := loadConfig("config.json")
+		seconds = config.rebootCountdown()
+	}
+	if reason == "" {
+		var parts []string
+		for _, r := range pendingReboot().Reasons {
+			parts = append(parts, r.Source)
+		}
+		reason = "Triveni Control Center: restart to finish " + listOrNone(parts)
+	}
+	if err := scheduleReboot(liveExecutor(), seconds, reason); err != nil {
+		return "❌ Error: Could not schedule the restart: " + err.Error()
+	}
+	return fmt.Sprintf("✅ Success: Restarting in %d seconds.", seconds)
+}
+
+// CancelReboot aborts a restart countdown. A queue waiting for the reboot stays queued.
+func (a *App) CancelReboot() (result string) {
+	defer beginAudit("CancelReboot", nil, nil).finish(&result)
+	cmd := exec.Command("shutdown", "/a")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.CombinedOutput()
+	os.Remove(rebootSchedulePath())
+	if err != nil {
+		return "❌ Error: No restart to cancel (" + strings.TrimSpace(string(output)) + ")"
+	}
+	return "✅ Success: Restart cancelled."
+}
+
+// GetProfiles returns the setup profiles declared in config.json
+func (a *App) GetProfiles() []SetupProfile {
+	config, err := loadConfig("config.json")
+	if err != nil || config.Profiles == nil {
+		return []SetupProfile{}
+	}
+	return config.Profiles
+}
+
+// RunProfile queues a profile's steps and runs them, restarting and resuming as steps require
+func (a *App) RunProfile(name string) (result string) {
+	defer beginAudit("RunProfile", map[string]string{"name": name}, nil).finish(&result)
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	p, ok := config.profile(name)
+	if !ok {
+		return "❌ Error: Profile " + name + " is not declared in config.json"
+	}
+	return a.startJobQueue(p.Name, p.Steps)
+}
+
+// RunJobQueue runs an ad-hoc list of steps the same way as a profile
+func (a *App) RunJobQueue(steps []JobStep) (result string) {
+	ops := make([]string, len(steps))
+	for i, st := range steps {
+		ops[i] = st.Operation
+	}
+	defer beginAudit("RunJobQueue", map[string]string{"steps": strings.Join(ops, ",")}, nil).finish(&result)
+	return a.startJobQueue("", steps)
+}
+
+func (a *App) startJobQueue(profile string, steps []JobStep) string {
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to run a job queue."
+	}
+	if problems := validateJobSteps(steps); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	if q, _ := loadJobQueue(); q != nil && q.active() {
+		return fmt.Sprintf("❌ Error: A queue (%s) is already %s. Cancel it first.", onOff(q.Profile != "", q.Profile, "ad hoc"), q.State)
+	}
+	q := newJobQueue(profile, steps)
+	if err := q.save(); err != nil {
+		return "❌ Error: Cannot save the job queue: " + err.Error()
+	}
+	return a.runJobQueue(q)
+}
+
+// GetJobQueue returns the current or last job queue (empty if there never was one)
+func (a *App) GetJobQueue() JobQueue {
+	q, err := loadJobQueue()
+	if err != nil || q == nil {
+		return JobQueue{Jobs: []QueuedJob{}}
+	}
+	return *q
+}
+
+// ResumeJobQueue retries a failed queue from the step that failed, or continues one still waiting for its reboot
+func (a *App) ResumeJobQueue() (result string) {
+	defer beginAudit("ResumeJobQueue", nil, nil).finish(&result)
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to run a job queue."
+	}
+	// Held until the queue finishes, so a step this process is running is never reset and run twice
+	if !jobQueueMu.TryLock() {
+		return "❌ Error: The job queue is already running."
+	}
+	defer jobQueueMu.Unlock()
+	q, err := takeJobQueueForResume()
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if q == nil {
+		return "ℹ️ No job queue to resume."
+	}
+	return a.runJobQueueLocked(q)
+}
+
+// takeJobQueueForResume resets the failed or interrupted steps and marks the queue running
+// under the file lock, so a process resuming after a reboot does not claim it as well
+func takeJobQueueForResume() (*JobQueue, error) {
+	unlock, err := lockSharedFile(jobQueuePath())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	q, err := loadJobQueue()
+	if err != nil {
+		return nil, err
+	}
+	if q == nil || q.State == "done" || q.State == "cancelled" {
+		return nil, nil
+	}
+	if !adminOwnedFile(jobQueuePath()) {
+		return nil, fmt.Errorf("%s was not written by an administrator", jobQueuePath())
+	}
+	if problems := validateJobSteps(q.steps()); len(problems) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	for i := range q.Jobs {
+		if q.Jobs[i].Status == "failed" || q.Jobs[i].Status == "running" {
+			q.Jobs[i].Status = "pending"
+		}
+	}
+	q.State = "running"
+	if err := q.save(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// CancelJobQueue drops the remaining steps and the resume task (a scheduled restart is left alone)
+func (a *App) CancelJobQueue() (result string) {
+	defer beginAudit("CancelJobQueue", nil, nil).finish(&result)
+	q, err := loadJobQueue()
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	if q == nil || !q.active() && q.State != "failed" {
+		return "ℹ️ No job queue to cancel."
+	}
+	removeResumeTask(liveExecutor())
+	q.State = "cancelled"
+	if err := q.save(); err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	a.emitJobQueue(q)
+	return fmt.Sprintf("✅ Success: Job queue cancelled (%d step(s) not run).", q.remaining())
+}
+
+// planProfile dry-runs every step of a profile in order
+func (a *App) planProfile(x *executor, name string) string {
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	p, ok := config.profile(name)
+	if !ok {
+		return "❌ Error: Profile " + name + " is not declared in config.json"
+	}
+	if problems := validateJobSteps(p.Steps); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+	for i, s := range p.Steps {
+		s = s.forProfile(p.Name)
+		x.record("note", fmt.Sprintf("Step %d/%d: %s %s", i+1, len(p.Steps), s.Operation, formatAuditMap(redactParams(s.Params))))
+		outcome := plannableOperations[s.Operation](a, x, s.Params)
+		if !resultSucceeded(outcome) {
+			return fmt.Sprintf("❌ Error: Step %d (%s) would fail: %s", i+1, s.Operation, outcome)
+		}
+		if restartRequired(outcome) && i < len(p.Steps)-1 {
+			x.record("note", "Restart, then continue at logon")
+		}
+	}
+	return fmt.Sprintf("✅ Success: Profile %s would run %d step(s).", p.Name, len(p.Steps))
+}
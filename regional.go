@@ -0,0 +1,523 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// RegionalSettings is the time zone, time sources, formats and locales a PC should use.
+// Set at the top level of config.json and overridden per profile; empty fields inherit.
+type RegionalSettings struct {
+	TimeZone           string   `json:"time_zone,omitempty"`           // Windows time zone ID, e.g. "India Standard Time"
+	NTPServers         []string `json:"ntp_servers,omitempty"`         // in order of preference, internal server first; empty keeps the PC's time source
+	MaxOffsetMs        int      `json:"max_offset_ms,omitempty"`       // largest clock offset accepted after a sync
+	ShortDate          string   `json:"short_date,omitempty"`          // sShortDate, e.g. "dd-MM-yyyy"
+	LongDate           string   `json:"long_date,omitempty"`           // sLongDate
+	ShortTime          string   `json:"short_time,omitempty"`          // sShortTime, "hh:mm tt" for 12-hour
+	LongTime           string   `json:"long_time,omitempty"`           // sTimeFormat
+	DecimalSymbol      string   `json:"decimal_symbol,omitempty"`      // sDecimal
+	ThousandsSeparator string   `json:"thousands_separator,omitempty"` // sThousand
+	DigitGrouping      string   `json:"digit_grouping,omitempty"`      // sGrouping, "3;2;0" for lakh/crore
+	UserLocale         string   `json:"user_locale,omitempty"`         // Set-Culture, e.g. "en-IN"
+	SystemLocale       string   `json:"system_locale,omitempty"`       // Set-WinSystemLocale; needs a restart
+}
+
+// RegionalStatus is what the PC currently uses, read back from Windows
+type RegionalStatus struct {
+	TimeZone           string           `json:"time_zone"`
+	NTPServers         []string         `json:"ntp_servers"`
+	SyncType           string           `json:"sync_type"` // NTP, NT5DS (domain hierarchy), NoSync
+	SyncSource         string           `json:"sync_source"`
+	LastSync           string           `json:"last_sync"`
+	OffsetMs           *float64         `json:"offset_ms"` // nil when no configured server answered
+	OffsetServer       string           `json:"offset_server"`
+	ShortDate          string           `json:"short_date"`
+	LongDate           string           `json:"long_date"`
+	ShortTime          string           `json:"short_time"`
+	LongTime           string           `json:"long_time"`
+	DecimalSymbol      string           `json:"decimal_symbol"`
+	ThousandsSeparator string           `json:"thousands_separator"`
+	DigitGrouping      string           `json:"digit_grouping"`
+	UserLocale         string           `json:"user_locale"`
+	SystemLocale       string           `json:"system_locale"`
+	Expected           RegionalSettings `json:"expected"`
+	Mismatches         []string         `json:"mismatches"`
+	Error              string           `json:"error,omitempty"`
+}
+
+const (
+	internationalKey = `Control Panel\International`
+	w32timeParamsKey = `SYSTEM\CurrentControlSet\Services\W32Time\Parameters`
+
+	defaultMaxOffsetMs = 1000
+	offsetSamples      = 3
+)
+
+// Used when config.json has no regional section: India time and a resync against the PC's
+// own time source, as SyncTime always did, and the 12-hour clock it announced (it used to
+// write HH:mm). The time source is only changed when ntp_servers is configured.
+var defaultRegionalSettings = RegionalSettings{
+	TimeZone:    "India Standard Time",
+	MaxOffsetMs: defaultMaxOffsetMs,
+	ShortTime:   "hh:mm tt",
+	LongTime:    "hh:mm:ss tt",
+}
+
+// Formats and the International value each one is stored in
+var regionalFormatValues = []struct {
+	name  string
+	value string
+	get   func(*RegionalSettings) *string
+}{
+	{"short date", "sShortDate", func(r *RegionalSettings) *string { return &r.ShortDate }},
+	{"long date", "sLongDate", func(r *RegionalSettings) *string { return &r.LongDate }},
+	{"short time", "sShortTime", func(r *RegionalSettings) *string { return &r.ShortTime }},
+	{"long time", "sTimeFormat", func(r *RegionalSettings) *string { return &r.LongTime }},
+	{"decimal symbol", "sDecimal", func(r *RegionalSettings) *string { return &r.DecimalSymbol }},
+	{"thousands separator", "sThousand", func(r *RegionalSettings) *string { return &r.ThousandsSeparator }},
+	{"digit grouping", "sGrouping", func(r *RegionalSettings) *string { return &r.DigitGrouping }},
+}
+
+var (
+	localeRe     = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+	ntpServerRe  = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?$`)
+	groupingRe   = regexp.MustCompile(`^[0-9](;[0-9])*$`)
+	stripchartRe = regexp.MustCompile(`(?m)^\s*\d{1,2}:\d{2}:\d{2}[^,]*,\s*([+-]?\d+(?:\.\d+)?)s\s*$`)
+)
+
+// overlay returns r with every field set in o replacing the inherited one
+func (r RegionalSettings) overlay(o *RegionalSettings) RegionalSettings {
+	if o == nil {
+		return r
+	}
+	if o.TimeZone != "" {
+		r.TimeZone = o.TimeZone
+	}
+	if len(o.NTPServers) > 0 {
+		r.NTPServers = o.NTPServers
+	}
+	if o.MaxOffsetMs > 0 {
+		r.MaxOffsetMs = o.MaxOffsetMs
+	}
+	for _, f := range regionalFormatValues {
+		if v := *f.get(o); v != "" {
+			*f.get(&r) = v
+		}
+	}
+	if o.UserLocale != "" {
+		r.UserLocale = o.UserLocale
+	}
+	if o.SystemLocale != "" {
+		r.SystemLocale = o.SystemLocale
+	}
+	return r
+}
+
+// regionalSettings resolves the settings for a profile ("" = the config-wide settings)
+func (c *Config) regionalSettings(profile string) (RegionalSettings, error) {
+	r := defaultRegionalSettings
+	if c == nil {
+		return r, nil
+	}
+	r = r.overlay(c.Regional)
+	if profile != "" {
+		p, ok := c.profile(profile)
+		if !ok {
+			return r, fmt.Errorf("profile %s is not declared in config.json", profile)
+		}
+		r = r.overlay(p.Regional)
+	}
+	return r, nil
+}
+
+// validate reports every field Windows would reject or misread
+func (r RegionalSettings) validate() []string {
+	var problems []string
+	if strings.ContainsAny(r.TimeZone, `"'`+"\r\n") {
+		problems = append(problems, "time zone "+r.TimeZone+" is not a valid ID")
+	}
+	for _, s := range r.NTPServers {
+		if !ntpServerRe.MatchString(s) {
+			problems = append(problems, "NTP server "+s+" is not a host name or IP address")
+		}
+	}
+	for _, f := range regionalFormatValues {
+		v := *f.get(&r)
+		if v == "" {
+			continue
+		}
+		if strings.ContainsAny(v, "\r\n\x00") || len(v) > 80 {
+			problems = append(problems, f.name+" format "+strconv.Quote(v)+" is not valid")
+		}
+	}
+	for _, f := range []struct{ name, v, need string }{
+		{"short date", r.ShortDate, "dMy"}, {"long date", r.LongDate, "dMy"},
+		{"short time", r.ShortTime, "hHm"}, {"long time", r.LongTime, "hHm"},
+	} {
+		if f.v != "" && !strings.ContainsAny(f.v, f.need) {
+			problems = append(problems, f.name+" format "+strconv.Quote(f.v)+" has no "+onOff(f.need == "dMy", "date", "time")+" fields")
+		}
+	}
+	if r.DigitGrouping != "" && !groupingRe.MatchString(r.DigitGrouping) {
+		problems = append(problems, "digit grouping "+r.DigitGrouping+` must look like "3;0" or "3;2;0"`)
+	}
+	if r.DecimalSymbol != "" && r.DecimalSymbol == r.ThousandsSeparator {
+		problems = append(problems, "the decimal symbol and thousands separator are the same")
+	}
+	for _, l := range []struct{ name, v string }{{"user locale", r.UserLocale}, {"system locale", r.SystemLocale}} {
+		if l.v != "" && !localeRe.MatchString(l.v) {
+			problems = append(problems, l.name+" "+l.v+" is not a locale name like en-IN")
+		}
+	}
+	return problems
+}
+
+// twelveHour tells whether a time format uses the 12-hour clock
+func twelveHour(format string) bool {
+	return strings.Contains(format, "h") && !strings.Contains(format, "H")
+}
+
+// describe is a one-line summary for result messages
+func (r RegionalSettings) describe() string {
+	parts := []string{}
+	if r.TimeZone != "" {
+		parts = append(parts, r.TimeZone)
+	}
+	if r.ShortTime != "" {
+		parts = append(parts, onOff(twelveHour(r.ShortTime), "12-hour", "24-hour")+" clock")
+	}
+	if r.ShortDate != "" {
+		parts = append(parts, "dates "+r.ShortDate)
+	}
+	if r.UserLocale != "" {
+		parts = append(parts, "locale "+r.UserLocale)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ntpPeerList is w32tm's manualpeerlist; 0x9 = client mode with the special poll interval
+func ntpPeerList(servers []string) string {
+	peers := make([]string, len(servers))
+	for i, s := range servers {
+		peers[i] = s + ",0x9"
+	}
+	return strings.Join(peers, " ")
+}
+
+// parseNtpServers turns the NtpServer registry value back into host names
+func parseNtpServers(value string) []string {
+	var servers []string
+	for _, peer := range strings.Fields(value) {
+		if host, _, _ := strings.Cut(peer, ","); host != "" {
+			servers = append(servers, host)
+		}
+	}
+	return servers
+}
+
+// parseStripchart reads the offsets from `w32tm /stripchart /dataonly` output
+func parseStripchart(out string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, m := range stripchartRe.FindAllStringSubmatch(out, -1) {
+		s, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		offsets = append(offsets, time.Duration(s*float64(time.Second)))
+	}
+	if len(offsets) == 0 {
+		msg := "no samples"
+		for _, line := range strings.Split(out, "\n") {
+			if line = strings.TrimSpace(line); strings.Contains(strings.ToLower(line), "error") {
+				msg = line
+				break
+			}
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return offsets, nil
+}
+
+// medianOffset is robust against one delayed sample
+func medianOffset(offsets []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// parseW32tmStatus reads the fields of `w32tm /query /status` that matter here
+func parseW32tmStatus(out string) (source, lastSync string) {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Source":
+			source = value
+		case "Last Successful Sync Time":
+			lastSync = value
+		}
+	}
+	return source, lastSync
+}
+
+// domainTime tells whether w32time follows the domain hierarchy, which ntp_servers must not override
+func domainTime() bool {
+	syncType, _ := readRegValue(registry.LOCAL_MACHINE, w32timeParamsKey, "Type")
+	return strings.EqualFold(syncType, "NT5DS")
+}
+
+// timeSources is where w32time currently takes its time from: the source it reports
+// (a domain controller under NT5DS), then the configured peers
+func timeSources() []string {
+	var sources []string
+	cmd := exec.Command("w32tm", "/query", "/source")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if out, err := cmd.Output(); err == nil {
+		// "Local CMOS Clock" and "Free-running System Clock" are not servers
+		if host, _, _ := strings.Cut(strings.TrimSpace(string(out)), ","); host != "" && !strings.Contains(host, " ") {
+			sources = append(sources, host)
+		}
+	}
+	ntp, _ := readRegValue(registry.LOCAL_MACHINE, w32timeParamsKey, "NtpServer")
+	for _, peer := range parseNtpServers(ntp) {
+		if len(sources) == 0 || !strings.EqualFold(peer, sources[0]) {
+			sources = append(sources, peer)
+		}
+	}
+	return sources
+}
+
+// measureClockOffset asks each server in turn until one answers
+func measureClockOffset(x *executor, servers []string) (time.Duration, string, error) {
+	var errs []string
+	for _, s := range servers {
+		cmd := exec.Command("w32tm", "/stripchart", "/computer:"+s, "/dataonly", "/samples:"+strconv.Itoa(offsetSamples))
+		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+		out, err := x.output(cmd)
+		if x.dryRun {
+			return 0, s, nil
+		}
+		offsets, perr := parseStripchart(string(out))
+		if perr != nil {
+			if err != nil {
+				perr = fmt.Errorf("%v (%v)", perr, err)
+			}
+			errs = append(errs, s+": "+perr.Error())
+			continue
+		}
+		return medianOffset(offsets), s, nil
+	}
+	return 0, "", fmt.Errorf("no time server answered: %s", strings.Join(errs, "; "))
+}
+
+func formatOffset(d time.Duration) string {
+	return fmt.Sprintf("%+.1f ms", float64(d)/float64(time.Millisecond))
+}
+
+// applyRegionalSettings sets everything in r, then syncs the clock and checks the offset
+func applyRegionalSettings(x *executor, r RegionalSettings) string {
+	if problems := r.validate(); len(problems) > 0 {
+		return "❌ Error: " + strings.Join(problems, "; ")
+	}
+
+	if r.TimeZone != "" {
+		if out, err := x.output(psCommand("Set-TimeZone -Id " + psQuote(r.TimeZone) + " -ErrorAction Stop")); err != nil {
+			return "❌ Error: Cannot set the time zone " + r.TimeZone + ": " + strings.TrimSpace(string(out))
+		}
+	}
+
+	// Set-Culture replaces the format overrides, so it goes before them
+	if r.UserLocale != "" {
+		if out, err := x.output(psCommand("Set-Culture -CultureInfo " + psQuote(r.UserLocale) + " -ErrorAction Stop")); err != nil {
+			return "❌ Error: Cannot set the user locale " + r.UserLocale + ": " + strings.TrimSpace(string(out))
+		}
+	}
+	for _, f := range regionalFormatValues {
+		v := *f.get(&r)
+		if v == "" {
+			continue
+		}
+		if err := x.regSet(registry.CURRENT_USER, internationalKey, f.value, v); err != nil {
+			return "❌ Error: Cannot set the " + f.name + " format: " + err.Error()
+		}
+	}
+	if r.LongTime != "" {
+		// iTime is what older programs read to pick the 12- or 24-hour clock
+		if err := x.regSet(registry.CURRENT_USER, internationalKey, "iTime", onOff(twelveHour(r.LongTime), "0", "1")); err != nil {
+			return "❌ Error: Cannot set the clock type: " + err.Error()
+		}
+	}
+
+	restart := false
+	if r.SystemLocale != "" {
+		current, _ := runPowerShell("(Get-WinSystemLocale).Name")
+		if x.dryRun || !strings.EqualFold(current, r.SystemLocale) {
+			if out, err := x.output(psCommand("Set-WinSystemLocale -SystemLocale " + psQuote(r.SystemLocale) + " -ErrorAction Stop")); err != nil {
+				return "❌ Error: Cannot set the system locale " + r.SystemLocale + ": " + strings.TrimSpace(string(out))
+			}
+			restart = true
+		}
+	}
+
+	msg := "✅ Success: Regional settings applied (" + r.describe() + ")."
+	servers, note := r.NTPServers, ""
+	if len(servers) > 0 && domainTime() {
+		servers, note = nil, " ntp_servers not applied: this PC takes its time from the domain (NT5DS)."
+	}
+	// An unverified or still-wrong clock fails the operation; the formats above stay applied
+	offset, server, err := syncClock(x, servers)
+	switch {
+	case err != nil:
+		msg = "❌ Error: Regional settings applied (" + r.describe() + "), but time sync could not be verified: " + err.Error()
+	case x.dryRun:
+	case math.Abs(float64(offset/time.Millisecond)) > float64(r.maxOffsetMs()):
+		msg = fmt.Sprintf("❌ Error: Regional settings applied (%s), but the clock is still %s off %s (limit ±%d ms).", r.describe(), formatOffset(offset), server, r.maxOffsetMs())
+	default:
+		msg += fmt.Sprintf(" Clock synced with %s, offset %s.", server, formatOffset(offset))
+	}
+	msg += note
+	if restart {
+		msg += " Restart required for the system locale " + r.SystemLocale + "."
+	}
+	return msg
+}
+
+func (r RegionalSettings) maxOffsetMs() int {
+	if r.MaxOffsetMs > 0 {
+		return r.MaxOffsetMs
+	}
+	return defaultMaxOffsetMs
+}
+
+// syncClock points w32time at the servers (none = keep its time source), forces a resync
+// and measures the remaining offset
+func syncClock(x *executor, servers []string) (time.Duration, string, error) {
+	steps := [][]string{
+		{"sc", "config", "w32time", "start=", "auto"},
+		{"net", "start", "w32time"},
+	}
+	if len(servers) > 0 {
+		steps = append(steps, []string{"w32tm", "/config", "/manualpeerlist:" + ntpPeerList(servers), "/syncfromflags:manual", "/reliable:no", "/update"})
+	}
+	steps = append(steps, []string{"w32tm", "/resync", "/force"})
+	for i, args := range steps {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+		out, err := x.output(cmd)
+		// net start fails when the service is already running
+		if err != nil && i != 1 {
+			return 0, "", fmt.Errorf("%s failed: %s", strings.Join(args[:2], " "), strings.TrimSpace(string(out)))
+		}
+	}
+	if len(servers) == 0 {
+		servers = timeSources()
+	}
+	return measureClockOffset(x, servers)
+}
+
+func psCommand(script string) *exec.Cmd {
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd
+}
+
+// readRegionalStatus reads the current values back and compares them with r
+func readRegionalStatus(r RegionalSettings) RegionalStatus {
+	s := RegionalStatus{Expected: r, Mismatches: []string{}}
+	if tz, err := snapshotTimeZone(); err == nil {
+		s.TimeZone = tz
+	} else {
+		s.Error = "Cannot read the time zone: " + err.Error()
+	}
+	ntp, _ := readRegValue(registry.LOCAL_MACHINE, w32timeParamsKey, "NtpServer")
+	s.NTPServers = parseNtpServers(ntp)
+	s.SyncType, _ = readRegValue(registry.LOCAL_MACHINE, w32timeParamsKey, "Type")
+	status := exec.Command("w32tm", "/query", "/status")
+	status.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if out, err := status.CombinedOutput(); err == nil {
+		s.SyncSource, s.LastSync = parseW32tmStatus(string(out))
+	}
+	current := RegionalSettings{}
+	for _, f := range regionalFormatValues {
+		*f.get(&current), _ = readRegValue(registry.CURRENT_USER, internationalKey, f.value)
+	}
+	s.ShortDate, s.LongDate, s.ShortTime, s.LongTime = current.ShortDate, current.LongDate, current.ShortTime, current.LongTime
+	s.DecimalSymbol, s.ThousandsSeparator, s.DigitGrouping = current.DecimalSymbol, current.ThousandsSeparator, current.DigitGrouping
+	s.UserLocale, _ = readRegValue(registry.CURRENT_USER, internationalKey, "LocaleName")
+	if out, err := runPowerShell("(Get-WinSystemLocale).Name"); err == nil {
+		s.SystemLocale = out
+	}
+
+	servers := r.NTPServers
+	if len(servers) == 0 || domainTime() {
+		servers = timeSources()
+	}
+	if offset, server, err := measureClockOffset(liveExecutor(), servers); err == nil {
+		ms := float64(offset) / float64(time.Millisecond)
+		s.OffsetMs, s.OffsetServer = &ms, server
+	}
+
+	mismatch := func(name, want, got string) {
+		if want != "" && !strings.EqualFold(want, got) {
+			s.Mismatches = append(s.Mismatches, fmt.Sprintf("%s is %q, expected %q", name, got, want))
+		}
+	}
+	mismatch("time zone", r.TimeZone, s.TimeZone)
+	for _, f := range regionalFormatValues {
+		mismatch(f.name, *f.get(&r), *f.get(&current))
+	}
+	mismatch("user locale", r.UserLocale, s.UserLocale)
+	mismatch("system locale", r.SystemLocale, s.SystemLocale)
+	if len(r.NTPServers) > 0 && !domainTime() && strings.Join(r.NTPServers, " ") != strings.Join(s.NTPServers, " ") {
+		s.Mismatches = append(s.Mismatches, fmt.Sprintf("time servers are %s, expected %s", listOrNone(s.NTPServers), strings.Join(r.NTPServers, ", ")))
+	}
+	if s.OffsetMs == nil {
+		s.Mismatches = append(s.Mismatches, "no time server answered, the clock offset is unknown")
+	} else if math.Abs(*s.OffsetMs) > float64(r.maxOffsetMs()) {
+		s.Mismatches = append(s.Mismatches, fmt.Sprintf("the clock is %s off %s", formatOffset(time.Duration(*s.OffsetMs*float64(time.Millisecond))), s.OffsetServer))
+	}
+	return s
+}
+
+// GetRegionalSettings reads back the current regional settings and compares them with a profile's ("" = config-wide)
+func (a *App) GetRegionalSettings(profile string) RegionalStatus {
+	config, _ := loadConfig("config.json")
+	r, err := config.regionalSettings(profile)
+	if err != nil {
+		return RegionalStatus{Expected: r, Mismatches: []string{}, Error: err.Error()}
+	}
+	return readRegionalStatus(r)
+}
+
+// ApplyRegionalSettings applies a profile's regional settings ("" = config-wide)
+func (a *App) ApplyRegionalSettings(profile string) (result string) {
+	defer beginAudit("ApplyRegionalSettings", map[string]string{"profile": profile}, timeAuditProbe).snapshot(timeChangeScope).finish(&result)
+	return a.applyRegionalSettings(liveExecutor(), profile)
+}
+
+func (a *App) applyRegionalSettings(x *executor, profile string) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to sync time and regional settings."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	r, err := config.regionalSettings(profile)
+	if err != nil {
+		return "❌ Error: " + err.Error()
+	}
+	return applyRegionalSettings(x, r)
+}
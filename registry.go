@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// regRef points at a registry value. Name "*" means every value under the key.
+type regRef struct {
+	Root registry.Key
+	Path string
+	Name string
+}
+
+func (r regRef) String() string {
+	name := r.Name
+	if name == "" {
+		name = "(Default)"
+	}
+	return regRootName(r.Root) + "\\" + r.Path + "\\" + name
+}
+
+func regRootName(k registry.Key) string {
+	switch k {
+	case registry.LOCAL_MACHINE:
+		return "HKLM"
+	case registry.CURRENT_USER:
+		return "HKCU"
+	case registry.USERS:
+		return "HKU"
+	case registry.CLASSES_ROOT:
+		return "HKCR"
+	}
+	return fmt.Sprintf("0x%X", uint32(k))
+}
+
+// readRegValue returns a registry value as display text and whether it exists
+func readRegValue(root registry.Key, path, name string) (string, bool) {
+	k, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return "", false
+	}
+	defer k.Close()
+	return formatRegValue(k, name)
+}
+
+func formatRegValue(k registry.Key, name string) (string, bool) {
+	_, valType, err := k.GetValue(name, nil)
+	if err != nil {
+		return "", false
+	}
+	switch valType {
+	case registry.DWORD, registry.QWORD:
+		v, _, err := k.GetIntegerValue(name)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("%d", v), true
+	case registry.SZ, registry.EXPAND_SZ:
+		v, _, err := k.GetStringValue(name)
+		if err != nil {
+			return "", false
+		}
+		return v, true
+	case registry.MULTI_SZ:
+		v, _, err := k.GetStringsValue(name)
+		if err != nil {
+			return "", false
+		}
+		return strings.Join(v, ";"), true
+	default:
+		v, _, err := k.GetBinaryValue(name)
+		if err != nil {
+			return "", false
+		}
+		return hex.EncodeToString(v), true
+	}
+}
+
+// regProbe returns a snapshot function for audit before/after values
+func regProbe(refs ...regRef) func() map[string]string {
+	return func() map[string]string {
+		values := map[string]string{}
+		for _, r := range refs {
+			if r.Name != "*" {
+				if v, ok := readRegValue(r.Root, r.Path, r.Name); ok {
+					values[r.String()] = v
+				} else {
+					values[r.String()] = "(not set)"
+				}
+				continue
+			}
+
+			k, err := registry.OpenKey(r.Root, r.Path, registry.QUERY_VALUE)
+			if err != nil {
+				values[regRootName(r.Root)+"\\"+r.Path] = "(not set)"
+				continue
+			}
+			names, _ := k.ReadValueNames(-1)
+			for _, n := range names {
+				if v, ok := formatRegValue(k, n); ok {
+					values[regRef{r.Root, r.Path, n}.String()] = v
+				}
+			}
+			k.Close()
+		}
+		return values
+	}
+}
+
+// --- Snapshots (for Rollback) ---
+
+var procRegSetValueExW = modadvapi32.NewProc("RegSetValueExW")
+
+// RegValueData is a raw registry value. Data is hex; password values are DPAPI-protected.
+type RegValueData struct {
+	Name      string `json:"name"`
+	Type      uint32 `json:"type"`
+	Data      string `json:"data"`
+	Protected bool   `json:"protected"`
+}
+
+// RegSnapshot is the exact prior state of one value, or of every value under a key (Name "*")
+type RegSnapshot struct {
+	Root       string         `json:"root"`
+	Path       string         `json:"path"`
+	Name       string         `json:"name"`
+	KeyExisted bool           `json:"key_existed"`
+	Values     []RegValueData `json:"values"`
+}
+
+func regRootFromName(name string) (registry.Key, error) {
+	switch strings.ToUpper(name) {
+	case "HKLM":
+		return registry.LOCAL_MACHINE, nil
+	case "HKCU":
+		return registry.CURRENT_USER, nil
+	case "HKU":
+		return registry.USERS, nil
+	case "HKCR":
+		return registry.CLASSES_ROOT, nil
+	}
+	return 0, fmt.Errorf("unknown registry root %q", name)
+}
+
+// isSensitiveRegValue marks values whose data must not be stored in clear text
+func isSensitiveRegValue(name string) bool {
+	return strings.Contains(strings.ToLower(name), "password")
+}
+
+func readRawRegValue(k registry.Key, name string) (RegValueData, error) {
+	size, valType, err := k.GetValue(name, nil)
+	if err != nil {
+		return RegValueData{}, err
+	}
+	buf := make([]byte, size)
+	if size > 0 {
+		if _, _, err := k.GetValue(name, buf); err != nil {
+			return RegValueData{}, err
+		}
+	}
+
+	v := RegValueData{Name: name, Type: valType}
+	if isSensitiveRegValue(name) && len(buf) > 0 {
+		protected, err := dpapiProtect(buf)
+		if err != nil {
+			return RegValueData{}, err
+		}
+		buf, v.Protected = protected, true
+	}
+	v.Data = hex.EncodeToString(buf)
+	return v, nil
+}
+
+// snapshotRegistry records the current state of ref
+func snapshotRegistry(ref regRef) (RegSnapshot, error) {
+	snap := RegSnapshot{Root: regRootName(ref.Root), Path: ref.Path, Name: ref.Name}
+	k, err := registry.OpenKey(ref.Root, ref.Path, registry.QUERY_VALUE)
+	if err == registry.ErrNotExist {
+		return snap, nil
+	}
+	if err != nil {
+		return snap, err
+	}
+	defer k.Close()
+	snap.KeyExisted = true
+
+	names := []string{ref.Name}
+	if ref.Name == "*" {
+		if names, err = k.ReadValueNames(-1); err != nil {
+			return snap, err
+		}
+	}
+	for _, n := range names {
+		v, err := readRawRegValue(k, n)
+		if err == registry.ErrNotExist {
+			continue
+		}
+		if err != nil {
+			return snap, err
+		}
+		snap.Values = append(snap.Values, v)
+	}
+	return snap, nil
+}
+
+// restore puts the registry back exactly as captured: values that did not exist are deleted,
+// and a whole-key snapshot of a key that did not exist deletes the key.
+func (s RegSnapshot) restore() error {
+	root, err := regRootFromName(s.Root)
+	if err != nil {
+		return err
+	}
+
+	if !s.KeyExisted {
+		if s.Name == "*" {
+			err := registry.DeleteKey(root, s.Path)
+			if err == registry.ErrNotExist {
+				return nil
+			}
+			return err
+		}
+		k, err := registry.OpenKey(root, s.Path, registry.SET_VALUE)
+		if err == registry.ErrNotExist {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer k.Close()
+		if err := k.DeleteValue(s.Name); err != nil && err != registry.ErrNotExist {
+			return err
+		}
+		return nil
+	}
+
+	k, _, err := registry.CreateKey(root, s.Path, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	keep := map[string]bool{}
+	for _, v := range s.Values {
+		keep[strings.ToLower(v.Name)] = true
+	}
+
+	// Remove values that were added since the snapshot
+	var existing []string
+	if s.Name == "*" {
+		existing, _ = k.ReadValueNames(-1)
+	} else {
+		existing = []string{s.Name}
+	}
+	for _, n := range existing {
+		if !keep[strings.ToLower(n)] {
+			if err := k.DeleteValue(n); err != nil && err != registry.ErrNotExist {
+				return err
+			}
+		}
+	}
+
+	for _, v := range s.Values {
+		data, err := hex.DecodeString(v.Data)
+		if err != nil {
+			return err
+		}
+		if v.Protected {
+			if data, err = dpapiUnprotect(data); err != nil {
+				return err
+			}
+		}
+		if err := setRawRegValue(k, v.Name, v.Type, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setRawRegValue(k registry.Key, name string, valType uint32, data []byte) error {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	var p *byte
+	if len(data) > 0 {
+		p = &data[0]
+	}
+	r, _, _ := procRegSetValueExW.Call(uintptr(k), uintptr(unsafe.Pointer(namePtr)), 0, uintptr(valType), uintptr(unsafe.Pointer(p)), uintptr(len(data)))
+	if r != 0 {
+		return windows.Errno(r)
+	}
+	return nil
+}
+
+// writeRegList replaces a Group Policy list key: one numbered REG_SZ value ("1", "2", ...) per entry
+func writeRegList(x *executor, root registry.Key, path string, values []string) error {
+	if err := x.regDeleteKey(root, path); err != nil {
+		return err
+	}
+	for i, v := range values {
+		if err := x.regSet(root, path, fmt.Sprint(i+1), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRegList returns a Group Policy list key's values in numeric order
+func readRegList(root registry.Key, path string) []string {
+	k, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer k.Close()
+	names, _ := k.ReadValueNames(-1)
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	var values []string
+	for _, n := range names {
+		if v, ok := formatRegValue(k, n); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// urlFilter is one parsed Chrome URL filter: [scheme://][.]host[:port][/path][?query]
+type urlFilter struct {
+	Raw        string
+	Scheme     string // "" or "*" = any scheme
+	Host       string // "*" = any host; "" only for file://
+	Exact      bool   // leading "." - do not match subdomains
+	Port       string // "" = any port
+	Path       string // prefix match
+	Query      []string
+	Normalized string
+	Note       string // what normalization changed
+}
+
+// URLFilterEntry reports how one allowlist/blocklist entry was understood
+type URLFilterEntry struct {
+	Input      string `json:"input"`
+	Valid      bool   `json:"valid"`
+	Normalized string `json:"normalized"`
+	Reason     string `json:"reason"` // why the entry is invalid
+	Note       string `json:"note"`   // what normalization changed
+	Scheme     string `json:"scheme"`
+	Host       string `json:"host"`
+	Subdomains bool   `json:"subdomains"`
+	Port       string `json:"port"`
+	Path       string `json:"path"`
+	Query      string `json:"query"`
+}
+
+// URLFilterParseResult is the outcome of parsing a list of entries
+type URLFilterParseResult struct {
+	Entries    []URLFilterEntry `json:"entries"`
+	Normalized []string         `json:"normalized"` // valid entries, deduplicated
+	Invalid    int              `json:"invalid"`
+}
+
+// URLAccessPreview tells whether a URL would be reachable under a filter list
+type URLAccessPreview struct {
+	URL     string `json:"url"`
+	Allowed bool   `json:"allowed"`
+	List    string `json:"list"` // allowlist, blocklist or "" when nothing matched
+	Filter  string `json:"filter"`
+	Reason  string `json:"reason"`
+}
+
+var (
+	urlSchemePattern = regexp.MustCompile(`^([a-z][a-z0-9+.-]*|\*)$`)
+	hostLabelInvalid = `"<>\^` + "`" + `{|}%,;'()[]!$&+=~`
+	defaultURLPorts  = map[string]string{"http": "80", "https": "443", "ftp": "21", "ws": "80", "wss": "443"}
+)
+
+// parseURLFilter parses and normalizes one entry, explaining what is wrong with invalid ones
+func parseURLFilter(raw string) (urlFilter, error) {
+	f := urlFilter{Raw: raw}
+	p := strings.TrimSpace(raw)
+	if p == "" {
+		return f, fmt.Errorf("empty entry")
+	}
+	for _, r := range p {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return f, fmt.Errorf("contains whitespace; separate entries with commas")
+		}
+	}
+	if p == "*" {
+		f.Host, f.Normalized = "*", "*"
+		return f, nil
+	}
+
+	rest := p
+	if i := strings.Index(rest, "://"); i >= 0 {
+		f.Scheme, rest = strings.ToLower(rest[:i]), rest[i+3:]
+		if !urlSchemePattern.MatchString(f.Scheme) {
+			return f, fmt.Errorf("invalid scheme %q", p[:i])
+		}
+	} else if i := strings.Index(rest, ":"); i > 0 && !strings.ContainsAny(rest[:i], "./[") {
+		if _, err := strconv.Atoi(strings.SplitN(rest[i+1:], "/", 2)[0]); err != nil {
+			return f, fmt.Errorf("scheme must be followed by \"://\"")
+		}
+	}
+
+	if i := strings.Index(rest, "?"); i >= 0 {
+		query := rest[i+1:]
+		rest = rest[:i]
+		for _, tok := range strings.Split(query, "&") {
+			if tok == "" {
+				continue
+			}
+			if strings.Contains(strings.TrimSuffix(tok, "*"), "*") {
+				return f, fmt.Errorf("query value %q: \"*\" is only allowed at the end", tok)
+			}
+			f.Query = append(f.Query, tok)
+		}
+	}
+
+	hostport := rest
+	if i := strings.Index(rest, "/"); i >= 0 {
+		hostport, f.Path = rest[:i], rest[i:]
+	}
+	if strings.Contains(f.Path, "*") {
+		if !strings.HasSuffix(f.Path, "*") || strings.Count(f.Path, "*") > 1 {
+			return f, fmt.Errorf("path %q: paths match by prefix and do not support \"*\"", f.Path)
+		}
+		f.Path = strings.TrimSuffix(f.Path, "*")
+		f.Note = "trailing \"*\" removed from the path (paths already match by prefix)"
+	}
+	if f.Path == "/" && len(f.Query) == 0 {
+		f.Path = ""
+	}
+
+	host := hostport
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end < 0 {
+			return f, fmt.Errorf("unterminated IPv6 address")
+		}
+		if after := host[end+1:]; after != "" {
+			if !strings.HasPrefix(after, ":") {
+				return f, fmt.Errorf("unexpected %q after IPv6 address", after)
+			}
+			f.Port = after[1:]
+		}
+		host = host[:end+1]
+	} else if i := strings.LastIndex(host, ":"); i >= 0 {
+		host, f.Port = host[:i], host[i+1:]
+	}
+	if hostport != "" && strings.HasSuffix(hostport, ":") {
+		return f, fmt.Errorf("empty port")
+	}
+	if f.Port != "" {
+		if n, err := strconv.Atoi(f.Port); err != nil || n < 1 || n > 65535 {
+			return f, fmt.Errorf("port %q must be 1-65535 (omit it to match any port)", f.Port)
+		}
+	}
+
+	if strings.HasPrefix(host, ".") {
+		f.Exact, host = true, host[1:]
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	switch {
+	case host == "" && f.Scheme == "file":
+	case host == "":
+		return f, fmt.Errorf("no host")
+	case host == "*":
+		if f.Exact {
+			return f, fmt.Errorf("\".*\" is not a valid host; use \"*\" for every host")
+		}
+	case strings.Contains(host, "*"):
+		if strings.HasPrefix(host, "*.") {
+			return f, fmt.Errorf("wildcards are only allowed as the whole host; use %q to include subdomains", host[2:])
+		}
+		return f, fmt.Errorf("wildcards are only allowed as the whole host (\"*\")")
+	case strings.HasPrefix(host, "["):
+	default:
+		if strings.ContainsAny(host, hostLabelInvalid) {
+			return f, fmt.Errorf("host %q contains invalid characters", host)
+		}
+		for _, label := range strings.Split(host, ".") {
+			if label == "" {
+				return f, fmt.Errorf("host %q has an empty label", host)
+			}
+			if len(label) > 63 {
+				return f, fmt.Errorf("host label %q is longer than 63 characters", label)
+			}
+			if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+				return f, fmt.Errorf("host label %q cannot start or end with \"-\"", label)
+			}
+		}
+	}
+	f.Host = host
+
+	var b strings.Builder
+	if f.Scheme != "" {
+		b.WriteString(f.Scheme + "://")
+	}
+	if f.Exact {
+		b.WriteString(".")
+	}
+	b.WriteString(f.Host)
+	if f.Port != "" {
+		b.WriteString(":" + f.Port)
+	}
+	b.WriteString(f.Path)
+	if len(f.Query) > 0 {
+		b.WriteString("?" + strings.Join(f.Query, "&"))
+	}
+	f.Normalized = b.String()
+	return f, nil
+}
+
+// splitURLFilters splits admin input on commas and newlines
+func splitURLFilters(input string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseURLFilters parses every entry; Normalized holds the valid ones without duplicates
+func parseURLFilters(entries []string) URLFilterParseResult {
+	result := URLFilterParseResult{Entries: []URLFilterEntry{}, Normalized: []string{}}
+	seen := map[string]bool{}
+	for _, raw := range entries {
+		f, err := parseURLFilter(raw)
+		e := URLFilterEntry{Input: raw, Valid: err == nil}
+		if err != nil {
+			e.Reason = err.Error()
+			result.Invalid++
+			result.Entries = append(result.Entries, e)
+			continue
+		}
+		e.Normalized, e.Note = f.Normalized, f.Note
+		e.Scheme, e.Host, e.Subdomains, e.Port, e.Path = f.Scheme, f.Host, !f.Exact && f.Host != "*", f.Port, f.Path
+		e.Query = strings.Join(f.Query, "&")
+		if seen[f.Normalized] {
+			e.Note = strings.TrimPrefix(e.Note+"; duplicate entry", "; ")
+		} else {
+			seen[f.Normalized] = true
+			result.Normalized = append(result.Normalized, f.Normalized)
+		}
+		result.Entries = append(result.Entries, e)
+	}
+	return result
+}
+
+// normalizeURLFilters returns the normalized, deduplicated list or an error naming each invalid entry
+func normalizeURLFilters(entries []string) ([]string, error) {
+	result := parseURLFilters(entries)
+	if result.Invalid == 0 {
+		return result.Normalized, nil
+	}
+	var problems []string
+	for _, e := range result.Entries {
+		if !e.Valid {
+			problems = append(problems, fmt.Sprintf("%q: %s", e.Input, e.Reason))
+		}
+	}
+	return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+}
+
+// matches reports whether the filter applies to u (host already lower-cased, port resolved)
+func (f urlFilter) matches(u *url.URL, port string) bool {
+	if f.Scheme != "" && f.Scheme != "*" && f.Scheme != u.Scheme {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	switch {
+	case f.Host == "*":
+	case f.Host == "":
+		if u.Scheme != "file" {
+			return false
+		}
+	case f.Exact:
+		if host != f.Host {
+			return false
+		}
+	default:
+		if host != f.Host && !strings.HasSuffix(host, "."+f.Host) {
+			return false
+		}
+	}
+	if f.Port != "" && f.Port != port {
+		return false
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, f.Path) {
+		return false
+	}
+
+	values := u.Query()
+	for _, tok := range f.Query {
+		key, want, hasValue := strings.Cut(tok, "=")
+		got, ok := values[key]
+		if !ok {
+			return false
+		}
+		if !hasValue {
+			continue
+		}
+		found := false
+		for _, v := range got {
+			if v == want || (strings.HasSuffix(want, "*") && strings.HasPrefix(v, strings.TrimSuffix(want, "*"))) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific follows Chrome's precedence: longer host, then exact host, then longer path, then more query tokens
+func (f urlFilter) moreSpecific(g urlFilter) int {
+	hostLen := func(h urlFilter) int {
+		if h.Host == "*" {
+			return 0
+		}
+		return len(h.Host)
+	}
+	switch {
+	case hostLen(f) != hostLen(g):
+		return hostLen(f) - hostLen(g)
+	case f.Exact != g.Exact:
+		if f.Exact {
+			return 1
+		}
+		return -1
+	case len(f.Path) != len(g.Path):
+		return len(f.Path) - len(g.Path)
+	}
+	return len(f.Query) - len(g.Query)
+}
+
+// previewURLAccess evaluates rawURL against allow/block lists the way Chrome and Edge do
+func previewURLAccess(allowlist, blocklist []string, rawURL string) URLAccessPreview {
+	preview := URLAccessPreview{URL: rawURL}
+	target := strings.TrimSpace(rawURL)
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Host == "" && u.Scheme != "file") {
+		preview.Reason = "Not a valid URL"
+		return preview
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		port = defaultURLPorts[u.Scheme]
+	}
+
+	best := func(entries []string) (urlFilter, bool) {
+		var top urlFilter
+		found := false
+		for _, raw := range entries {
+			f, err := parseURLFilter(raw)
+			if err != nil || !f.matches(u, port) {
+				continue
+			}
+			if !found || f.moreSpecific(top) > 0 {
+				top, found = f, true
+			}
+		}
+		return top, found
+	}
+	allow, allowed := best(allowlist)
+	block, blocked := best(blocklist)
+
+	switch {
+	case !allowed && !blocked:
+		preview.Allowed = true
+		preview.Reason = "No filter matches; the URL is allowed"
+	case allowed && (!blocked || allow.moreSpecific(block) >= 0):
+		// Equally specific allow and block filters: allow wins
+		preview.Allowed, preview.List, preview.Filter = true, "allowlist", allow.Normalized
+		preview.Reason = "Allowed by " + allow.Normalized
+	default:
+		preview.List, preview.Filter = "blocklist", block.Normalized
+		preview.Reason = "Blocked by " + block.Normalized
+	}
+	return preview
+}
+
+// ParseURLFilters validates and normalizes comma- or newline-separated allowlist/blocklist entries
+func (a *App) ParseURLFilters(input string) URLFilterParseResult {
+	return parseURLFilters(splitURLFilters(input))
+}
+
+// PreviewURLAccess tells whether url would be allowed. An allowlist without a blocklist blocks everything else,
+// as SetDomainWhitelist and ApplyBrowserPolicy configure it.
+func (a *App) PreviewURLAccess(allowlist, blocklist []string, url string) URLAccessPreview {
+	p := BrowserPolicy{Allowlist: allowlist, Blocklist: blocklist}
+	return previewURLAccess(p.Allowlist, p.effectiveBlocklist(), url)
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"crypto/des"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// VNCConfig is the TightVNC server configuration (vnc in config.json).
+// Passwords are never part of the config; set them with SetVNCPasswords.
+type VNCConfig struct {
+	AllowedRanges []string `json:"allowed_ranges"` // "10.0.0.0/24", "10.0.0.5" or "10.0.0.1-10.0.0.50"
+	Port          int      `json:"port"`           // default 5900
+	RequireAuth   *bool    `json:"require_auth"`   // default true
+}
+
+// VNCPasswordStatus tells which passwords are stored, without revealing them
+type VNCPasswordStatus struct {
+	Admin    bool `json:"admin"`
+	ViewOnly bool `json:"view_only"`
+}
+
+// vncSecrets holds the DES-encoded passwords, DPAPI-protected on disk
+type vncSecrets struct {
+	Admin    []byte `json:"admin"`
+	ViewOnly []byte `json:"view_only"`
+}
+
+const (
+	vncServerPath  = `SOFTWARE\TightVNC\Server`
+	vncDefaultPort = 5900
+)
+
+// The fixed key VNC servers use to obfuscate stored passwords
+var vncDESKey = []byte{0x17, 0x52, 0x6B, 0x06, 0x23, 0x4E, 0x58, 0x07}
+
+// encodeVNCPassword returns the 8-byte value TightVNC stores for a password.
+// VNC's DES reads key bits LSB first, so the key bytes are bit-reversed for a standard DES.
+func encodeVNCPassword(password string) ([]byte, error) {
+	if err := validateVNCPassword(password); err != nil {
+		return nil, err
+	}
+	key := make([]byte, len(vncDESKey))
+	for i, b := range vncDESKey {
+		key[i] = bits.Reverse8(b)
+	}
+	block, err := des.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, 8)
+	copy(plain, password)
+	out := make([]byte, 8)
+	block.Encrypt(out, plain)
+	return out, nil
+}
+
+// validateVNCPassword enforces what the VNC protocol can carry: 1-8 printable ASCII characters
+func validateVNCPassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("password is empty")
+	}
+	if len(password) > 8 {
+		return fmt.Errorf("VNC passwords are limited to 8 characters")
+	}
+	for _, r := range password {
+		if r < 0x21 || r > 0x7e {
+			return fmt.Errorf("password must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+// parseVNCRange turns a CIDR, single address or "first-last" range into TightVNC's "first-last" form
+func parseVNCRange(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	var first, last netip.Addr
+	var err error
+	switch {
+	case strings.Contains(s, "/"):
+		prefix, perr := netip.ParsePrefix(s)
+		if perr != nil {
+			return "", fmt.Errorf("%q: %v", s, perr)
+		}
+		prefix = prefix.Masked()
+		first = prefix.Addr()
+		if first.Is4() {
+			b := first.As4()
+			n := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+			n |= uint32(1<<(32-prefix.Bits())) - 1
+			last = netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+		}
+	case strings.Contains(s, "-"):
+		a, b, _ := strings.Cut(s, "-")
+		if first, err = netip.ParseAddr(strings.TrimSpace(a)); err != nil {
+			return "", fmt.Errorf("%q: %v", s, err)
+		}
+		if last, err = netip.ParseAddr(strings.TrimSpace(b)); err != nil {
+			return "", fmt.Errorf("%q: %v", s, err)
+		}
+	default:
+		if first, err = netip.ParseAddr(s); err != nil {
+			return "", fmt.Errorf("%q: %v", s, err)
+		}
+		last = first
+	}
+	if !first.Is4() || !last.Is4() {
+		return "", fmt.Errorf("%q: TightVNC access control only supports IPv4", s)
+	}
+	if last.Less(first) {
+		return "", fmt.Errorf("%q: range ends before it starts", s)
+	}
+	return first.String() + "-" + last.String(), nil
+}
+
+// ipAccessControl builds TightVNC's IpAccessControl rules: allow the ranges, deny everyone else.
+// TightVNC allows addresses that match no rule, so the final deny-all rule is what makes it an allowlist.
+func (c VNCConfig) ipAccessControl() (string, error) {
+	if len(c.AllowedRanges) == 0 {
+		return "", fmt.Errorf("no allowed_ranges declared")
+	}
+	var rules, problems []string
+	for _, r := range c.AllowedRanges {
+		rng, err := parseVNCRange(r)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		rules = append(rules, rng+":0")
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return strings.Join(append(rules, "0.0.0.0-255.255.255.255:1"), ","), nil
+}
+
+// checkPort rejects a declared port outside 1-65535; 0 means the default
+func (c VNCConfig) checkPort() error {
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("port %d is outside 1-65535", c.Port)
+	}
+	return nil
+}
+
+func (c VNCConfig) port() uint32 {
+	if c.Port == 0 {
+		return vncDefaultPort
+	}
+	return uint32(c.Port)
+}
+
+func (c VNCConfig) requireAuth() bool {
+	return c.RequireAuth == nil || *c.RequireAuth
+}
+
+func vncSecretsPath() string {
+	return filepath.Join(appDataDir(), "vnc.secret")
+}
+
+func loadVNCSecrets() (vncSecrets, error) {
+	var s vncSecrets
+	blob, err := os.ReadFile(vncSecretsPath())
+	if err != nil {
+		return s, err
+	}
+	data, err := dpapiUnprotect(blob)
+	if err != nil {
+		return s, err
+	}
+	return s, json.Unmarshal(data, &s)
+}
+
+func saveVNCSecrets(s vncSecrets) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	protected, err := dpapiProtect(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(appDataDir(), 0755); err != nil {
+		return err
+	}
+	return writeAdminOnlyFile(vncSecretsPath(), protected)
+}
+
+// adminOnlySDDL grants SYSTEM and Administrators full control and nothing else. The VNC
+// secrets are machine-scope DPAPI, so any local user who can read them can decrypt them.
+const adminOnlySDDL = "D:P(A;;FA;;;SY)(A;;FA;;;BA)"
+
+// writeAdminOnlyFile writes data to a new file created with adminOnlySDDL and moves it
+// over path, so the file never exists with the inherited ProgramData ACL
+func writeAdminOnlyFile(path string, data []byte) error {
+	sd, err := windows.SecurityDescriptorFromString(adminOnlySDDL)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	name, err := windows.UTF16PtrFromString(tmp)
+	if err != nil {
+		return err
+	}
+	sa := &windows.SecurityAttributes{Length: uint32(unsafe.Sizeof(windows.SecurityAttributes{})), SecurityDescriptor: sd}
+	h, err := windows.CreateFile(name, windows.GENERIC_WRITE, 0, sa, windows.CREATE_NEW, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(h), tmp)
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// adminOwnedFile tells whether path is owned by SYSTEM, Administrators or this process's user.
+// ProgramData lets Users create files, so a file we only read must not be one they planted.
+func adminOwnedFile(path string) bool {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return false
+	}
+	owner, _, err := sd.Owner()
+	if err != nil || owner == nil {
+		return false
+	}
+	if owner.IsWellKnown(windows.WinLocalSystemSid) || owner.IsWellKnown(windows.WinBuiltinAdministratorsSid) {
+		return true
+	}
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	return err == nil && owner.Equals(user.User.Sid)
+}
+
+// SetVNCPasswords stores the admin and view-only passwords (DPAPI-protected) for ApplyTightVNCConfig.
+// An empty view-only password means no view-only access.
+func (a *App) SetVNCPasswords(admin, viewOnly string) (result string) {
+	defer beginAudit("SetVNCPasswords", map[string]string{"view_only": onOff(viewOnly != "", "set", "none")}, nil).finish(&result)
+	if !isAdmin() {
+		return "⚠️ Error: Administrative privileges required to set VNC passwords."
+	}
+	var s vncSecrets
+	var err error
+	if s.Admin, err = encodeVNCPassword(admin); err != nil {
+		return "❌ Error: Admin password - " + err.Error()
+	}
+	if viewOnly != "" {
+		if viewOnly == admin {
+			return "❌ Error: The view-only password must differ from the admin password"
+		}
+		if s.ViewOnly, err = encodeVNCPassword(viewOnly); err != nil {
+			return "❌ Error: View-only password - " + err.Error()
+		}
+	}
+	if err := saveVNCSecrets(s); err != nil {
+		return "❌ Error: Failed to store VNC passwords: " + err.Error()
+	}
+	return "✅ Success: VNC passwords stored. Apply the TightVNC configuration to use them."
+}
+
+// GetVNCPasswordStatus reports which VNC passwords are stored
+func (a *App) GetVNCPasswordStatus() VNCPasswordStatus {
+	s, err := loadVNCSecrets()
+	if err != nil {
+		return VNCPasswordStatus{}
+	}
+	return VNCPasswordStatus{Admin: len(s.Admin) == 8, ViewOnly: len(s.ViewOnly) == 8}
+}
+
+// ApplyTightVNCConfig applies security settings to TightVNC natively
+func (a *App) ApplyTightVNCConfig() (result string) {
+	defer beginAudit("ApplyTightVNCConfig", nil, vncAuditProbe).snapshot(vncChangeScope).finish(&result)
+	return a.applyTightVNCConfig(liveExecutor())
+}
+
+func (a *App) applyTightVNCConfig(x *executor) string {
+	if msg, ok := x.requireAdmin("⚠️ Error: Administrative privileges required to configure VNC."); !ok {
+		return msg
+	}
+	config, err := loadConfig("config.json")
+	if err != nil {
+		return "Error loading config"
+	}
+	if config.VNC == nil {
+		return "❌ Error: No vnc section declared in config.json"
+	}
+	vc := *config.VNC
+	ipAccess, err := vc.ipAccessControl()
+	if err != nil {
+		return "❌ Error: Invalid VNC allowed_ranges - " + err.Error()
+	}
+	if err := vc.checkPort(); err != nil {
+		return "❌ Error: Invalid VNC port - " + err.Error()
+	}
+
+	auth := uint32(0)
+	var secrets vncSecrets
+	if vc.requireAuth() {
+		auth = 1
+		if secrets, err = loadVNCSecrets(); err != nil || len(secrets.Admin) != 8 {
+			return "❌ Error: No VNC passwords stored on this machine. Set them with SetVNCPasswords first."
+		}
+	}
+
+	set := func(name string, value any) {
+		if err == nil {
+			err = x.regSet(registry.LOCAL_MACHINE, vncServerPath, name, value)
+		}
+	}
+	set("UseVncAuthentication", auth)
+	set("UseControlAuthentication", auth)
+	set("IpAccessControl", ipAccess)
+	set("RfbPort", vc.port())
+	set("AcceptPointerEvents", uint32(1))
+	set("AcceptKeyboardEvents", uint32(1))
+	set("AllowLoopback", uint32(1))
+	if vc.requireAuth() {
+		set("Password", secrets.Admin)
+		set("ControlPassword", secrets.Admin)
+		if len(secrets.ViewOnly) == 8 {
+			set("PasswordViewOnly", secrets.ViewOnly)
+		}
+	}
+	if err != nil {
+		return "❌ Error: Failed to write TightVNC settings: " + err.Error()
+	}
+
+	// Remove passwords that are no longer wanted, and the value older versions wrote under the wrong name
+	stale := []string{"AccessControlConfig"}
+	if !vc.requireAuth() {
+		stale = append(stale, "Password", "ControlPassword", "PasswordViewOnly")
+	} else if len(secrets.ViewOnly) != 8 {
+		stale = append(stale, "PasswordViewOnly")
+	}
+	for _, name := range stale {
+		if err := x.regDeleteValue(registry.LOCAL_MACHINE, vncServerPath, name); err != nil {
+			return "❌ Error: Failed to remove " + name + ": " + err.Error()
+		}
+	}
+
+	// Restart service more robustly
+	x.run(exec.Command("net", "stop", "tvnserver"))
+	// Wait a bit for it to stop
+	x.run(exec.Command("powershell", "-Command", "Start-Sleep -Seconds 2"))
+	mode := onOff(vc.requireAuth(), "password authentication", "Authentication Disabled")
+	if err := x.run(exec.Command("net", "start", "tvnserver")); err != nil {
+		return "✅ Config Applied (" + mode + "; VNC Service restart skipped)."
+	}
+	return fmt.Sprintf("✅ Success: TightVNC Configured (%s, %d allowed range(s)).", mode, len(vc.AllowedRanges))
+}
+
+// checkVNCAccess compares TightVNC's IP access rules and port with the vnc section
+func checkVNCAccess(vc VNCConfig) ComplianceControl {
+	actual := regValueOrNotSet(registry.LOCAL_MACHINE, vncServerPath, "IpAccessControl")
+	port := regValueOrNotSet(registry.LOCAL_MACHINE, vncServerPath, "RfbPort")
+	c := ComplianceControl{
+		Control:     "TightVNC IP access",
+		Actual:      fmt.Sprintf("IpAccessControl=%s, RfbPort=%s", actual, port),
+		Remediation: "Apply the TightVNC configuration",
+		FixOp:       "ApplyTightVNCConfig",
+	}
+	want, err := vc.ipAccessControl()
+	if err != nil {
+		c.Expected = "valid allowed_ranges (" + err.Error() + ")"
+		return c
+	}
+	if err := vc.checkPort(); err != nil {
+		c.Expected = "valid port (" + err.Error() + ")"
+		return c
+	}
+	c.Expected = fmt.Sprintf("IpAccessControl=%s, RfbPort=%d", want, vc.port())
+	c.Pass = actual == want && port == fmt.Sprint(vc.port())
+	return c
+}